@@ -14,9 +14,16 @@ import (
 	"HyVanila/internal/discord"
 	"HyVanila/internal/env"
 	"HyVanila/internal/game"
+	"HyVanila/internal/gamestore"
+	"HyVanila/internal/java"
+	"HyVanila/internal/migrate"
 	"HyVanila/internal/mods"
 	"HyVanila/internal/news"
+	"HyVanila/internal/prefetch"
 	"HyVanila/internal/pwr"
+	"HyVanila/internal/relocate"
+	"HyVanila/internal/server"
+	"HyVanila/updater"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -27,6 +34,16 @@ type App struct {
 	cfg            *config.Config
 	newsService    *news.NewsService
 	discordService *discord.Service
+	serverManager  *server.Manager
+	prefetcher     *prefetch.Scheduler
+	stopPrefetcher context.CancelFunc
+	// allowDowngrade lets launcher updates install older versions; it is set
+	// for the session when the user switches channels and accepts a downgrade
+	allowDowngrade bool
+	// rolledBackFrom is a launcher update that failed to start and was undone
+	rolledBackFrom string
+	// configProblem is what went wrong loading the config (nil if nothing did)
+	configProblem error
 }
 
 // ProgressUpdate represents download/install progress
@@ -42,14 +59,19 @@ type ProgressUpdate struct {
 
 // NewApp creates a new App instance
 func NewApp() *App {
-	cfg, _ := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 	if cfg == nil {
 		cfg = config.Default()
 	}
 	return &App{
 		cfg:            cfg,
+		configProblem:  err,
 		newsService:    news.NewNewsService(),
 		discordService: discord.NewService(),
+		serverManager:  server.NewManager(),
 	}
 }
 
@@ -62,6 +84,9 @@ func (a *App) Startup(ctx context.Context) {
 	fmt.Printf("║           Version: %-43s║\n", AppVersion)
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 
+	// Proxy and CA settings must be in place before anything goes online
+	a.applyNetworkSettings()
+
 	// Set custom instance directory if configured
 	if a.cfg.CustomInstanceDir != "" {
 		env.SetCustomInstanceDir(a.cfg.CustomInstanceDir)
@@ -73,6 +98,21 @@ func (a *App) Startup(ctx context.Context) {
 		fmt.Printf("Warning: Failed to create folders: %v\n", err)
 	}
 
+	// Move data from the pre-instance layout before anything reads instance folders
+	if _, err := migrate.RunLegacyLayout(); err != nil {
+		fmt.Printf("Warning: Legacy data migration incomplete: %v\n", err)
+	}
+
+	if job := relocate.Pending(); job != nil {
+		fmt.Printf("Warning: Moving instances to %s was interrupted; it can be resumed from settings\n", job.Dest)
+	}
+
+	a.applyBackupPolicy()
+	gamestore.SetEnabled(a.cfg.ShareGameFiles)
+	a.applyCachePolicy()
+	a.applyBandwidthLimits()
+	a.startPrefetcher(ctx)
+
 	// Initialize Discord RPC if enabled
 	if a.cfg.DiscordRPCEnabled {
 		go func() {
@@ -82,6 +122,16 @@ func (a *App) Startup(ctx context.Context) {
 		}()
 	}
 
+	// Keep Java and butler on their pinned versions
+	a.startToolUpdates()
+
+	// Startup completed, so a launcher update is confirmed and no longer rolled back
+	updater.MarkHealthy(AppVersion)
+	if rolledBack := updater.TakeRolledBack(); rolledBack != nil {
+		fmt.Printf("Warning: Launcher %s failed to start and was rolled back\n", rolledBack.ToVersion)
+		a.rolledBackFrom = rolledBack.ToVersion
+	}
+
 	// Check for launcher updates in background
 	go func() {
 		fmt.Println("Starting background update check...")
@@ -92,9 +142,15 @@ func (a *App) Startup(ctx context.Context) {
 // Shutdown is called when the app closes
 func (a *App) Shutdown(ctx context.Context) {
 	fmt.Println("HyVanila shutting down...")
+	if a.stopPrefetcher != nil {
+		a.stopPrefetcher()
+	}
 	if a.discordService != nil {
 		a.discordService.Close()
 	}
+	if a.serverManager != nil {
+		a.serverManager.StopAll()
+	}
 }
 
 // SelectInstanceDirectory opens a folder picker dialog and saves the selected directory
@@ -105,31 +161,29 @@ func (a *App) SelectInstanceDirectory() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to open directory dialog: %w", err)
 	}
-	
+
 	if selectedDir == "" {
 		// User cancelled the dialog
 		return "", nil
 	}
-	
+
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(selectedDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create directory: %w\n\nPlease ensure:\n• The drive is properly connected\n• You have write permissions\n• The path is valid", err)
 	}
-	
+
 	// Verify the directory is writable (important for external drives)
 	testFile := filepath.Join(selectedDir, ".hyvanila-test")
 	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
 		return "", fmt.Errorf("directory is not writable: %w\n\nPlease check:\n• Drive is not read-only\n• You have write permissions\n• Drive has free space", err)
 	}
 	os.Remove(testFile)
-	
-	// Save to config
-	a.cfg.CustomInstanceDir = selectedDir
-	env.SetCustomInstanceDir(selectedDir)
-	if err := config.Save(a.cfg); err != nil {
-		return "", fmt.Errorf("failed to save config: %w", err)
+
+	// Move the existing instances and save to config
+	if err := a.relocateInstances(selectedDir); err != nil {
+		return "", err
 	}
-	
+
 	fmt.Printf("Instance directory updated to: %s\n", selectedDir)
 	return selectedDir, nil
 }
@@ -153,6 +207,10 @@ func (a *App) SelectJavaPath() (string, error) {
 		return "", nil
 	}
 
+	if _, err := java.Validate(a.ctx, selectedFile); err != nil {
+		return "", ValidationError(err.Error())
+	}
+
 	// Save to config
 	a.cfg.JavaPath = selectedFile
 	if err := config.Save(a.cfg); err != nil {
@@ -229,29 +287,18 @@ func (a *App) DownloadAndLaunch(playerName string) error {
 		return wrappedErr
 	}
 
+	// Apply an update that was pre-downloaded in the background
+	if err := a.updateLatestBeforeLaunch(versionType, version); err != nil {
+		wrappedErr := GameError("Failed to update game", err)
+		a.emitError(wrappedErr)
+		return wrappedErr
+	}
+
 	// Launch the game with branch, version, and online mode settings
 	a.progressCallback("launch", 100, "Launching game...", "", "", 0, 0)
 
 	// Use online mode from config
-	opts := game.LaunchOptions{
-		PlayerName: playerName,
-		Branch:     versionType,
-		Version:    version,
-		OnlineMode: a.cfg.OnlineMode,
-		AuthDomain: a.cfg.AuthDomain,
-		JavaPath:   a.cfg.JavaPath,
-		MaxMemory:  a.cfg.MaxMemory,
-		MinMemory:  a.cfg.MinMemory,
-		FullScreen: a.cfg.FullScreen,
-		OnExit: func() {
-			// Show launcher window when game exits
-			wailsRuntime.WindowShow(a.ctx)
-			// Reset Discord RPC
-			if a.cfg.DiscordRPCEnabled && a.discordService != nil {
-				a.discordService.SetIdle()
-			}
-		},
-	}
+	opts := a.newLaunchOptions(playerName, versionType, version)
 
 	if err := game.LaunchInstanceWithOptions(opts); err != nil {
 		wrappedErr := GameError("Failed to launch game", err)
@@ -274,6 +321,30 @@ func (a *App) DownloadAndLaunch(playerName string) error {
 	return nil
 }
 
+// newLaunchOptions builds launch options for an instance from the launcher config
+func (a *App) newLaunchOptions(playerName string, branch string, version int) game.LaunchOptions {
+	return game.LaunchOptions{
+		PlayerName: playerName,
+		Branch:     branch,
+		Version:    version,
+		OnlineMode: a.cfg.OnlineMode,
+		AuthDomain: a.cfg.AuthDomain,
+		JavaPath:   a.javaPathFor(branch, version),
+		MaxMemory:  a.cfg.MaxMemory,
+		MinMemory:  a.cfg.MinMemory,
+		FullScreen: a.cfg.FullScreen,
+		OnExit: func() {
+			// Show launcher window when game exits
+			wailsRuntime.WindowShow(a.ctx)
+			// Reset Discord RPC
+			if a.cfg.DiscordRPCEnabled && a.discordService != nil {
+				a.discordService.SetIdle()
+			}
+			a.backupAfterSession(branch, version)
+		},
+	}
+}
+
 // GetLogs returns launcher logs
 func (a *App) GetLogs() (string, error) {
 	logPath := filepath.Join(env.GetDefaultAppDir(), "logs", "launcher.log")
@@ -522,13 +593,13 @@ func (a *App) CheckLatestNeedsUpdate(branch string) bool {
 	if !env.IsVersionInstalled(branch, 0) {
 		return false
 	}
-	
+
 	// Get the actual latest version number
 	latestVersion := pwr.FindLatestVersion(branch)
 	if latestVersion <= 0 {
 		return false
 	}
-	
+
 	// Check if the latest instance has the current version
 	// We can check this by looking at the instance's installed version file
 	instanceDir := env.GetInstanceDir(branch, 0)
@@ -538,14 +609,14 @@ func (a *App) CheckLatestNeedsUpdate(branch string) bool {
 		// No version file means fresh install or corrupted
 		return true
 	}
-	
+
 	installedVersionStr := string(data)
 	installedVersionStr = filepath.Base(strings.TrimSpace(installedVersionStr))
 	installedVersion, err := strconv.Atoi(installedVersionStr)
 	if err != nil {
 		return true
 	}
-	
+
 	// If installed version is less than latest, needs update
 	return installedVersion < latestVersion
 }
@@ -586,7 +657,7 @@ func (a *App) DownloadVersion(versionType string, playerName string) error {
 	if versionType != "release" && versionType != "prerelease" {
 		return fmt.Errorf("invalid version type: %s", versionType)
 	}
-	
+
 	// Validate nickname
 	if len(playerName) == 0 {
 		err := ValidationError("Please enter a nickname")
@@ -628,3 +699,8 @@ func (a *App) GetNews(limit int) ([]news.NewsItem, error) {
 	}
 	return a.newsService.GetNews(limit)
 }
+
+// GetMigrationReport returns the report of the legacy data migration (nil if it hasn't completed)
+func (a *App) GetMigrationReport() *migrate.Report {
+	return migrate.GetReport()
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+
+	"HyVanila/internal/backup"
+	"HyVanila/internal/config"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// applyBackupPolicy pushes the backup settings from config to the backup subsystem
+func (a *App) applyBackupPolicy() {
+	backup.SetPolicy(backup.Policy{
+		OnUpdate:      a.cfg.BackupOnUpdate,
+		OnSessionExit: a.cfg.BackupOnExit,
+		KeepLast:      a.cfg.BackupKeepLast,
+		KeepDaily:     a.cfg.BackupKeepDaily,
+		MaxSizeMB:     a.cfg.BackupMaxSizeMB,
+	})
+}
+
+// backupAfterSession snapshots an instance's worlds after the game exits
+func (a *App) backupAfterSession(branch string, version int) {
+	if !backup.GetPolicy().OnSessionExit {
+		return
+	}
+	snap, err := backup.Create(branch, version, backup.ReasonSession, "")
+	if err != nil {
+		fmt.Printf("Warning: Failed to back up worlds after session: %v\n", err)
+		return
+	}
+	if snap != nil {
+		wailsRuntime.EventsEmit(a.ctx, "backup-created", snap.ID)
+	}
+}
+
+// CreateBackup snapshots the worlds of an instance now
+func (a *App) CreateBackup(branch string, version int, note string) (*backup.Summary, error) {
+	snap, err := backup.Create(branch, version, backup.ReasonManual, note)
+	if err != nil {
+		return nil, FileSystemError("creating backup", err)
+	}
+	if snap == nil {
+		return nil, ValidationError("This instance has no worlds to back up")
+	}
+	wailsRuntime.EventsEmit(a.ctx, "backup-created", snap.ID)
+
+	summaries := backup.List(branch, version)
+	for i := range summaries {
+		if summaries[i].ID == snap.ID {
+			return &summaries[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ListBackups returns the backups of an instance, newest first (empty branch lists all)
+func (a *App) ListBackups(branch string, version int) []backup.Summary {
+	return backup.List(branch, version)
+}
+
+// GetBackup returns a backup including its file list
+func (a *App) GetBackup(id string) (*backup.Snapshot, error) {
+	snap, err := backup.Get(id)
+	if err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return snap, nil
+}
+
+// RestoreBackup restores a backup's worlds into an instance (the same or another one).
+// The instance's current worlds are backed up first.
+func (a *App) RestoreBackup(id string, branch string, version int) error {
+	if a.IsGameRunning() {
+		return GameError("Close the game before restoring a backup", nil)
+	}
+	if err := backup.Restore(id, branch, version); err != nil {
+		return FileSystemError("restoring backup", err)
+	}
+	return nil
+}
+
+// DeleteBackup deletes a backup and frees its unshared data
+func (a *App) DeleteBackup(id string) error {
+	if err := backup.Delete(id); err != nil {
+		return FileSystemError("deleting backup", err)
+	}
+	return nil
+}
+
+// ExportBackup asks for a destination and writes the backup as a zip archive
+func (a *App) ExportBackup(id string) (string, error) {
+	destPath, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export Backup",
+		DefaultFilename: fmt.Sprintf("hyvanila-backup-%s.zip", id),
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "Zip Archives (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if destPath == "" {
+		// User cancelled the dialog
+		return "", nil
+	}
+
+	if err := backup.Export(id, destPath); err != nil {
+		return "", FileSystemError("exporting backup", err)
+	}
+	return destPath, nil
+}
+
+// GetBackupStorageSize returns the disk space used by backups in bytes
+func (a *App) GetBackupStorageSize() int64 {
+	return backup.GetStoreSize()
+}
+
+// SetBackupPolicy updates when backups are taken and how long they are kept
+func (a *App) SetBackupPolicy(policy backup.Policy) error {
+	if policy.KeepLast < 0 || policy.KeepDaily < 0 || policy.MaxSizeMB < 0 {
+		return ValidationError("Backup limits can't be negative")
+	}
+	a.cfg.BackupOnUpdate = policy.OnUpdate
+	a.cfg.BackupOnExit = policy.OnSessionExit
+	a.cfg.BackupKeepLast = policy.KeepLast
+	a.cfg.BackupKeepDaily = policy.KeepDaily
+	a.cfg.BackupMaxSizeMB = policy.MaxSizeMB
+	if err := config.Save(a.cfg); err != nil {
+		return err
+	}
+
+	a.applyBackupPolicy()
+	go func() {
+		if err := backup.ApplyRetention(); err != nil {
+			fmt.Printf("Warning: Failed to apply backup retention: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// GetBackupPolicy returns the active backup policy
+func (a *App) GetBackupPolicy() backup.Policy {
+	return backup.GetPolicy()
+}
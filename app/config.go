@@ -2,9 +2,10 @@ package app
 
 import (
 	"fmt"
+	"strings"
 	
 	"HyVanila/internal/config"
-	"HyVanila/internal/env"
+	"HyVanila/internal/java"
 	"HyVanila/internal/pwr"
 
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
@@ -31,6 +32,15 @@ func (a *App) SaveConfig() error {
 	return config.Save(a.cfg)
 }
 
+// GetConfigProblem describes what was wrong with the config file when the
+// launcher started, or returns nil if it loaded cleanly
+func (a *App) GetConfigProblem() *AppError {
+	if a.configProblem == nil {
+		return nil
+	}
+	return NewAppError(ErrorTypeValidation, "Some settings could not be loaded", a.configProblem)
+}
+
 // SetMusicEnabled sets music enabled state and saves it
 func (a *App) SetMusicEnabled(enabled bool) error {
 	a.cfg.MusicEnabled = enabled
@@ -100,11 +110,10 @@ func (a *App) GetCustomInstanceDir() string {
 	return a.cfg.CustomInstanceDir
 }
 
-// SetCustomInstanceDir sets a custom directory for instances
+// SetCustomInstanceDir sets a custom directory for instances ("" for the default)
+// and moves the existing instances there
 func (a *App) SetCustomInstanceDir(path string) error {
-	a.cfg.CustomInstanceDir = path
-	env.SetCustomInstanceDir(path) // Update the env module
-	return config.Save(a.cfg)
+	return a.relocateInstances(path)
 }
 
 // GetAutoUpdateLatest returns whether the latest instance should auto-update
@@ -115,6 +124,9 @@ func (a *App) GetAutoUpdateLatest() bool {
 // SetAutoUpdateLatest sets whether the latest instance should auto-update
 func (a *App) SetAutoUpdateLatest(enabled bool) error {
 	a.cfg.AutoUpdateLatest = enabled
+	if a.prefetcher != nil {
+		a.prefetcher.SetSettings(a.prefetchSettings())
+	}
 	return config.Save(a.cfg)
 }
 
@@ -126,14 +138,17 @@ func (a *App) GetOnlineMode() bool {
 // SetOnlineMode sets whether online mode is enabled
 func (a *App) SetOnlineMode(enabled bool) error {
 	// If switching from online to offline, restore original binaries
+	var restoreErr error
 	if !enabled && a.cfg.OnlineMode {
 		fmt.Println("Switching to offline mode, restoring original binaries...")
-		// This is a placeholder - in current version we don't patch binaries
-		// so no restoration needed
+		restoreErr = a.restorePatchedInstances()
 	}
 	
 	a.cfg.OnlineMode = enabled
-	return config.Save(a.cfg)
+	if err := config.Save(a.cfg); err != nil {
+		return err
+	}
+	return restoreErr
 }
 
 // GetAuthDomain returns the custom auth domain
@@ -143,12 +158,22 @@ func (a *App) GetAuthDomain() string {
 
 // SetAuthDomain sets the custom auth domain
 func (a *App) SetAuthDomain(domain string) error {
+	domain = strings.TrimSpace(domain)
+	if err := validateAuthDomain(domain); err != nil {
+		return err
+	}
 	a.cfg.AuthDomain = domain
 	return config.Save(a.cfg)
 }
 
-// SetJavaPath sets the custom Java path
+// SetJavaPath sets the custom Java path, used by instances without their own
+// runtime choice. The Java executable is checked first; empty uses the default runtime.
 func (a *App) SetJavaPath(path string) error {
+	if path != "" {
+		if _, err := java.Validate(a.ctx, path); err != nil {
+			return ValidationError(err.Error())
+		}
+	}
 	a.cfg.JavaPath = path
 	return config.Save(a.cfg)
 }
@@ -173,12 +198,18 @@ func (a *App) SetDiscordRPCEnabled(enabled bool) error {
 
 // SetMaxMemory sets the maximum memory in MB
 func (a *App) SetMaxMemory(memory int) error {
+	if err := config.ValidateMemory(a.cfg.MinMemory, memory); err != nil {
+		return ValidationError(err.Error())
+	}
 	a.cfg.MaxMemory = memory
 	return config.Save(a.cfg)
 }
 
 // SetMinMemory sets the minimum memory in MB
 func (a *App) SetMinMemory(memory int) error {
+	if err := config.ValidateMemory(memory, a.cfg.MaxMemory); err != nil {
+		return ValidationError(err.Error())
+	}
 	a.cfg.MinMemory = memory
 	return config.Save(a.cfg)
 }
@@ -4,9 +4,10 @@ import (
 	"HyVanila/internal/env"
 	"HyVanila/internal/java"
 	"HyVanila/internal/pwr/butler"
+	"HyVanila/internal/tools"
+	"HyVanila/internal/util/download"
 	"fmt"
 	"net"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -45,8 +46,10 @@ type GameStatusInfo struct {
 type DependenciesInfo struct {
 	JavaInstalled   bool   `json:"javaInstalled"`
 	JavaPath        string `json:"javaPath"`
+	JavaVersion     string `json:"javaVersion"`
 	ButlerInstalled bool   `json:"butlerInstalled"`
 	ButlerPath      string `json:"butlerPath"`
+	ButlerVersion   string `json:"butlerVersion"`
 }
 
 // RunDiagnostics runs system diagnostics
@@ -76,9 +79,7 @@ func (a *App) RunDiagnostics() DiagnosticReport {
 
 func checkConnectivity() ConnectivityInfo {
 	info := ConnectivityInfo{}
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := download.NewClient(10 * time.Second)
 
 	// Check Hytale patches
 	resp, err := client.Head("https://game-patches.hytale.com")
@@ -151,6 +152,9 @@ func checkDependencies() DependenciesInfo {
 	if err == nil {
 		info.JavaInstalled = true
 		info.JavaPath = javaPath
+		if tool, ok := tools.Get(java.ManagedID(java.DefaultMajor)); ok {
+			info.JavaVersion = tool.Version
+		}
 	}
 
 	// Check Butler
@@ -159,6 +163,7 @@ func checkDependencies() DependenciesInfo {
 		if _, err := os.Stat(butlerPath); err == nil {
 			info.ButlerInstalled = true
 			info.ButlerPath = butlerPath
+			info.ButlerVersion = butler.InstalledVersion()
 		}
 	}
 
@@ -200,14 +205,17 @@ Online Fix Applied: %v
 === DEPENDENCIES ===
 Java Installed: %v
 Java Path: %s
+Java Version: %s
 Butler Installed: %v
 Butler Path: %s
+Butler Version: %s
 `,
 		report.Timestamp,
 		report.Platform.OS, report.Platform.Arch, report.Platform.Version,
 		report.Connectivity.HytalePatches, report.Connectivity.GitHub, report.Connectivity.ItchIO, report.Connectivity.Error,
 		report.GameStatus.Installed, report.GameStatus.Version, report.GameStatus.ClientExists, report.GameStatus.OnlineFixApplied,
-		report.Dependencies.JavaInstalled, report.Dependencies.JavaPath, report.Dependencies.ButlerInstalled, report.Dependencies.ButlerPath,
+		report.Dependencies.JavaInstalled, report.Dependencies.JavaPath, report.Dependencies.JavaVersion,
+		report.Dependencies.ButlerInstalled, report.Dependencies.ButlerPath, report.Dependencies.ButlerVersion,
 	)
 
 	if err := os.WriteFile(filepath, []byte(content), 0644); err != nil {
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"os"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/integrity"
+)
+
+// VerifyInstance checks an instance's game files against the manifest recorded at
+// install, listing missing, modified and extra files. Progress is reported through
+// "progress-update" events with the "verify" stage.
+func (a *App) VerifyInstance(branch string, version int) (*integrity.Report, error) {
+	if _, err := os.Stat(env.GetInstanceGameDir(branch, version)); os.IsNotExist(err) {
+		return nil, GameError("Instance is not installed", err)
+	}
+	report, err := integrity.Verify(branch, version, a.progressCallback)
+	if err != nil {
+		return nil, FileSystemError("verifying instance", err)
+	}
+	return report, nil
+}
+
+// RepairInstance re-fetches only the broken game files of an instance. Extra
+// files are removed as well if removeExtra is set.
+func (a *App) RepairInstance(branch string, version int, removeExtra bool) (*integrity.Report, error) {
+	if a.IsGameRunning() {
+		return nil, GameError("Close the game before repairing an instance", nil)
+	}
+	if server := a.serverManager.Get(branch, version); server != nil && server.IsRunning() {
+		return nil, GameError("Stop the instance's server before repairing it", nil)
+	}
+	if _, err := os.Stat(env.GetInstanceGameDir(branch, version)); os.IsNotExist(err) {
+		return nil, GameError("Instance is not installed", err)
+	}
+
+	report, err := integrity.Repair(a.ctx, branch, version, removeExtra, a.progressCallback)
+	if err != nil {
+		return nil, GameError("Failed to repair instance", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/env"
+	"HyVanila/internal/java"
+)
+
+// JavaRuntimes lists the Java installations the launcher can use
+type JavaRuntimes struct {
+	Managed []java.Runtime `json:"managed"`
+	System  []java.Runtime `json:"system"`
+	// Custom is the Java path set in settings, if any
+	Custom *java.Runtime `json:"custom,omitempty"`
+}
+
+// instanceKey identifies an instance in per-instance settings by its folder name
+func instanceKey(branch string, version int) string {
+	return filepath.Base(env.GetInstanceDir(branch, version))
+}
+
+// javaPathFor returns the Java executable an instance runs on: its own runtime
+// choice, then the custom Java path, then the default runtime (empty)
+func (a *App) javaPathFor(branch string, version int) string {
+	if id := a.cfg.InstanceRuntimes[instanceKey(branch, version)]; id != "" {
+		if javaPath, err := java.Resolve(id); err == nil {
+			return javaPath
+		}
+	}
+	return a.cfg.JavaPath
+}
+
+// GetJavaRuntimes returns the managed runtimes, Java installations found on the
+// system and the custom Java path, each probed for version, vendor and arch
+func (a *App) GetJavaRuntimes() JavaRuntimes {
+	runtimes := JavaRuntimes{
+		Managed: java.ListManaged(a.ctx),
+		System:  java.DetectSystem(a.ctx),
+	}
+	if a.cfg.JavaPath != "" {
+		if rt, err := java.Probe(a.ctx, a.cfg.JavaPath); err == nil {
+			rt.Source = java.SourceCustom
+			runtimes.Custom = rt
+		} else {
+			runtimes.Custom = &java.Runtime{ID: a.cfg.JavaPath, Path: a.cfg.JavaPath, Source: java.SourceCustom, Problem: err.Error()}
+		}
+	}
+	return runtimes
+}
+
+// ProbeJava checks a Java executable and describes it
+func (a *App) ProbeJava(javaPath string) (*java.Runtime, error) {
+	rt, err := java.Probe(a.ctx, javaPath)
+	if err != nil {
+		return nil, ValidationError(err.Error())
+	}
+	return rt, nil
+}
+
+// InstallJavaRuntime installs or updates the Temurin runtime of a Java feature
+// release, e.g. 25, next to the other managed runtimes
+func (a *App) InstallJavaRuntime(major int) (*java.Runtime, error) {
+	if major < java.RequiredMajor {
+		return nil, ValidationError(fmt.Sprintf("The game needs Java %d or newer", java.RequiredMajor))
+	}
+	rt, err := java.InstallRuntime(a.ctx, major, a.progressCallback)
+	if err != nil {
+		return nil, NetworkError("installing Java runtime", err)
+	}
+	return rt, nil
+}
+
+// RemoveJavaRuntime deletes a managed runtime and resets instances that used it
+func (a *App) RemoveJavaRuntime(id string) error {
+	if err := java.RemoveRuntime(id); err != nil {
+		return ValidationError(err.Error())
+	}
+	for key, selected := range a.cfg.InstanceRuntimes {
+		if selected == id {
+			delete(a.cfg.InstanceRuntimes, key)
+		}
+	}
+	return config.Save(a.cfg)
+}
+
+// GetInstanceJava returns the runtime ID an instance is set to (empty = default)
+func (a *App) GetInstanceJava(branch string, version int) string {
+	return a.cfg.InstanceRuntimes[instanceKey(branch, version)]
+}
+
+// SetInstanceJava makes an instance run on a runtime: a managed runtime ID, a
+// Java executable path, or empty for the default. The runtime is checked first.
+func (a *App) SetInstanceJava(branch string, version int, id string) error {
+	key := instanceKey(branch, version)
+	if id == "" {
+		delete(a.cfg.InstanceRuntimes, key)
+		return config.Save(a.cfg)
+	}
+
+	javaPath, err := java.Resolve(id)
+	if err != nil {
+		return ValidationError(err.Error())
+	}
+	if _, err := java.Validate(a.ctx, javaPath); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	if a.cfg.InstanceRuntimes == nil {
+		a.cfg.InstanceRuntimes = make(map[string]string)
+	}
+	a.cfg.InstanceRuntimes[key] = id
+	return config.Save(a.cfg)
+}
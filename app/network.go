@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/util"
+	"HyVanila/internal/util/download"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// networkSettings builds the network settings from the launcher config
+func (a *App) networkSettings() download.NetworkSettings {
+	return download.NetworkSettings{
+		ProxyMode:     a.cfg.ProxyMode,
+		ProxyURL:      a.cfg.ProxyURL,
+		ProxyUsername: a.cfg.ProxyUsername,
+		ProxyPassword: a.cfg.ProxyPassword,
+		NoProxy:       a.cfg.NoProxy,
+		CABundles:     a.cfg.CABundles,
+	}
+}
+
+// applyNetworkSettings pushes the proxy and CA settings from config to the HTTP clients
+func (a *App) applyNetworkSettings() {
+	if err := download.Configure(a.networkSettings()); err != nil {
+		fmt.Printf("Warning: Invalid network settings, using system defaults: %v\n", err)
+	}
+}
+
+// GetNetworkSettings returns the proxy and trusted CA settings
+func (a *App) GetNetworkSettings() download.NetworkSettings {
+	return a.networkSettings()
+}
+
+// SetNetworkSettings updates the proxy and trusted CA settings. They are checked
+// and applied before being saved, so invalid settings never reach the config.
+func (a *App) SetNetworkSettings(settings download.NetworkSettings) error {
+	if settings.NoProxy == nil {
+		settings.NoProxy = []string{}
+	}
+	if settings.CABundles == nil {
+		settings.CABundles = []string{}
+	}
+	if err := download.Configure(settings); err != nil {
+		return ValidationError(err.Error())
+	}
+
+	a.cfg.ProxyMode = settings.ProxyMode
+	a.cfg.ProxyURL = settings.ProxyURL
+	a.cfg.ProxyUsername = settings.ProxyUsername
+	a.cfg.ProxyPassword = settings.ProxyPassword
+	a.cfg.NoProxy = settings.NoProxy
+	a.cfg.CABundles = settings.CABundles
+	return config.Save(a.cfg)
+}
+
+// SelectCABundle opens a file picker for a PEM certificate bundle and returns its path
+func (a *App) SelectCABundle() (string, error) {
+	selectedFile, err := wailsRuntime.OpenFileDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+		Title: "Select CA Certificate Bundle",
+		Filters: []wailsRuntime.FileFilter{
+			{
+				DisplayName: "Certificates (*.pem, *.crt, *.cer)",
+				Pattern:     "*.pem;*.crt;*.cer",
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	return selectedFile, nil
+}
+
+// applyBandwidthLimits pushes the bandwidth settings from config to the download engine
+func (a *App) applyBandwidthLimits() {
+	limits := download.Limits{
+		ForegroundKBps: a.cfg.ForegroundKBps,
+		BackgroundKBps: a.cfg.BackgroundKBps,
+		Rules:          []download.LimitRule{},
+	}
+	for _, rule := range a.cfg.BandwidthRules {
+		limits.Rules = append(limits.Rules, download.LimitRule{
+			Window:         rule.Window,
+			ForegroundKBps: rule.ForegroundKBps,
+			BackgroundKBps: rule.BackgroundKBps,
+		})
+	}
+	download.SetLimits(limits)
+}
+
+// GetBandwidthLimits returns the download bandwidth limits
+func (a *App) GetBandwidthLimits() download.Limits {
+	return download.GetLimits()
+}
+
+// SetBandwidthLimits updates the download bandwidth limits. Running downloads
+// slow down or speed up right away.
+func (a *App) SetBandwidthLimits(limits download.Limits) error {
+	if limits.ForegroundKBps < 0 || limits.BackgroundKBps < 0 {
+		return ValidationError("Bandwidth limits can't be negative")
+	}
+	rules := []config.BandwidthRule{}
+	for _, rule := range limits.Rules {
+		if _, _, err := util.ParseTimeWindow(rule.Window); err != nil {
+			return ValidationError(err.Error())
+		}
+		if rule.ForegroundKBps < 0 || rule.BackgroundKBps < 0 {
+			return ValidationError(fmt.Sprintf("Bandwidth limits for %s can't be negative", rule.Window))
+		}
+		rules = append(rules, config.BandwidthRule{
+			Window:         rule.Window,
+			ForegroundKBps: rule.ForegroundKBps,
+			BackgroundKBps: rule.BackgroundKBps,
+		})
+	}
+
+	a.cfg.ForegroundKBps = limits.ForegroundKBps
+	a.cfg.BackgroundKBps = limits.BackgroundKBps
+	a.cfg.BandwidthRules = rules
+	a.applyBandwidthLimits()
+	return config.Save(a.cfg)
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"HyVanila/internal/env"
+	"HyVanila/internal/patcher"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DryRunPatch reports what patching an instance would change without modifying it
+func (a *App) DryRunPatch(branch string, version int) (*patcher.DryRunReport, error) {
+	gameDir := env.GetInstanceGameDir(branch, version)
+	if _, err := os.Stat(gameDir); os.IsNotExist(err) {
+		return nil, GameError("Instance is not installed", err)
+	}
+
+	clientPatcher := patcher.NewClientPatcher(a.cfg.AuthDomain)
+	clientPatcher.SetGameVersion(branch, env.GetInstanceBuild(branch, version))
+
+	report, err := clientPatcher.DryRun(gameDir)
+	if err != nil {
+		return nil, GameError("Failed to run patch dry-run", err)
+	}
+	return report, nil
+}
+
+// VerifyPatch checks an instance's patched files against its patch manifest
+func (a *App) VerifyPatch(branch string, version int) (*patcher.PatchVerification, error) {
+	gameDir := env.GetInstanceGameDir(branch, version)
+	if _, err := os.Stat(gameDir); os.IsNotExist(err) {
+		return nil, GameError("Instance is not installed", err)
+	}
+
+	result, err := patcher.VerifyPatch(gameDir)
+	if err != nil {
+		return nil, GameError("Failed to verify patch", err)
+	}
+	return result, nil
+}
+
+// restorePatchedInstances restores the original binaries of every patched instance,
+// reporting progress through "progress-update" events with the "restore" stage
+func (a *App) restorePatchedInstances() error {
+	instances, err := env.ListInstances()
+	if err != nil {
+		return FileSystemError("listing instances", err)
+	}
+
+	var patched []string
+	for _, name := range instances {
+		gameDir := filepath.Join(env.GetInstancesDir(), name, "game")
+		if patcher.PatchedDomain(gameDir) != "" {
+			patched = append(patched, name)
+		}
+	}
+	if len(patched) == 0 {
+		fmt.Println("No patched instances to restore")
+		return nil
+	}
+
+	restorer := patcher.NewClientPatcher("")
+	var failed []string
+	for i, name := range patched {
+		gameDir := filepath.Join(env.GetInstancesDir(), name, "game")
+		base := float64(i) * 100 / float64(len(patched))
+		a.progressCallback("restore", base, fmt.Sprintf("Restoring %s...", name), name, "", 0, 0)
+
+		result := restorer.RestorePatched(gameDir, func(msg string, percent int) {
+			progress := base + float64(percent)/float64(len(patched))
+			a.progressCallback("restore", progress, fmt.Sprintf("%s: %s", name, msg), name, "", 0, 0)
+		})
+		if !result.Success || patcher.PatchedDomain(gameDir) != "" {
+			fmt.Printf("Failed to restore %s: %s\n", name, result.Error)
+			failed = append(failed, name)
+		}
+	}
+
+	a.progressCallback("restore", 100, fmt.Sprintf("Restored %d instance(s)", len(patched)-len(failed)), "", "", 0, 0)
+	if len(failed) > 0 {
+		return GameError("Failed to restore original binaries", fmt.Errorf("instances: %s", strings.Join(failed, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/env"
+	"HyVanila/internal/game"
+	"HyVanila/internal/prefetch"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// prefetchSettings builds the pre-download settings from the launcher config
+func (a *App) prefetchSettings() prefetch.Settings {
+	return prefetch.Settings{
+		Enabled:         a.cfg.AutoUpdateLatest,
+		IntervalMinutes: a.cfg.PrefetchInterval,
+		Windows:         a.cfg.PrefetchWindows,
+		MaxKBps:         a.cfg.PrefetchMaxKBps,
+	}
+}
+
+// startPrefetcher starts pre-downloading updates for "-latest" instances in the background
+func (a *App) startPrefetcher(ctx context.Context) {
+	a.prefetcher = prefetch.NewScheduler(a.prefetchSettings())
+	a.prefetcher.IsBusy = func() bool {
+		return game.IsGameRunning() || game.IsInstalling()
+	}
+	a.prefetcher.OnStatus = func(status prefetch.BranchStatus) {
+		wailsRuntime.EventsEmit(a.ctx, "prefetch-status", status)
+	}
+	a.prefetcher.OnReady = a.applyPrefetchedUpdate
+
+	ctx, a.stopPrefetcher = context.WithCancel(ctx)
+	go a.prefetcher.Run(ctx)
+}
+
+// applyPrefetchedUpdate installs a pre-downloaded build right away if nothing is
+// using the instance; otherwise it is applied at the next launch
+func (a *App) applyPrefetchedUpdate(branch string, build int) {
+	if game.IsGameRunning() || game.IsInstalling() {
+		return
+	}
+	if server := a.serverManager.Get(branch, 0); server != nil && server.IsRunning() {
+		return
+	}
+	fmt.Printf("Applying pre-downloaded %s build %d\n", branch, build)
+	if err := game.UpdateLatestInstance(a.ctx, branch, a.progressCallback); err != nil {
+		fmt.Printf("Warning: Failed to apply %s build %d: %v\n", branch, build, err)
+		return
+	}
+	a.prefetcher.Wake()
+}
+
+// updateLatestBeforeLaunch applies a pre-downloaded update to a "-latest" instance
+func (a *App) updateLatestBeforeLaunch(branch string, version int) error {
+	if version != 0 || !a.cfg.AutoUpdateLatest || a.prefetcher == nil {
+		return nil
+	}
+	ready := a.prefetcher.ReadyBuild(branch)
+	if ready == 0 || ready <= env.GetInstanceBuild(branch, 0) {
+		return nil
+	}
+	if err := game.UpdateLatestInstance(a.ctx, branch, a.progressCallback); err != nil {
+		return err
+	}
+	a.prefetcher.Wake()
+	return nil
+}
+
+// GetPrefetchStatus returns the background download state of each "-latest" instance
+func (a *App) GetPrefetchStatus() []prefetch.BranchStatus {
+	if a.prefetcher == nil {
+		return []prefetch.BranchStatus{}
+	}
+	return a.prefetcher.Status()
+}
+
+// GetPrefetchSettings returns when and how fast updates are downloaded in the background
+func (a *App) GetPrefetchSettings() prefetch.Settings {
+	return a.prefetchSettings()
+}
+
+// SetPrefetchSchedule sets the background download windows ("HH:MM-HH:MM", empty
+// for any time), the minutes between update checks and the bandwidth cap in KB/s
+func (a *App) SetPrefetchSchedule(windows []string, intervalMinutes int, maxKBps int) error {
+	for _, window := range windows {
+		if err := prefetch.ValidateWindow(window); err != nil {
+			return ValidationError(err.Error())
+		}
+	}
+	if intervalMinutes < 5 {
+		return ValidationError("Update checks must be at least 5 minutes apart")
+	}
+	if maxKBps < 0 {
+		return ValidationError("Bandwidth cap can't be negative")
+	}
+
+	if windows == nil {
+		windows = []string{}
+	}
+	a.cfg.PrefetchWindows = windows
+	a.cfg.PrefetchInterval = intervalMinutes
+	a.cfg.PrefetchMaxKBps = maxKBps
+	if err := config.Save(a.cfg); err != nil {
+		return err
+	}
+	if a.prefetcher != nil {
+		a.prefetcher.SetSettings(a.prefetchSettings())
+	}
+	return nil
+}
+
+// CheckForGameUpdatesNow checks for new builds without waiting for the next interval
+func (a *App) CheckForGameUpdatesNow() {
+	if a.prefetcher != nil {
+		a.prefetcher.CheckNow()
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/env"
+	"HyVanila/internal/relocate"
+)
+
+// relocateInstances moves all instances to newDir ("" means the default
+// location) and switches the instances directory once they are verified there
+func (a *App) relocateInstances(newDir string) error {
+	if a.IsGameRunning() {
+		return GameError("Close the game before moving instances", nil)
+	}
+	for _, status := range a.serverManager.List() {
+		if status.Running {
+			return GameError("Stop all servers before moving instances", nil)
+		}
+	}
+
+	current := env.GetInstancesDir()
+	target := newDir
+	if target == "" {
+		target = filepath.Join(env.GetDefaultAppDir(), "instances")
+	}
+	if filepath.Clean(current) == filepath.Clean(target) {
+		return a.switchInstanceDir(newDir)
+	}
+
+	job, err := relocate.Plan(current, target)
+	if err != nil {
+		return FileSystemError("preparing to move instances", err)
+	}
+	return a.runRelocation(job, newDir)
+}
+
+// runRelocation runs a relocation job; newDir is the value saved to the config on success
+func (a *App) runRelocation(job *relocate.Job, newDir string) error {
+	err := job.Run(func(string) error {
+		return a.switchInstanceDir(newDir)
+	}, a.progressCallback)
+	if err != nil {
+		return FileSystemError("moving instances", err)
+	}
+	return nil
+}
+
+// switchInstanceDir points the launcher at a new instances directory
+func (a *App) switchInstanceDir(dir string) error {
+	a.cfg.CustomInstanceDir = dir
+	env.SetCustomInstanceDir(dir) // Update the env module
+	if err := config.Save(a.cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// GetPendingRelocation returns an interrupted instance move, or nil if there is none
+func (a *App) GetPendingRelocation() *relocate.Job {
+	return relocate.Pending()
+}
+
+// ResumeRelocation continues an interrupted instance move
+func (a *App) ResumeRelocation() error {
+	job := relocate.Pending()
+	if job == nil {
+		return ValidationError("There is no instance move to resume")
+	}
+	if a.IsGameRunning() {
+		return GameError("Close the game before moving instances", nil)
+	}
+
+	// The config stores "" for the default location
+	newDir := job.Dest
+	if filepath.Clean(job.Dest) == filepath.Clean(filepath.Join(env.GetDefaultAppDir(), "instances")) {
+		newDir = ""
+	}
+	return a.runRelocation(job, newDir)
+}
+
+// CancelRelocation abandons an interrupted instance move and undoes its partial work
+func (a *App) CancelRelocation() error {
+	job := relocate.Pending()
+	if job == nil {
+		return nil
+	}
+	if err := job.Cancel(); err != nil {
+		return FileSystemError("cancelling instance move", err)
+	}
+	return nil
+}
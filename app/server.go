@@ -0,0 +1,189 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/env"
+	"HyVanila/internal/game"
+	"HyVanila/internal/patcher"
+	"HyVanila/internal/server"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// serverReadyTimeout is how long StartServerAndLaunch waits for the server before launching the client
+const serverReadyTimeout = 2 * time.Minute
+
+// StartServer starts the dedicated server bundled with an instance.
+// Console output is emitted as "server-console" events, state changes as "server-status".
+func (a *App) StartServer(branch string, version int) (*server.Status, error) {
+	if !env.IsVersionInstalled(branch, version) {
+		return nil, GameError("Instance is not installed", fmt.Errorf("%s v%d", branch, version))
+	}
+
+	authMode := "offline"
+	if a.cfg.OnlineMode {
+		// The server validates tokens against the same auth domain as the client
+		serverPatcher := patcher.NewClientPatcher(a.cfg.AuthDomain)
+		serverPatcher.SetGameVersion(branch, env.GetInstanceBuild(branch, version))
+		result := serverPatcher.EnsurePatched(env.GetInstanceGameDir(branch, version), nil)
+		if !result.Success {
+			return nil, GameError("Failed to patch server for online mode", fmt.Errorf("%s", result.Error))
+		}
+		authMode = "authenticated"
+	}
+
+	s, err := a.serverManager.Start(server.Options{
+		Branch:    branch,
+		Version:   version,
+		JavaPath:  a.javaPathFor(branch, version),
+		MaxMemory: a.cfg.ServerMaxMemory,
+		MinMemory: a.cfg.ServerMinMemory,
+		Port:      a.cfg.ServerPort,
+		AuthMode:  authMode,
+		ExtraArgs: strings.Fields(a.cfg.ServerArgs),
+		OnOutput: func(line server.ConsoleLine) {
+			wailsRuntime.EventsEmit(a.ctx, "server-console", line)
+		},
+		OnExit: func(status server.Status) {
+			wailsRuntime.EventsEmit(a.ctx, "server-status", status)
+		},
+	})
+	if err != nil {
+		wrappedErr := GameError("Failed to start server", err)
+		a.emitError(wrappedErr)
+		return nil, wrappedErr
+	}
+
+	status := s.Status()
+	wailsRuntime.EventsEmit(a.ctx, "server-status", status)
+	return &status, nil
+}
+
+// StopServer gracefully stops an instance's dedicated server
+func (a *App) StopServer(branch string, version int) error {
+	s := a.serverManager.Get(branch, version)
+	if s == nil {
+		return nil
+	}
+	if err := s.Stop(); err != nil {
+		return GameError("Failed to stop server", err)
+	}
+	return nil
+}
+
+// KillServer terminates an instance's dedicated server immediately
+func (a *App) KillServer(branch string, version int) error {
+	s := a.serverManager.Get(branch, version)
+	if s == nil {
+		return nil
+	}
+	if err := s.Kill(); err != nil {
+		return GameError("Failed to kill server", err)
+	}
+	return nil
+}
+
+// SendServerCommand sends a console command to an instance's dedicated server
+func (a *App) SendServerCommand(branch string, version int, command string) error {
+	s := a.serverManager.Get(branch, version)
+	if s == nil {
+		return GameError("Server is not running", nil)
+	}
+	if err := s.SendCommand(command); err != nil {
+		return GameError("Failed to send command", err)
+	}
+	return nil
+}
+
+// GetServerStatus returns the status of an instance's dedicated server
+func (a *App) GetServerStatus(branch string, version int) server.Status {
+	if s := a.serverManager.Get(branch, version); s != nil {
+		return s.Status()
+	}
+	return server.Status{
+		Branch:  branch,
+		Version: version,
+		Port:    a.cfg.ServerPort,
+		DataDir: server.GetDataDir(branch, version),
+	}
+}
+
+// GetServerStatuses returns the status of all dedicated servers started this session
+func (a *App) GetServerStatuses() []server.Status {
+	return a.serverManager.List()
+}
+
+// GetServerConsole returns the recent console output of an instance's dedicated server
+func (a *App) GetServerConsole(branch string, version int) []server.ConsoleLine {
+	if s := a.serverManager.Get(branch, version); s != nil {
+		return s.Console()
+	}
+	return []server.ConsoleLine{}
+}
+
+// StartServerAndLaunch starts an instance's dedicated server (if it isn't running),
+// waits for it to come up and launches the client connected to it
+func (a *App) StartServerAndLaunch(playerName string, branch string, version int) error {
+	s := a.serverManager.Get(branch, version)
+	if s == nil || !s.IsRunning() {
+		a.progressCallback("launch", 0, "Starting local server...", "", "", 0, 0)
+		if _, err := a.StartServer(branch, version); err != nil {
+			return err
+		}
+		s = a.serverManager.Get(branch, version)
+	}
+
+	a.progressCallback("launch", 50, "Waiting for local server...", "", "", 0, 0)
+	if !s.WaitReady(serverReadyTimeout) {
+		if !s.IsRunning() {
+			err := GameError("Server exited before it was ready", fmt.Errorf("%s", s.Status().Error))
+			a.emitError(err)
+			return err
+		}
+		fmt.Println("Warning: Server did not report ready in time, launching client anyway")
+	}
+
+	a.progressCallback("launch", 100, "Launching game...", "", "", 0, 0)
+	opts := a.newLaunchOptions(playerName, branch, version)
+	opts.ConnectTo = "127.0.0.1:" + strconv.Itoa(s.Status().Port)
+
+	if err := game.LaunchInstanceWithOptions(opts); err != nil {
+		wrappedErr := GameError("Failed to launch game", err)
+		a.emitError(wrappedErr)
+		return wrappedErr
+	}
+	return nil
+}
+
+// SetServerMemory sets the dedicated server memory limits in MB
+func (a *App) SetServerMemory(maxMemory int, minMemory int) error {
+	if minMemory > maxMemory {
+		return ValidationError("Minimum server memory can't exceed the maximum")
+	}
+	if err := config.ValidateMemory(minMemory, maxMemory); err != nil {
+		return ValidationError("Server " + err.Error())
+	}
+	a.cfg.ServerMaxMemory = maxMemory
+	a.cfg.ServerMinMemory = minMemory
+	return a.SaveConfig()
+}
+
+// SetServerPort sets the dedicated server port
+func (a *App) SetServerPort(port int) error {
+	if port <= 0 || port > 65535 {
+		return ValidationError("Invalid server port")
+	}
+	a.cfg.ServerPort = port
+	return a.SaveConfig()
+}
+
+// SetServerArgs sets extra arguments passed to the dedicated server
+func (a *App) SetServerArgs(args string) error {
+	a.cfg.ServerArgs = args
+	return a.SaveConfig()
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"HyVanila/internal/config"
+	"HyVanila/internal/game"
+	"HyVanila/internal/patcher"
+	"HyVanila/internal/server"
+)
+
+// GetSavedServers returns the saved server favorites
+func (a *App) GetSavedServers() []config.SavedServer {
+	if a.cfg.Servers == nil {
+		return []config.SavedServer{}
+	}
+	return a.cfg.Servers
+}
+
+// AddSavedServer adds a server to the favorites and returns it with its new ID
+func (a *App) AddSavedServer(srv config.SavedServer) (*config.SavedServer, error) {
+	if err := validateSavedServer(&srv); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	srv.ID = hex.EncodeToString(id)
+	srv.AddedAt = time.Now().Format(time.RFC3339)
+	srv.LastPlayed = ""
+
+	a.cfg.Servers = append(a.cfg.Servers, srv)
+	if err := config.Save(a.cfg); err != nil {
+		return nil, FileSystemError("saving config", err)
+	}
+	return &srv, nil
+}
+
+// UpdateSavedServer replaces a saved server's settings
+func (a *App) UpdateSavedServer(srv config.SavedServer) error {
+	existing := a.cfg.FindServer(srv.ID)
+	if existing == nil {
+		return ValidationError(fmt.Sprintf("Server not found: %s", srv.ID))
+	}
+	if err := validateSavedServer(&srv); err != nil {
+		return err
+	}
+
+	srv.AddedAt = existing.AddedAt
+	srv.LastPlayed = existing.LastPlayed
+	*existing = srv
+	return config.Save(a.cfg)
+}
+
+// RemoveSavedServer removes a server from the favorites
+func (a *App) RemoveSavedServer(id string) error {
+	servers := make([]config.SavedServer, 0, len(a.cfg.Servers))
+	for _, srv := range a.cfg.Servers {
+		if srv.ID != id {
+			servers = append(servers, srv)
+		}
+	}
+	if len(servers) == len(a.cfg.Servers) {
+		return ValidationError(fmt.Sprintf("Server not found: %s", id))
+	}
+
+	a.cfg.Servers = servers
+	return config.Save(a.cfg)
+}
+
+// PingSavedServer checks whether a saved server is reachable and measures latency
+func (a *App) PingSavedServer(id string) (*server.PingResult, error) {
+	srv := a.cfg.FindServer(id)
+	if srv == nil {
+		return nil, ValidationError(fmt.Sprintf("Server not found: %s", id))
+	}
+	result := server.Ping(srv.Address)
+	return &result, nil
+}
+
+// PingAddress checks whether an arbitrary server address is reachable
+func (a *App) PingAddress(address string) server.PingResult {
+	return server.Ping(address)
+}
+
+// LaunchToServer installs the server's instance if needed, patches it for the
+// server's auth domain and launches the client connected to the server
+func (a *App) LaunchToServer(id string) error {
+	srv := a.cfg.FindServer(id)
+	if srv == nil {
+		err := ValidationError(fmt.Sprintf("Server not found: %s", id))
+		a.emitError(err)
+		return err
+	}
+
+	branch, version := srv.Branch, srv.Version
+	if branch == "" {
+		branch = a.GetVersionType()
+		version = a.GetSelectedVersion()
+	}
+
+	nick := srv.Nick
+	if nick == "" {
+		nick = a.cfg.Nick
+	}
+	if len(nick) == 0 || len(nick) > 16 {
+		err := ValidationError("Please enter a nickname (max 16 characters)")
+		a.emitError(err)
+		return err
+	}
+
+	if err := game.EnsureInstalledVersionSpecific(a.ctx, branch, version, a.progressCallback); err != nil {
+		wrappedErr := GameError("Failed to install or update game", err)
+		a.emitError(wrappedErr)
+		return wrappedErr
+	}
+
+	a.progressCallback("launch", 100, fmt.Sprintf("Joining %s...", srv.Name), "", "", 0, 0)
+
+	opts := a.newLaunchOptions(nick, branch, version)
+	opts.ConnectTo = srv.Address
+	if srv.AuthDomain != "" {
+		// Launch patches the instance for this domain, restoring it first if it
+		// was patched for another one
+		opts.OnlineMode = true
+		opts.AuthDomain = srv.AuthDomain
+	} else {
+		// Servers without an auth domain are joined offline, whatever the
+		// launcher's own online setting is
+		opts.OnlineMode = false
+	}
+
+	if err := game.LaunchInstanceWithOptions(opts); err != nil {
+		wrappedErr := GameError("Failed to launch game", err)
+		a.emitError(wrappedErr)
+		return wrappedErr
+	}
+
+	srv.LastPlayed = time.Now().Format(time.RFC3339)
+	if err := config.Save(a.cfg); err != nil {
+		fmt.Printf("Warning: Failed to save config: %v\n", err)
+	}
+
+	if a.cfg.DiscordRPCEnabled && a.discordService != nil {
+		versionStr := strconv.Itoa(version)
+		if version == 0 {
+			versionStr = "Latest"
+		}
+		a.discordService.SetPlaying(versionStr)
+	}
+	return nil
+}
+
+// validateSavedServer checks and normalizes a saved server's fields
+func validateSavedServer(srv *config.SavedServer) error {
+	srv.Name = strings.TrimSpace(srv.Name)
+	srv.AuthDomain = strings.TrimSpace(srv.AuthDomain)
+
+	address, err := server.NormalizeAddress(strings.TrimSpace(srv.Address))
+	if err != nil {
+		return ValidationError(fmt.Sprintf("Invalid server address: %v", err))
+	}
+	srv.Address = address
+	if srv.Name == "" {
+		srv.Name = address
+	}
+
+	if err := validateAuthDomain(srv.AuthDomain); err != nil {
+		return err
+	}
+	if len(srv.Nick) > 16 {
+		return ValidationError("Nickname is too long (max 16 characters)")
+	}
+	return nil
+}
+
+// validateAuthDomain checks a custom auth domain ("" is the default domain)
+func validateAuthDomain(domain string) error {
+	if domain == "" {
+		return nil
+	}
+	if err := config.ValidateDomain(domain); err != nil {
+		return ValidationError(fmt.Sprintf("Invalid auth domain: %v", err))
+	}
+	// The patcher can only substitute domains of the same length
+	if len(domain) != len(patcher.OriginalDomain) {
+		return ValidationError(fmt.Sprintf("Auth domain must be exactly %d characters long", len(patcher.OriginalDomain)))
+	}
+	return nil
+}
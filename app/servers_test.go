@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+
+	"HyVanila/internal/config"
+)
+
+func TestValidateSavedServerAuthDomain(t *testing.T) {
+	tests := []struct {
+		domain  string
+		wantErr bool
+	}{
+		{"", false},
+		{"sanasol.ws", false},
+		{" example.io ", false},
+		{"http://a/x", true},  // right length, but not a domain
+		{"a.b/c:d.ef", true},  // right length, but not a domain
+		{"example.com", true}, // valid, but longer than the original
+		{"short.io", true},    // valid, but shorter than the original
+		{"exa mple.c", true},  // right length, but contains a space
+	}
+	for _, tt := range tests {
+		srv := &config.SavedServer{Address: "play.example.com", AuthDomain: tt.domain}
+		if err := validateSavedServer(srv); (err != nil) != tt.wantErr {
+			t.Errorf("AuthDomain %q: validateSavedServer = %v, want error %v", tt.domain, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"HyVanila/internal/config"
+	"HyVanila/internal/gamestore"
+	"HyVanila/internal/storage"
+)
+
+// GetStorageReport returns real versus apparent disk usage per instance
+func (a *App) GetStorageReport() (*gamestore.StorageReport, error) {
+	report, err := gamestore.GetStorageReport()
+	if err != nil {
+		return nil, FileSystemError("measuring instance storage", err)
+	}
+	return report, nil
+}
+
+// GetShareGameFiles returns whether identical game files are shared between instances
+func (a *App) GetShareGameFiles() bool {
+	return a.cfg.ShareGameFiles
+}
+
+// SetShareGameFiles turns sharing of game files for new installs on or off.
+// Files already shared stay shared until their instance is updated or deleted.
+func (a *App) SetShareGameFiles(enabled bool) error {
+	a.cfg.ShareGameFiles = enabled
+	gamestore.SetEnabled(enabled)
+	return config.Save(a.cfg)
+}
+
+// CollectGameStoreGarbage deletes shared game files no instance uses anymore
+// and returns the bytes freed
+func (a *App) CollectGameStoreGarbage() (int64, error) {
+	_, freed, err := gamestore.CollectGarbage()
+	if err != nil {
+		return freed, FileSystemError("cleaning up game file store", err)
+	}
+	return freed, nil
+}
+
+// applyCachePolicy pushes the cache settings from config to the storage subsystem
+func (a *App) applyCachePolicy() {
+	storage.SetCachePolicy(storage.CachePolicy{
+		MaxSizeMB:   a.cfg.CacheMaxSizeMB,
+		KeepPatches: a.cfg.CacheKeepPatches,
+	})
+	storage.ApplyCachePolicy()
+}
+
+// GetDiskUsage returns how much disk space instances, backups, caches and logs use
+func (a *App) GetDiskUsage() (*storage.Report, error) {
+	report, err := storage.GetReport()
+	if err != nil {
+		return nil, FileSystemError("measuring disk usage", err)
+	}
+	return report, nil
+}
+
+// GetCachePolicy returns the active cache policy
+func (a *App) GetCachePolicy() storage.CachePolicy {
+	return storage.GetCachePolicy()
+}
+
+// SetCachePolicy updates how large the cache may grow and how many game patches it keeps
+func (a *App) SetCachePolicy(policy storage.CachePolicy) error {
+	if policy.MaxSizeMB < 0 || policy.KeepPatches < 0 {
+		return ValidationError("Cache limits can't be negative")
+	}
+	a.cfg.CacheMaxSizeMB = policy.MaxSizeMB
+	a.cfg.CacheKeepPatches = policy.KeepPatches
+	if err := config.Save(a.cfg); err != nil {
+		return err
+	}
+	a.applyCachePolicy()
+	return nil
+}
+
+// CleanupStorage frees disk space; files used by a running install are skipped
+func (a *App) CleanupStorage(opts storage.CleanupOptions) *storage.CleanupResult {
+	return storage.Cleanup(opts)
+}
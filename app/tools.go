@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"HyVanila/internal/java"
+	"HyVanila/internal/pwr/butler"
+	"HyVanila/internal/tools"
+	"HyVanila/internal/util/download"
+)
+
+// GetTools returns the installed helper tools (butler and Java runtimes) with
+// their versions and checksums
+func (a *App) GetTools() map[string]tools.Tool {
+	return tools.List()
+}
+
+// startToolUpdates keeps the installed Java runtime and butler on their pinned
+// versions while the launcher runs. Tools that aren't installed yet are left
+// to the next game install.
+func (a *App) startToolUpdates() {
+	go func() {
+		ticker := time.NewTicker(tools.CheckInterval)
+		defer ticker.Stop()
+		for {
+			a.updateTools()
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) updateTools() {
+	ctx := download.Background(a.ctx)
+	if _, err := java.GetJavaExec(); err == nil {
+		if err := java.DownloadJRE(ctx, nil); err != nil {
+			fmt.Printf("Warning: Java Runtime update check failed: %v\n", err)
+		}
+	}
+	if butlerPath, err := butler.GetButlerPath(); err == nil {
+		if _, err := os.Stat(butlerPath); err == nil {
+			if _, err := butler.InstallButler(ctx, nil); err != nil {
+				fmt.Printf("Warning: Butler update check failed: %v\n", err)
+			}
+		}
+	}
+}
@@ -1,84 +1,156 @@
 package app
 
 import (
-	"HyVanila/internal/util"
+	"HyVanila/internal/config"
 	"HyVanila/updater"
+	"errors"
 	"fmt"
-	"os"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// CheckUpdate checks for launcher updates
-func (a *App) CheckUpdate() (*updater.Asset, error) {
+// updateChannel returns the configured update channel, or the channel this
+// build was published on if none is set
+func (a *App) updateChannel() string {
+	if a.cfg.UpdateChannel != "" {
+		return a.cfg.UpdateChannel
+	}
+	return updater.DefaultChannel(AppVersion)
+}
+
+// checkForUpdate checks the configured channel, skipping an update that was
+// rolled back in this session
+func (a *App) checkForUpdate() (*updater.UpdateCheck, error) {
+	check, err := updater.CheckUpdate(a.ctx, AppVersion, a.updateChannel(), a.allowDowngrade)
+	if err != nil || check == nil {
+		return check, err
+	}
+	if check.Version == a.rolledBackFrom {
+		fmt.Printf("Skipping update to %s, which failed to start\n", check.Version)
+		return nil, nil
+	}
+	return check, nil
+}
+
+// CheckUpdate checks for launcher updates. The result carries the release
+// notes, so they can be shown before installing; it is nil when up to date.
+func (a *App) CheckUpdate() (*updater.UpdateCheck, error) {
 	fmt.Println("Checking for launcher updates...")
 
-	asset, newVersion, err := updater.CheckUpdate(a.ctx, AppVersion)
+	check, err := a.checkForUpdate()
 	if err != nil {
 		fmt.Printf("Update check failed: %v\n", err)
 		return nil, nil
 	}
 
-	if asset != nil {
-		fmt.Printf("Update available: %s\n", newVersion)
+	if check != nil {
+		fmt.Printf("Update available: %s\n", check.Version)
 	} else {
 		fmt.Println("No update available")
 	}
 
-	return asset, nil
+	return check, nil
+}
+
+// GetUpdateChannel returns the launcher update channel
+func (a *App) GetUpdateChannel() string {
+	return a.updateChannel()
+}
+
+// SetUpdateChannel switches the launcher update channel and checks it for an
+// update. allowDowngrade accepts an older version from the new channel, e.g.
+// when going from nightly back to stable; otherwise the launcher stays on its
+// current version until the channel catches up.
+func (a *App) SetUpdateChannel(channel string, allowDowngrade bool) (*updater.UpdateCheck, error) {
+	if err := updater.ValidateChannel(channel); err != nil {
+		return nil, ValidationError(err.Error())
+	}
+
+	a.cfg.UpdateChannel = channel
+	if err := config.Save(a.cfg); err != nil {
+		return nil, err
+	}
+	a.allowDowngrade = allowDowngrade
+
+	check, err := updater.CheckUpdate(a.ctx, AppVersion, channel, allowDowngrade)
+	if err != nil {
+		if errors.Is(err, updater.ErrUnverified) {
+			return nil, WrapError(ErrorTypeValidation, "Update info verification failed", err)
+		}
+		return nil, NetworkError("checking for launcher updates", err)
+	}
+	return check, nil
 }
 
 // Update downloads and applies a launcher update
 func (a *App) Update() error {
 	fmt.Println("Starting launcher update process...")
 
-	asset, newVersion, err := updater.CheckUpdate(a.ctx, AppVersion)
+	check, err := a.checkForUpdate()
 	if err != nil {
 		fmt.Printf("Update check failed: %v\n", err)
+		if errors.Is(err, updater.ErrUnverified) {
+			return WrapError(ErrorTypeValidation, "Update info verification failed", err)
+		}
 		return WrapError(ErrorTypeNetwork, "Failed to check for updates", err)
 	}
 
-	if asset == nil {
+	if check == nil {
 		fmt.Println("No update available")
 		return nil
 	}
+	if check.Asset == nil {
+		// Package manager installs (e.g. Flatpak) must not replace themselves
+		return ValidationError(check.Instructions)
+	}
 
-	fmt.Printf("Downloading update from: %s\n", asset.URL)
+	fmt.Printf("Downloading update from: %s\n", check.Asset.URL)
 
-	tmp, err := updater.DownloadUpdate(a.ctx, asset.URL, func(stage string, progress float64, message string, currentFile string, speed string, downloaded int64, total int64) {
+	tmp, err := updater.DownloadUpdate(a.ctx, check.Asset, func(stage string, progress float64, message string, currentFile string, speed string, downloaded int64, total int64) {
 		fmt.Printf("[%s] %s: %.1f%% (%d/%d bytes) at %s\n", stage, message, progress, downloaded, total, speed)
 		runtime.EventsEmit(a.ctx, "update:progress", stage, progress, message, currentFile, speed, downloaded, total)
 	})
 
 	if err != nil {
 		fmt.Printf("Download failed: %v\n", err)
+		if errors.Is(err, updater.ErrUnverified) {
+			return WrapError(ErrorTypeValidation, "Update file verification failed", err)
+		}
 		return NetworkError("downloading launcher update", err)
 	}
 
 	fmt.Printf("Download complete: %s\n", tmp)
 
-	// Verify checksum if provided
-	if asset.Sha256 != "" {
-		fmt.Println("Verifying download checksum...")
-		if err := util.VerifySHA256(tmp, asset.Sha256); err != nil {
-			fmt.Printf("Verification failed: %v\n", err)
-			os.Remove(tmp)
-			return WrapError(ErrorTypeValidation, "Update file verification failed", err)
-		}
-		fmt.Println("Checksum verified successfully")
-	} else {
-		fmt.Println("Warning: No checksum provided, skipping verification")
-	}
-
 	fmt.Println("Applying update...")
 
-	if err := updater.Apply(tmp); err != nil {
+	if err := updater.Apply(tmp, AppVersion, check.Version, check.Rollback); err != nil {
 		fmt.Printf("Failed to start update helper: %v\n", err)
 		return FileSystemError("starting updater", err)
 	}
 
-	fmt.Printf("Update helper started successfully, exiting launcher (updating to version %s)...\n", newVersion)
-	os.Exit(0)
+	return nil
+}
+
+// GetLauncherRollback returns the launcher version a revert goes back to, and
+// an update that was rolled back because it failed to start
+func (a *App) GetLauncherRollback() updater.RollbackInfo {
+	return updater.RollbackInfo{
+		PreviousVersion: updater.PreviousVersion(),
+		RolledBackFrom:  a.rolledBackFrom,
+	}
+}
+
+// RevertLauncher replaces this launcher with the previously installed version
+// and restarts. The current version is kept, so the revert can be undone.
+func (a *App) RevertLauncher() error {
+	previous := updater.PreviousVersion()
+	if previous == "" {
+		return ValidationError("No previous launcher version is available")
+	}
+	fmt.Printf("Reverting launcher %s to %s...\n", AppVersion, previous)
+	if err := updater.Revert(AppVersion); err != nil {
+		return FileSystemError("reverting launcher", err)
+	}
 	return nil
 }
 
@@ -86,17 +158,17 @@ func (a *App) Update() error {
 func (a *App) checkUpdateSilently() {
 	fmt.Println("Running silent update check...")
 
-	asset, newVersion, err := updater.CheckUpdate(a.ctx, AppVersion)
+	check, err := a.checkForUpdate()
 	if err != nil {
 		fmt.Printf("Silent update check failed (this is normal if offline): %v\n", err)
 		return
 	}
 
-	if asset == nil {
+	if check == nil {
 		fmt.Println("No update available (silent check)")
 		return
 	}
 
-	fmt.Printf("Update available: %s (notifying frontend)\n", newVersion)
-	runtime.EventsEmit(a.ctx, "update:available", asset)
+	fmt.Printf("Update available: %s (notifying frontend)\n", check.Version)
+	runtime.EventsEmit(a.ctx, "update:available", check)
 }
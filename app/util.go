@@ -2,6 +2,7 @@ package app
 
 import (
 	"HyVanila/internal/env"
+	"HyVanila/internal/storage"
 	"fmt"
 	"os"
 	"os/exec"
@@ -71,8 +72,9 @@ func (a *App) RepairInstallation() error {
 	cacheDir := env.GetCacheDir()
 	cacheEntries, _ := os.ReadDir(cacheDir)
 	for _, entry := range cacheEntries {
-		if strings.HasSuffix(entry.Name(), ".pwr") {
-			os.Remove(filepath.Join(cacheDir, entry.Name()))
+		pwrPath := filepath.Join(cacheDir, entry.Name())
+		if strings.HasSuffix(entry.Name(), ".pwr") && !storage.IsInUse(pwrPath) {
+			os.Remove(pwrPath)
 		}
 	}
 	
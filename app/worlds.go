@@ -0,0 +1,85 @@
+package app
+
+import (
+	"fmt"
+
+	"HyVanila/internal/worlds"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// GetWorlds returns the worlds of an instance with size and last played time
+func (a *App) GetWorlds(branch string, version int) ([]worlds.World, error) {
+	list, err := worlds.List(branch, version)
+	if err != nil {
+		return nil, FileSystemError("listing worlds", err)
+	}
+	return list, nil
+}
+
+// ExportWorld asks for a destination and writes a world as a zip archive
+func (a *App) ExportWorld(branch string, version int, name string) (string, error) {
+	destPath, err := wailsRuntime.SaveFileDialog(a.ctx, wailsRuntime.SaveDialogOptions{
+		Title:           "Export World",
+		DefaultFilename: name + ".zip",
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "Zip Archives (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open save dialog: %w", err)
+	}
+	if destPath == "" {
+		// User cancelled the dialog
+		return "", nil
+	}
+
+	if err := worlds.Export(branch, version, name, destPath); err != nil {
+		return "", FileSystemError("exporting world", err)
+	}
+	return destPath, nil
+}
+
+// ImportWorld asks for a world zip and imports it into an instance.
+// conflict is "fail", "rename" or "overwrite" and applies if the world name is taken.
+func (a *App) ImportWorld(branch string, version int, conflict string) (*worlds.World, error) {
+	zipPath, err := wailsRuntime.OpenFileDialog(a.ctx, wailsRuntime.OpenDialogOptions{
+		Title: "Import World",
+		Filters: []wailsRuntime.FileFilter{
+			{DisplayName: "Zip Archives (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
+	}
+	if zipPath == "" {
+		// User cancelled the dialog
+		return nil, nil
+	}
+	return a.ImportWorldFile(zipPath, branch, version, conflict)
+}
+
+// ImportWorldFile imports a world zip from a known path into an instance
+func (a *App) ImportWorldFile(zipPath string, branch string, version int, conflict string) (*worlds.World, error) {
+	if conflict == worlds.ConflictOverwrite && a.IsGameRunning() {
+		return nil, GameError("Close the game before overwriting a world", nil)
+	}
+	world, err := worlds.Import(zipPath, branch, version, conflict)
+	if err != nil {
+		return nil, FileSystemError("importing world", err)
+	}
+	return world, nil
+}
+
+// CopyWorld copies a world between instances.
+// conflict is "fail", "rename" or "overwrite" and applies if the world name is taken.
+func (a *App) CopyWorld(srcBranch string, srcVersion int, name string, dstBranch string, dstVersion int, conflict string) (*worlds.World, error) {
+	if conflict == worlds.ConflictOverwrite && a.IsGameRunning() {
+		return nil, GameError("Close the game before overwriting a world", nil)
+	}
+	world, err := worlds.Copy(srcBranch, srcVersion, name, dstBranch, dstVersion, conflict)
+	if err != nil {
+		return nil, FileSystemError("copying world", err)
+	}
+	return world, nil
+}
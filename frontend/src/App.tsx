@@ -37,6 +37,7 @@ import {
   GetNews,
   GetLauncherVersion,
   GetGameLogs,
+  GetConfigProblem,
 } from '../wailsjs/go/app/App';
 import { EventsOn } from '../wailsjs/runtime/runtime';
 import { NewsPreview } from './components/NewsPreview';
@@ -91,6 +92,17 @@ const App: React.FC = () => {
     document.documentElement.lang = i18n.language;
   }, [i18n.language]);
 
+  // Tell the user about settings that were reset because the config was invalid
+  useEffect(() => {
+    GetConfigProblem()
+      .then((problem) => {
+        if (problem) {
+          setError({ ...problem, message: t(problem.message), timestamp: new Date().toISOString() });
+        }
+      })
+      .catch((err) => console.error('Failed to check config:', err));
+  }, []);
+
   // Check if current version is installed when branch or version changes
   useEffect(() => {
     const checkInstalled = async () => {
@@ -218,9 +230,9 @@ const App: React.FC = () => {
         try {
           // Add a small delay for better UX (so the checking screen doesn't flash too fast)
           await new Promise(r => setTimeout(r, 1500));
-          const asset = await CheckUpdate();
-          if (asset) {
-            setBlockingUpdate(asset);
+          const update = await CheckUpdate();
+          if (update) {
+            setBlockingUpdate(update);
           }
         } catch (e) {
           console.error('Failed to check for updates:', e);
@@ -298,10 +310,10 @@ const App: React.FC = () => {
       }
     });
 
-    const unsubUpdate = EventsOn('update:available', (asset: any) => {
-      setUpdateAsset(asset);
+    const unsubUpdate = EventsOn('update:available', (update: any) => {
+      setUpdateAsset(update);
       // Don't auto-update - let user click the update button
-      console.log('Update available:', asset);
+      console.log('Update available:', update);
     });
 
     const unsubUpdateProgress = EventsOn('update:progress', (_stage: string, progress: number, _message: string, _file: string, _speed: string, downloaded: number, total: number) => {
@@ -451,17 +463,36 @@ const App: React.FC = () => {
               <svg xmlns="http://www.w3.org/2000/svg" width="32" height="32" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2" strokeLinecap="round" strokeLinejoin="round"><path d="M21 15v4a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2v-4" /><polyline points="7 10 12 15 17 10" /><line x1="12" x2="12" y1="15" y2="3" /></svg>
             </div>
             <h2 className="text-2xl font-bold text-white mb-2">{t('Update Available')}</h2>
-            <p className="text-white/60 mb-8">
-              {t('A new version of HyVanila is available.')}
-              <span className="block mt-1 text-[#FFA845] font-mono text-sm">{blockingUpdate.name}</span>
+            <p className="text-white/60 mb-4">
+              {blockingUpdate.downgrade
+                ? t('This will replace your current version with an older one.')
+                : t('A new version of HyVanila is available.')}
+              <span className="block mt-1 text-[#FFA845] font-mono text-sm">
+                {blockingUpdate.currentVersion} → {blockingUpdate.version}
+              </span>
+              {blockingUpdate.asset && !blockingUpdate.rollback && (
+                <span className="block mt-2 text-white/50 text-sm">
+                  {t("If this version fails to start, it won't be rolled back automatically.")}
+                </span>
+              )}
             </p>
 
-            <button
-              onClick={handleUpdate}
-              className="w-full py-4 bg-[#FFA845] hover:bg-[#FFB966] text-black font-bold rounded-xl transition-all active:scale-95"
-            >
-              {t('Update Now')}
-            </button>
+            {blockingUpdate.notes && (
+              <div className="w-full max-h-48 overflow-y-auto mb-8 p-4 bg-white/5 rounded-xl text-start text-white/70 text-sm whitespace-pre-wrap">
+                {blockingUpdate.notes}
+              </div>
+            )}
+
+            {blockingUpdate.asset ? (
+              <button
+                onClick={handleUpdate}
+                className="w-full py-4 bg-[#FFA845] hover:bg-[#FFB966] text-black font-bold rounded-xl transition-all active:scale-95"
+              >
+                {t('Update Now')}
+              </button>
+            ) : (
+              <p className="w-full p-4 bg-white/5 rounded-xl text-white/70 text-sm select-text">{blockingUpdate.instructions}</p>
+            )}
 
             <button
               onClick={() => setBlockingUpdate(null)}
@@ -495,7 +526,7 @@ const App: React.FC = () => {
             isEditing={isEditing}
             onEditToggle={setIsEditing}
             onUserChange={handleNickChange}
-            updateAvailable={!!updateAsset}
+            updateAvailable={!!updateAsset?.asset}
             onUpdate={handleUpdate}
             launcherVersion={launcherVersion}
           />
@@ -11,7 +11,9 @@ import {
     SetMusicEnabled,
     SetDiscordRPCEnabled,
     SelectJavaPath,
-    GetConfig
+    GetConfig,
+    GetLauncherRollback,
+    RevertLauncher
 } from '../../wailsjs/go/app/App';
 
 interface SettingsModalProps {
@@ -24,9 +26,13 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ onClose, onShowLog
     const [activeTab, setActiveTab] = useState<'general' | 'performance'>('general');
     const [config, setConfig] = useState<any>(null);
     const [loading, setLoading] = useState(true);
+    const [previousLauncher, setPreviousLauncher] = useState('');
 
     useEffect(() => {
         loadConfig();
+        GetLauncherRollback()
+            .then((info) => setPreviousLauncher(info.previousVersion))
+            .catch((err) => console.error('Failed to get previous launcher:', err));
     }, []);
 
     const loadConfig = async () => {
@@ -83,6 +89,14 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ onClose, onShowLog
         }
     };
 
+    const handleRevertLauncher = async () => {
+        try {
+            await RevertLauncher();
+        } catch (err) {
+            console.error('Failed to revert launcher:', err);
+        }
+    };
+
     const handleMusicToggle = async () => {
         const newVal = !config.musicEnabled;
         try {
@@ -246,6 +260,21 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ onClose, onShowLog
                                                 </button>
                                             </div>
 
+                                            {previousLauncher && (
+                                                <div className="p-4 rounded-xl bg-white/[0.03] border border-white/5 flex items-center justify-between">
+                                                    <div>
+                                                        <h4 className="text-sm font-medium text-white">{t('Previous Launcher Version')}</h4>
+                                                        <p className="text-xs text-white/40">{t('Go back to version')} {previousLauncher}</p>
+                                                    </div>
+                                                    <button
+                                                        onClick={handleRevertLauncher}
+                                                        className="px-4 py-2 bg-[#FFA845]/10 hover:bg-[#FFA845]/20 border border-[#FFA845]/20 rounded-lg text-sm text-[#FFA845] transition-colors"
+                                                    >
+                                                        {t('Revert')}
+                                                    </button>
+                                                </div>
+                                            )}
+
                                             <div className="p-4 rounded-xl bg-white/[0.03] border border-white/5 flex items-center justify-between">
                                                 <div>
                                                     <h4 className="text-sm font-medium text-white">{t('Discord Rich Presence')}</h4>
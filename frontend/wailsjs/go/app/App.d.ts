@@ -1,10 +1,29 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {config} from '../models';
 import {mods} from '../models';
 import {updater} from '../models';
 import {app} from '../models';
-import {config} from '../models';
+import {storage} from '../models';
+import {worlds} from '../models';
+import {backup} from '../models';
+import {patcher} from '../models';
+import {download} from '../models';
+import {migrate} from '../models';
 import {news} from '../models';
+import {relocate} from '../models';
+import {prefetch} from '../models';
+import {server} from '../models';
+import {gamestore} from '../models';
+import {tools} from '../models';
+import {java} from '../models';
+import {integrity} from '../models';
+
+export function AddSavedServer(arg1:config.SavedServer):Promise<config.SavedServer>;
+
+export function CancelRelocation():Promise<void>;
+
+export function CheckForGameUpdatesNow():Promise<void>;
 
 export function CheckInstanceModUpdates(arg1:string,arg2:number):Promise<Array<mods.Mod>>;
 
@@ -12,32 +31,62 @@ export function CheckLatestNeedsUpdate(arg1:string):Promise<boolean>;
 
 export function CheckModUpdates():Promise<Array<mods.Mod>>;
 
-export function CheckUpdate():Promise<updater.Asset>;
+export function CheckUpdate():Promise<updater.UpdateCheck>;
 
 export function CheckVersionAvailability():Promise<app.VersionCheckInfo>;
 
+export function CleanupStorage(arg1:storage.CleanupOptions):Promise<storage.CleanupResult>;
+
+export function CollectGameStoreGarbage():Promise<number>;
+
+export function CopyWorld(arg1:string,arg2:number,arg3:string,arg4:string,arg5:number,arg6:string):Promise<worlds.World>;
+
+export function CreateBackup(arg1:string,arg2:number,arg3:string):Promise<backup.Summary>;
+
+export function DeleteBackup(arg1:string):Promise<void>;
+
 export function DeleteGame():Promise<void>;
 
 export function DownloadAndLaunch(arg1:string):Promise<void>;
 
 export function DownloadVersion(arg1:string,arg2:string):Promise<void>;
 
+export function DryRunPatch(arg1:string,arg2:number):Promise<patcher.DryRunReport>;
+
 export function ExitGame():Promise<void>;
 
+export function ExportBackup(arg1:string):Promise<string>;
+
+export function ExportWorld(arg1:string,arg2:number,arg3:string):Promise<string>;
+
 export function GetAuthDomain():Promise<string>;
 
 export function GetAutoUpdateLatest():Promise<boolean>;
 
 export function GetAvailableVersions():Promise<Record<string, number>>;
 
+export function GetBackup(arg1:string):Promise<backup.Snapshot>;
+
+export function GetBackupPolicy():Promise<backup.Policy>;
+
+export function GetBackupStorageSize():Promise<number>;
+
+export function GetBandwidthLimits():Promise<download.Limits>;
+
+export function GetCachePolicy():Promise<storage.CachePolicy>;
+
 export function GetConfig():Promise<config.Config>;
 
+export function GetConfigProblem():Promise<app.AppError>;
+
 export function GetCrashReports():Promise<Array<app.CrashReport>>;
 
 export function GetCurrentVersion():Promise<string>;
 
 export function GetCustomInstanceDir():Promise<string>;
 
+export function GetDiskUsage():Promise<storage.Report>;
+
 export function GetGameLogs():Promise<string>;
 
 export function GetGamePath():Promise<string>;
@@ -50,10 +99,18 @@ export function GetInstalledVersionsForBranch(arg1:string):Promise<Array<number>
 
 export function GetInstanceInstalledMods(arg1:string,arg2:number):Promise<Array<mods.Mod>>;
 
+export function GetInstanceJava(arg1:string,arg2:number):Promise<string>;
+
+export function GetJavaRuntimes():Promise<app.JavaRuntimes>;
+
+export function GetLauncherRollback():Promise<updater.RollbackInfo>;
+
 export function GetLauncherVersion():Promise<string>;
 
 export function GetLogs():Promise<string>;
 
+export function GetMigrationReport():Promise<migrate.Report>;
+
 export function GetModCategories():Promise<Array<mods.ModCategory>>;
 
 export function GetModDetails(arg1:number):Promise<mods.CurseForgeMod>;
@@ -62,22 +119,54 @@ export function GetModFiles(arg1:number):Promise<Array<mods.ModFile>>;
 
 export function GetMusicEnabled():Promise<boolean>;
 
+export function GetNetworkSettings():Promise<download.NetworkSettings>;
+
 export function GetNews(arg1:number):Promise<Array<news.NewsItem>>;
 
 export function GetNick():Promise<string>;
 
 export function GetOnlineMode():Promise<boolean>;
 
+export function GetPendingRelocation():Promise<relocate.Job>;
+
 export function GetPlatformInfo():Promise<Record<string, string>>;
 
+export function GetPrefetchSettings():Promise<prefetch.Settings>;
+
+export function GetPrefetchStatus():Promise<Array<prefetch.BranchStatus>>;
+
+export function GetSavedServers():Promise<Array<config.SavedServer>>;
+
 export function GetSelectedVersion():Promise<number>;
 
+export function GetServerConsole(arg1:string,arg2:number):Promise<Array<server.ConsoleLine>>;
+
+export function GetServerStatus(arg1:string,arg2:number):Promise<server.Status>;
+
+export function GetServerStatuses():Promise<Array<server.Status>>;
+
+export function GetShareGameFiles():Promise<boolean>;
+
+export function GetStorageReport():Promise<gamestore.StorageReport>;
+
+export function GetTools():Promise<Record<string, tools.Tool>>;
+
+export function GetUpdateChannel():Promise<string>;
+
 export function GetVersionList(arg1:string):Promise<Array<number>>;
 
 export function GetVersionType():Promise<string>;
 
 export function GetVersions():Promise<string|string>;
 
+export function GetWorlds(arg1:string,arg2:number):Promise<Array<worlds.World>>;
+
+export function ImportWorld(arg1:string,arg2:number,arg3:string):Promise<worlds.World>;
+
+export function ImportWorldFile(arg1:string,arg2:string,arg3:number,arg4:string):Promise<worlds.World>;
+
+export function InstallJavaRuntime(arg1:number):Promise<java.Runtime>;
+
 export function InstallMod(arg1:number):Promise<void>;
 
 export function InstallModFile(arg1:number,arg2:number):Promise<void>;
@@ -92,6 +181,12 @@ export function IsGameRunning():Promise<boolean>;
 
 export function IsVersionInstalled(arg1:string,arg2:number):Promise<boolean>;
 
+export function KillServer(arg1:string,arg2:number):Promise<void>;
+
+export function LaunchToServer(arg1:string):Promise<void>;
+
+export function ListBackups(arg1:string,arg2:number):Promise<Array<backup.Summary>>;
+
 export function OpenFolder():Promise<void>;
 
 export function OpenGameFolder():Promise<void>;
@@ -100,10 +195,28 @@ export function OpenInstanceModsFolder(arg1:string,arg2:number):Promise<void>;
 
 export function OpenModsFolder():Promise<void>;
 
+export function PingAddress(arg1:string):Promise<server.PingResult>;
+
+export function PingSavedServer(arg1:string):Promise<server.PingResult>;
+
+export function ProbeJava(arg1:string):Promise<java.Runtime>;
+
 export function QuickLaunch():Promise<void>;
 
+export function RemoveJavaRuntime(arg1:string):Promise<void>;
+
+export function RemoveSavedServer(arg1:string):Promise<void>;
+
 export function RepairInstallation():Promise<void>;
 
+export function RepairInstance(arg1:string,arg2:number,arg3:boolean):Promise<integrity.Report>;
+
+export function RestoreBackup(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function ResumeRelocation():Promise<void>;
+
+export function RevertLauncher():Promise<void>;
+
 export function RunDiagnostics():Promise<app.DiagnosticReport>;
 
 export function SaveConfig():Promise<void>;
@@ -112,20 +225,32 @@ export function SaveDiagnosticReport():Promise<string>;
 
 export function SearchMods(arg1:string,arg2:number,arg3:number):Promise<mods.SearchResult>;
 
+export function SelectCABundle():Promise<string>;
+
 export function SelectInstanceDirectory():Promise<string>;
 
 export function SelectJavaPath():Promise<string>;
 
+export function SendServerCommand(arg1:string,arg2:number,arg3:string):Promise<void>;
+
 export function SetAuthDomain(arg1:string):Promise<void>;
 
 export function SetAutoUpdateLatest(arg1:boolean):Promise<void>;
 
+export function SetBackupPolicy(arg1:backup.Policy):Promise<void>;
+
+export function SetBandwidthLimits(arg1:download.Limits):Promise<void>;
+
+export function SetCachePolicy(arg1:storage.CachePolicy):Promise<void>;
+
 export function SetCustomInstanceDir(arg1:string):Promise<void>;
 
 export function SetDiscordRPCEnabled(arg1:boolean):Promise<void>;
 
 export function SetFullScreen(arg1:boolean):Promise<void>;
 
+export function SetInstanceJava(arg1:string,arg2:number,arg3:string):Promise<void>;
+
 export function SetJavaPath(arg1:string):Promise<void>;
 
 export function SetMaxMemory(arg1:number):Promise<void>;
@@ -134,14 +259,34 @@ export function SetMinMemory(arg1:number):Promise<void>;
 
 export function SetMusicEnabled(arg1:boolean):Promise<void>;
 
+export function SetNetworkSettings(arg1:download.NetworkSettings):Promise<void>;
+
 export function SetNick(arg1:string):Promise<void>;
 
 export function SetOnlineMode(arg1:boolean):Promise<void>;
 
+export function SetPrefetchSchedule(arg1:Array<string>,arg2:number,arg3:number):Promise<void>;
+
 export function SetSelectedVersion(arg1:number):Promise<void>;
 
+export function SetServerArgs(arg1:string):Promise<void>;
+
+export function SetServerMemory(arg1:number,arg2:number):Promise<void>;
+
+export function SetServerPort(arg1:number):Promise<void>;
+
+export function SetShareGameFiles(arg1:boolean):Promise<void>;
+
+export function SetUpdateChannel(arg1:string,arg2:boolean):Promise<updater.UpdateCheck>;
+
 export function SetVersionType(arg1:string):Promise<void>;
 
+export function StartServer(arg1:string,arg2:number):Promise<server.Status>;
+
+export function StartServerAndLaunch(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function StopServer(arg1:string,arg2:number):Promise<void>;
+
 export function SwitchVersion(arg1:number):Promise<void>;
 
 export function ToggleInstanceMod(arg1:string,arg2:boolean,arg3:string,arg4:number):Promise<void>;
@@ -153,3 +298,9 @@ export function UninstallInstanceMod(arg1:string,arg2:string,arg3:number):Promis
 export function UninstallMod(arg1:string):Promise<void>;
 
 export function Update():Promise<void>;
+
+export function UpdateSavedServer(arg1:config.SavedServer):Promise<void>;
+
+export function VerifyInstance(arg1:string,arg2:number):Promise<integrity.Report>;
+
+export function VerifyPatch(arg1:string,arg2:number):Promise<patcher.PatchVerification>;
@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddSavedServer(arg1) {
+  return window['go']['app']['App']['AddSavedServer'](arg1);
+}
+
+export function CancelRelocation() {
+  return window['go']['app']['App']['CancelRelocation']();
+}
+
+export function CheckForGameUpdatesNow() {
+  return window['go']['app']['App']['CheckForGameUpdatesNow']();
+}
+
 export function CheckInstanceModUpdates(arg1, arg2) {
   return window['go']['app']['App']['CheckInstanceModUpdates'](arg1, arg2);
 }
@@ -22,6 +34,26 @@ export function CheckVersionAvailability() {
   return window['go']['app']['App']['CheckVersionAvailability']();
 }
 
+export function CleanupStorage(arg1) {
+  return window['go']['app']['App']['CleanupStorage'](arg1);
+}
+
+export function CollectGameStoreGarbage() {
+  return window['go']['app']['App']['CollectGameStoreGarbage']();
+}
+
+export function CopyWorld(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['app']['App']['CopyWorld'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function CreateBackup(arg1, arg2, arg3) {
+  return window['go']['app']['App']['CreateBackup'](arg1, arg2, arg3);
+}
+
+export function DeleteBackup(arg1) {
+  return window['go']['app']['App']['DeleteBackup'](arg1);
+}
+
 export function DeleteGame() {
   return window['go']['app']['App']['DeleteGame']();
 }
@@ -34,10 +66,22 @@ export function DownloadVersion(arg1, arg2) {
   return window['go']['app']['App']['DownloadVersion'](arg1, arg2);
 }
 
+export function DryRunPatch(arg1, arg2) {
+  return window['go']['app']['App']['DryRunPatch'](arg1, arg2);
+}
+
 export function ExitGame() {
   return window['go']['app']['App']['ExitGame']();
 }
 
+export function ExportBackup(arg1) {
+  return window['go']['app']['App']['ExportBackup'](arg1);
+}
+
+export function ExportWorld(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ExportWorld'](arg1, arg2, arg3);
+}
+
 export function GetAuthDomain() {
   return window['go']['app']['App']['GetAuthDomain']();
 }
@@ -50,10 +94,34 @@ export function GetAvailableVersions() {
   return window['go']['app']['App']['GetAvailableVersions']();
 }
 
+export function GetBackup(arg1) {
+  return window['go']['app']['App']['GetBackup'](arg1);
+}
+
+export function GetBackupPolicy() {
+  return window['go']['app']['App']['GetBackupPolicy']();
+}
+
+export function GetBackupStorageSize() {
+  return window['go']['app']['App']['GetBackupStorageSize']();
+}
+
+export function GetBandwidthLimits() {
+  return window['go']['app']['App']['GetBandwidthLimits']();
+}
+
+export function GetCachePolicy() {
+  return window['go']['app']['App']['GetCachePolicy']();
+}
+
 export function GetConfig() {
   return window['go']['app']['App']['GetConfig']();
 }
 
+export function GetConfigProblem() {
+  return window['go']['app']['App']['GetConfigProblem']();
+}
+
 export function GetCrashReports() {
   return window['go']['app']['App']['GetCrashReports']();
 }
@@ -66,6 +134,10 @@ export function GetCustomInstanceDir() {
   return window['go']['app']['App']['GetCustomInstanceDir']();
 }
 
+export function GetDiskUsage() {
+  return window['go']['app']['App']['GetDiskUsage']();
+}
+
 export function GetGameLogs() {
   return window['go']['app']['App']['GetGameLogs']();
 }
@@ -90,6 +162,18 @@ export function GetInstanceInstalledMods(arg1, arg2) {
   return window['go']['app']['App']['GetInstanceInstalledMods'](arg1, arg2);
 }
 
+export function GetInstanceJava(arg1, arg2) {
+  return window['go']['app']['App']['GetInstanceJava'](arg1, arg2);
+}
+
+export function GetJavaRuntimes() {
+  return window['go']['app']['App']['GetJavaRuntimes']();
+}
+
+export function GetLauncherRollback() {
+  return window['go']['app']['App']['GetLauncherRollback']();
+}
+
 export function GetLauncherVersion() {
   return window['go']['app']['App']['GetLauncherVersion']();
 }
@@ -98,6 +182,10 @@ export function GetLogs() {
   return window['go']['app']['App']['GetLogs']();
 }
 
+export function GetMigrationReport() {
+  return window['go']['app']['App']['GetMigrationReport']();
+}
+
 export function GetModCategories() {
   return window['go']['app']['App']['GetModCategories']();
 }
@@ -114,6 +202,10 @@ export function GetMusicEnabled() {
   return window['go']['app']['App']['GetMusicEnabled']();
 }
 
+export function GetNetworkSettings() {
+  return window['go']['app']['App']['GetNetworkSettings']();
+}
+
 export function GetNews(arg1) {
   return window['go']['app']['App']['GetNews'](arg1);
 }
@@ -126,14 +218,58 @@ export function GetOnlineMode() {
   return window['go']['app']['App']['GetOnlineMode']();
 }
 
+export function GetPendingRelocation() {
+  return window['go']['app']['App']['GetPendingRelocation']();
+}
+
 export function GetPlatformInfo() {
   return window['go']['app']['App']['GetPlatformInfo']();
 }
 
+export function GetPrefetchSettings() {
+  return window['go']['app']['App']['GetPrefetchSettings']();
+}
+
+export function GetPrefetchStatus() {
+  return window['go']['app']['App']['GetPrefetchStatus']();
+}
+
+export function GetSavedServers() {
+  return window['go']['app']['App']['GetSavedServers']();
+}
+
 export function GetSelectedVersion() {
   return window['go']['app']['App']['GetSelectedVersion']();
 }
 
+export function GetServerConsole(arg1, arg2) {
+  return window['go']['app']['App']['GetServerConsole'](arg1, arg2);
+}
+
+export function GetServerStatus(arg1, arg2) {
+  return window['go']['app']['App']['GetServerStatus'](arg1, arg2);
+}
+
+export function GetServerStatuses() {
+  return window['go']['app']['App']['GetServerStatuses']();
+}
+
+export function GetShareGameFiles() {
+  return window['go']['app']['App']['GetShareGameFiles']();
+}
+
+export function GetStorageReport() {
+  return window['go']['app']['App']['GetStorageReport']();
+}
+
+export function GetTools() {
+  return window['go']['app']['App']['GetTools']();
+}
+
+export function GetUpdateChannel() {
+  return window['go']['app']['App']['GetUpdateChannel']();
+}
+
 export function GetVersionList(arg1) {
   return window['go']['app']['App']['GetVersionList'](arg1);
 }
@@ -146,6 +282,22 @@ export function GetVersions() {
   return window['go']['app']['App']['GetVersions']();
 }
 
+export function GetWorlds(arg1, arg2) {
+  return window['go']['app']['App']['GetWorlds'](arg1, arg2);
+}
+
+export function ImportWorld(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ImportWorld'](arg1, arg2, arg3);
+}
+
+export function ImportWorldFile(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['ImportWorldFile'](arg1, arg2, arg3, arg4);
+}
+
+export function InstallJavaRuntime(arg1) {
+  return window['go']['app']['App']['InstallJavaRuntime'](arg1);
+}
+
 export function InstallMod(arg1) {
   return window['go']['app']['App']['InstallMod'](arg1);
 }
@@ -174,6 +326,18 @@ export function IsVersionInstalled(arg1, arg2) {
   return window['go']['app']['App']['IsVersionInstalled'](arg1, arg2);
 }
 
+export function KillServer(arg1, arg2) {
+  return window['go']['app']['App']['KillServer'](arg1, arg2);
+}
+
+export function LaunchToServer(arg1) {
+  return window['go']['app']['App']['LaunchToServer'](arg1);
+}
+
+export function ListBackups(arg1, arg2) {
+  return window['go']['app']['App']['ListBackups'](arg1, arg2);
+}
+
 export function OpenFolder() {
   return window['go']['app']['App']['OpenFolder']();
 }
@@ -190,14 +354,50 @@ export function OpenModsFolder() {
   return window['go']['app']['App']['OpenModsFolder']();
 }
 
+export function PingAddress(arg1) {
+  return window['go']['app']['App']['PingAddress'](arg1);
+}
+
+export function PingSavedServer(arg1) {
+  return window['go']['app']['App']['PingSavedServer'](arg1);
+}
+
+export function ProbeJava(arg1) {
+  return window['go']['app']['App']['ProbeJava'](arg1);
+}
+
 export function QuickLaunch() {
   return window['go']['app']['App']['QuickLaunch']();
 }
 
+export function RemoveJavaRuntime(arg1) {
+  return window['go']['app']['App']['RemoveJavaRuntime'](arg1);
+}
+
+export function RemoveSavedServer(arg1) {
+  return window['go']['app']['App']['RemoveSavedServer'](arg1);
+}
+
 export function RepairInstallation() {
   return window['go']['app']['App']['RepairInstallation']();
 }
 
+export function RepairInstance(arg1, arg2, arg3) {
+  return window['go']['app']['App']['RepairInstance'](arg1, arg2, arg3);
+}
+
+export function RestoreBackup(arg1, arg2, arg3) {
+  return window['go']['app']['App']['RestoreBackup'](arg1, arg2, arg3);
+}
+
+export function ResumeRelocation() {
+  return window['go']['app']['App']['ResumeRelocation']();
+}
+
+export function RevertLauncher() {
+  return window['go']['app']['App']['RevertLauncher']();
+}
+
 export function RunDiagnostics() {
   return window['go']['app']['App']['RunDiagnostics']();
 }
@@ -214,6 +414,10 @@ export function SearchMods(arg1, arg2, arg3) {
   return window['go']['app']['App']['SearchMods'](arg1, arg2, arg3);
 }
 
+export function SelectCABundle() {
+  return window['go']['app']['App']['SelectCABundle']();
+}
+
 export function SelectInstanceDirectory() {
   return window['go']['app']['App']['SelectInstanceDirectory']();
 }
@@ -222,6 +426,10 @@ export function SelectJavaPath() {
   return window['go']['app']['App']['SelectJavaPath']();
 }
 
+export function SendServerCommand(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SendServerCommand'](arg1, arg2, arg3);
+}
+
 export function SetAuthDomain(arg1) {
   return window['go']['app']['App']['SetAuthDomain'](arg1);
 }
@@ -230,6 +438,18 @@ export function SetAutoUpdateLatest(arg1) {
   return window['go']['app']['App']['SetAutoUpdateLatest'](arg1);
 }
 
+export function SetBackupPolicy(arg1) {
+  return window['go']['app']['App']['SetBackupPolicy'](arg1);
+}
+
+export function SetBandwidthLimits(arg1) {
+  return window['go']['app']['App']['SetBandwidthLimits'](arg1);
+}
+
+export function SetCachePolicy(arg1) {
+  return window['go']['app']['App']['SetCachePolicy'](arg1);
+}
+
 export function SetCustomInstanceDir(arg1) {
   return window['go']['app']['App']['SetCustomInstanceDir'](arg1);
 }
@@ -242,6 +462,10 @@ export function SetFullScreen(arg1) {
   return window['go']['app']['App']['SetFullScreen'](arg1);
 }
 
+export function SetInstanceJava(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SetInstanceJava'](arg1, arg2, arg3);
+}
+
 export function SetJavaPath(arg1) {
   return window['go']['app']['App']['SetJavaPath'](arg1);
 }
@@ -258,6 +482,10 @@ export function SetMusicEnabled(arg1) {
   return window['go']['app']['App']['SetMusicEnabled'](arg1);
 }
 
+export function SetNetworkSettings(arg1) {
+  return window['go']['app']['App']['SetNetworkSettings'](arg1);
+}
+
 export function SetNick(arg1) {
   return window['go']['app']['App']['SetNick'](arg1);
 }
@@ -266,14 +494,50 @@ export function SetOnlineMode(arg1) {
   return window['go']['app']['App']['SetOnlineMode'](arg1);
 }
 
+export function SetPrefetchSchedule(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SetPrefetchSchedule'](arg1, arg2, arg3);
+}
+
 export function SetSelectedVersion(arg1) {
   return window['go']['app']['App']['SetSelectedVersion'](arg1);
 }
 
+export function SetServerArgs(arg1) {
+  return window['go']['app']['App']['SetServerArgs'](arg1);
+}
+
+export function SetServerMemory(arg1, arg2) {
+  return window['go']['app']['App']['SetServerMemory'](arg1, arg2);
+}
+
+export function SetServerPort(arg1) {
+  return window['go']['app']['App']['SetServerPort'](arg1);
+}
+
+export function SetShareGameFiles(arg1) {
+  return window['go']['app']['App']['SetShareGameFiles'](arg1);
+}
+
+export function SetUpdateChannel(arg1, arg2) {
+  return window['go']['app']['App']['SetUpdateChannel'](arg1, arg2);
+}
+
 export function SetVersionType(arg1) {
   return window['go']['app']['App']['SetVersionType'](arg1);
 }
 
+export function StartServer(arg1, arg2) {
+  return window['go']['app']['App']['StartServer'](arg1, arg2);
+}
+
+export function StartServerAndLaunch(arg1, arg2, arg3) {
+  return window['go']['app']['App']['StartServerAndLaunch'](arg1, arg2, arg3);
+}
+
+export function StopServer(arg1, arg2) {
+  return window['go']['app']['App']['StopServer'](arg1, arg2);
+}
+
 export function SwitchVersion(arg1) {
   return window['go']['app']['App']['SwitchVersion'](arg1);
 }
@@ -297,3 +561,15 @@ export function UninstallMod(arg1) {
 export function Update() {
   return window['go']['app']['App']['Update']();
 }
+
+export function UpdateSavedServer(arg1) {
+  return window['go']['app']['App']['UpdateSavedServer'](arg1);
+}
+
+export function VerifyInstance(arg1, arg2) {
+  return window['go']['app']['App']['VerifyInstance'](arg1, arg2);
+}
+
+export function VerifyPatch(arg1, arg2) {
+  return window['go']['app']['App']['VerifyPatch'](arg1, arg2);
+}
@@ -1,5 +1,23 @@
 export namespace app {
 	
+	export class AppError {
+	    type: string;
+	    message: string;
+	    technical?: string;
+	    timestamp: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.message = source["message"];
+	        this.technical = source["technical"];
+	        this.timestamp = source["timestamp"];
+	    }
+	}
 	export class ConnectivityInfo {
 	    hytalePatches: boolean;
 	    github: boolean;
@@ -37,8 +55,10 @@ export namespace app {
 	export class DependenciesInfo {
 	    javaInstalled: boolean;
 	    javaPath: string;
+	    javaVersion: string;
 	    butlerInstalled: boolean;
 	    butlerPath: string;
+	    butlerVersion: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new DependenciesInfo(source);
@@ -48,8 +68,10 @@ export namespace app {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.javaInstalled = source["javaInstalled"];
 	        this.javaPath = source["javaPath"];
+	        this.javaVersion = source["javaVersion"];
 	        this.butlerInstalled = source["butlerInstalled"];
 	        this.butlerPath = source["butlerPath"];
+	        this.butlerVersion = source["butlerVersion"];
 	    }
 	}
 	export class GameStatusInfo {
@@ -141,6 +163,40 @@ export namespace app {
 	        this.installDate = source["installDate"];
 	    }
 	}
+	export class JavaRuntimes {
+	    managed: java.Runtime[];
+	    system: java.Runtime[];
+	    custom?: java.Runtime;
+	
+	    static createFrom(source: any = {}) {
+	        return new JavaRuntimes(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.managed = this.convertValues(source["managed"], java.Runtime);
+	        this.system = this.convertValues(source["system"], java.Runtime);
+	        this.custom = this.convertValues(source["custom"], java.Runtime);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	
 	export class VersionCheckInfo {
 	    available: boolean;
@@ -159,8 +215,173 @@ export namespace app {
 
 }
 
+export namespace backup {
+	
+	export class File {
+	    path: string;
+	    hash: string;
+	    size: number;
+	    mode: number;
+	    modTime: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new File(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.hash = source["hash"];
+	        this.size = source["size"];
+	        this.mode = source["mode"];
+	        this.modTime = source["modTime"];
+	    }
+	}
+	export class Policy {
+	    onUpdate: boolean;
+	    onSessionExit: boolean;
+	    keepLast: number;
+	    keepDaily: number;
+	    maxSizeMB: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Policy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.onUpdate = source["onUpdate"];
+	        this.onSessionExit = source["onSessionExit"];
+	        this.keepLast = source["keepLast"];
+	        this.keepDaily = source["keepDaily"];
+	        this.maxSizeMB = source["maxSizeMB"];
+	    }
+	}
+	export class Snapshot {
+	    id: string;
+	    branch: string;
+	    version: number;
+	    reason: string;
+	    note?: string;
+	    createdAt: string;
+	    roots: string[];
+	    files: File[];
+	    totalSize: number;
+	    addedSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.reason = source["reason"];
+	        this.note = source["note"];
+	        this.createdAt = source["createdAt"];
+	        this.roots = source["roots"];
+	        this.files = this.convertValues(source["files"], File);
+	        this.totalSize = source["totalSize"];
+	        this.addedSize = source["addedSize"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Summary {
+	    id: string;
+	    branch: string;
+	    version: number;
+	    reason: string;
+	    note?: string;
+	    createdAt: string;
+	    fileCount: number;
+	    totalSize: number;
+	    addedSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Summary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.reason = source["reason"];
+	        this.note = source["note"];
+	        this.createdAt = source["createdAt"];
+	        this.fileCount = source["fileCount"];
+	        this.totalSize = source["totalSize"];
+	        this.addedSize = source["addedSize"];
+	    }
+	}
+
+}
+
 export namespace config {
 	
+	export class BandwidthRule {
+	    window: string;
+	    foregroundKBps: number;
+	    backgroundKBps: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BandwidthRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.window = source["window"];
+	        this.foregroundKBps = source["foregroundKBps"];
+	        this.backgroundKBps = source["backgroundKBps"];
+	    }
+	}
+	export class SavedServer {
+	    id: string;
+	    name: string;
+	    address: string;
+	    authDomain: string;
+	    branch: string;
+	    version: number;
+	    nick: string;
+	    addedAt: string;
+	    lastPlayed: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedServer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.address = source["address"];
+	        this.authDomain = source["authDomain"];
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.nick = source["nick"];
+	        this.addedAt = source["addedAt"];
+	        this.lastPlayed = source["lastPlayed"];
+	    }
+	}
 	export class Config {
 	    version: string;
 	    nick: string;
@@ -176,6 +397,33 @@ export namespace config {
 	    maxMemory: number;
 	    minMemory: number;
 	    fullScreen: boolean;
+	    serverMaxMemory: number;
+	    serverMinMemory: number;
+	    serverPort: number;
+	    serverArgs: string;
+	    servers: SavedServer[];
+	    backupOnUpdate: boolean;
+	    backupOnExit: boolean;
+	    backupKeepLast: number;
+	    backupKeepDaily: number;
+	    backupMaxSizeMB: number;
+	    shareGameFiles: boolean;
+	    cacheMaxSizeMB: number;
+	    cacheKeepPatches: number;
+	    prefetchInterval: number;
+	    prefetchWindows: string[];
+	    prefetchMaxKBps: number;
+	    foregroundKBps: number;
+	    backgroundKBps: number;
+	    bandwidthRules: BandwidthRule[];
+	    proxyMode: string;
+	    proxyURL: string;
+	    proxyUsername: string;
+	    proxyPassword: string;
+	    noProxy: string[];
+	    caBundles: string[];
+	    updateChannel: string;
+	    instanceRuntimes: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -197,140 +445,476 @@ export namespace config {
 	        this.maxMemory = source["maxMemory"];
 	        this.minMemory = source["minMemory"];
 	        this.fullScreen = source["fullScreen"];
+	        this.serverMaxMemory = source["serverMaxMemory"];
+	        this.serverMinMemory = source["serverMinMemory"];
+	        this.serverPort = source["serverPort"];
+	        this.serverArgs = source["serverArgs"];
+	        this.servers = this.convertValues(source["servers"], SavedServer);
+	        this.backupOnUpdate = source["backupOnUpdate"];
+	        this.backupOnExit = source["backupOnExit"];
+	        this.backupKeepLast = source["backupKeepLast"];
+	        this.backupKeepDaily = source["backupKeepDaily"];
+	        this.backupMaxSizeMB = source["backupMaxSizeMB"];
+	        this.shareGameFiles = source["shareGameFiles"];
+	        this.cacheMaxSizeMB = source["cacheMaxSizeMB"];
+	        this.cacheKeepPatches = source["cacheKeepPatches"];
+	        this.prefetchInterval = source["prefetchInterval"];
+	        this.prefetchWindows = source["prefetchWindows"];
+	        this.prefetchMaxKBps = source["prefetchMaxKBps"];
+	        this.foregroundKBps = source["foregroundKBps"];
+	        this.backgroundKBps = source["backgroundKBps"];
+	        this.bandwidthRules = this.convertValues(source["bandwidthRules"], BandwidthRule);
+	        this.proxyMode = source["proxyMode"];
+	        this.proxyURL = source["proxyURL"];
+	        this.proxyUsername = source["proxyUsername"];
+	        this.proxyPassword = source["proxyPassword"];
+	        this.noProxy = source["noProxy"];
+	        this.caBundles = source["caBundles"];
+	        this.updateChannel = source["updateChannel"];
+	        this.instanceRuntimes = source["instanceRuntimes"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
 
-export namespace mods {
+export namespace download {
 	
-	export class ModFile {
-	    id: number;
-	    modId: number;
-	    displayName: string;
-	    fileName: string;
-	    fileLength: number;
-	    downloadUrl: string;
-	    fileDate: string;
-	    releaseType: number;
+	export class LimitRule {
+	    window: string;
+	    foregroundKBps: number;
+	    backgroundKBps: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ModFile(source);
+	        return new LimitRule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.modId = source["modId"];
-	        this.displayName = source["displayName"];
-	        this.fileName = source["fileName"];
-	        this.fileLength = source["fileLength"];
-	        this.downloadUrl = source["downloadUrl"];
-	        this.fileDate = source["fileDate"];
-	        this.releaseType = source["releaseType"];
+	        this.window = source["window"];
+	        this.foregroundKBps = source["foregroundKBps"];
+	        this.backgroundKBps = source["backgroundKBps"];
 	    }
 	}
-	export class ModAuthor {
-	    id: number;
-	    name: string;
-	    url: string;
+	export class Limits {
+	    foregroundKBps: number;
+	    backgroundKBps: number;
+	    rules: LimitRule[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ModAuthor(source);
+	        return new Limits(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.url = source["url"];
+	        this.foregroundKBps = source["foregroundKBps"];
+	        this.backgroundKBps = source["backgroundKBps"];
+	        this.rules = this.convertValues(source["rules"], LimitRule);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ModCategory {
-	    id: number;
-	    name: string;
-	    slug: string;
-	    url: string;
-	    iconUrl: string;
+	export class NetworkSettings {
+	    proxyMode: string;
+	    proxyURL: string;
+	    proxyUsername: string;
+	    proxyPassword: string;
+	    noProxy: string[];
+	    caBundles: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ModCategory(source);
+	        return new NetworkSettings(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.name = source["name"];
-	        this.slug = source["slug"];
-	        this.url = source["url"];
-	        this.iconUrl = source["iconUrl"];
+	        this.proxyMode = source["proxyMode"];
+	        this.proxyURL = source["proxyURL"];
+	        this.proxyUsername = source["proxyUsername"];
+	        this.proxyPassword = source["proxyPassword"];
+	        this.noProxy = source["noProxy"];
+	        this.caBundles = source["caBundles"];
 	    }
 	}
-	export class ModScreenshot {
-	    id: number;
-	    modId: number;
-	    title: string;
-	    description: string;
-	    thumbnailUrl: string;
-	    url: string;
+
+}
+
+export namespace gamestore {
+	
+	export class InstanceUsage {
+	    name: string;
+	    apparentSize: number;
+	    sharedSize: number;
+	    exclusiveSize: number;
+	    files: number;
+	    sharedFiles: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ModScreenshot(source);
+	        return new InstanceUsage(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.modId = source["modId"];
-	        this.title = source["title"];
-	        this.description = source["description"];
-	        this.thumbnailUrl = source["thumbnailUrl"];
-	        this.url = source["url"];
+	        this.name = source["name"];
+	        this.apparentSize = source["apparentSize"];
+	        this.sharedSize = source["sharedSize"];
+	        this.exclusiveSize = source["exclusiveSize"];
+	        this.files = source["files"];
+	        this.sharedFiles = source["sharedFiles"];
 	    }
 	}
-	export class ModLogo {
-	    id: number;
-	    modId: number;
-	    title: string;
-	    description: string;
-	    thumbnailUrl: string;
-	    url: string;
+	export class StorageReport {
+	    enabled: boolean;
+	    instances: InstanceUsage[];
+	    storeSize: number;
+	    storeObjects: number;
+	    unreferencedSize: number;
+	    apparentTotal: number;
+	    actualTotal: number;
+	    savedBytes: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new ModLogo(source);
+	        return new StorageReport(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.id = source["id"];
-	        this.modId = source["modId"];
-	        this.title = source["title"];
-	        this.description = source["description"];
-	        this.thumbnailUrl = source["thumbnailUrl"];
-	        this.url = source["url"];
+	        this.enabled = source["enabled"];
+	        this.instances = this.convertValues(source["instances"], InstanceUsage);
+	        this.storeSize = source["storeSize"];
+	        this.storeObjects = source["storeObjects"];
+	        this.unreferencedSize = source["unreferencedSize"];
+	        this.apparentTotal = source["apparentTotal"];
+	        this.actualTotal = source["actualTotal"];
+	        this.savedBytes = source["savedBytes"];
 	    }
-	}
-	export class CurseForgeMod {
-	    id: number;
-	    gameId: number;
-	    name: string;
-	    slug: string;
-	    summary: string;
-	    downloadCount: number;
-	    dateCreated: string;
-	    dateModified: string;
-	    dateReleased: string;
-	    logo?: ModLogo;
-	    screenshots: ModScreenshot[];
-	    categories: ModCategory[];
-	    authors: ModAuthor[];
-	    latestFiles: ModFile[];
-	    mainFileId: number;
-	    allowModDistribution: boolean;
 	
-	    static createFrom(source: any = {}) {
-	        return new CurseForgeMod(source);
-	    }
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace integrity {
+	
+	export class Issue {
+	    path: string;
+	    kind: string;
+	    expectedSha256?: string;
+	    currentSha256?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Issue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.kind = source["kind"];
+	        this.expectedSha256 = source["expectedSha256"];
+	        this.currentSha256 = source["currentSha256"];
+	    }
+	}
+	export class Report {
+	    branch: string;
+	    version: number;
+	    build: number;
+	    status: string;
+	    checkedFiles: number;
+	    missing: Issue[];
+	    modified: Issue[];
+	    extra: Issue[];
+	    patched?: string[];
+	    repaired?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.build = source["build"];
+	        this.status = source["status"];
+	        this.checkedFiles = source["checkedFiles"];
+	        this.missing = this.convertValues(source["missing"], Issue);
+	        this.modified = this.convertValues(source["modified"], Issue);
+	        this.extra = this.convertValues(source["extra"], Issue);
+	        this.patched = source["patched"];
+	        this.repaired = source["repaired"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace java {
+	
+	export class Runtime {
+	    id: string;
+	    path: string;
+	    home: string;
+	    version: string;
+	    major: number;
+	    vendor: string;
+	    arch: string;
+	    source: string;
+	    problem?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Runtime(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.path = source["path"];
+	        this.home = source["home"];
+	        this.version = source["version"];
+	        this.major = source["major"];
+	        this.vendor = source["vendor"];
+	        this.arch = source["arch"];
+	        this.source = source["source"];
+	        this.problem = source["problem"];
+	    }
+	}
+
+}
+
+export namespace migrate {
+	
+	export class Report {
+	    id: string;
+	    startedAt: string;
+	    completedAt: string;
+	    targetBranch: string;
+	    targetVersion: number;
+	    movedFiles: number;
+	    duplicates: number;
+	    conflicts?: string[];
+	    mergedMods: number;
+	    version?: string;
+	    errors?: string[];
+	    skipped: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.startedAt = source["startedAt"];
+	        this.completedAt = source["completedAt"];
+	        this.targetBranch = source["targetBranch"];
+	        this.targetVersion = source["targetVersion"];
+	        this.movedFiles = source["movedFiles"];
+	        this.duplicates = source["duplicates"];
+	        this.conflicts = source["conflicts"];
+	        this.mergedMods = source["mergedMods"];
+	        this.version = source["version"];
+	        this.errors = source["errors"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+
+}
+
+export namespace mods {
+	
+	export class ModFile {
+	    id: number;
+	    modId: number;
+	    displayName: string;
+	    fileName: string;
+	    fileLength: number;
+	    downloadUrl: string;
+	    fileDate: string;
+	    releaseType: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.modId = source["modId"];
+	        this.displayName = source["displayName"];
+	        this.fileName = source["fileName"];
+	        this.fileLength = source["fileLength"];
+	        this.downloadUrl = source["downloadUrl"];
+	        this.fileDate = source["fileDate"];
+	        this.releaseType = source["releaseType"];
+	    }
+	}
+	export class ModAuthor {
+	    id: number;
+	    name: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModAuthor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.url = source["url"];
+	    }
+	}
+	export class ModCategory {
+	    id: number;
+	    name: string;
+	    slug: string;
+	    url: string;
+	    iconUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModCategory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.slug = source["slug"];
+	        this.url = source["url"];
+	        this.iconUrl = source["iconUrl"];
+	    }
+	}
+	export class ModScreenshot {
+	    id: number;
+	    modId: number;
+	    title: string;
+	    description: string;
+	    thumbnailUrl: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModScreenshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.modId = source["modId"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.thumbnailUrl = source["thumbnailUrl"];
+	        this.url = source["url"];
+	    }
+	}
+	export class ModLogo {
+	    id: number;
+	    modId: number;
+	    title: string;
+	    description: string;
+	    thumbnailUrl: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModLogo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.modId = source["modId"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.thumbnailUrl = source["thumbnailUrl"];
+	        this.url = source["url"];
+	    }
+	}
+	export class CurseForgeMod {
+	    id: number;
+	    gameId: number;
+	    name: string;
+	    slug: string;
+	    summary: string;
+	    downloadCount: number;
+	    dateCreated: string;
+	    dateModified: string;
+	    dateReleased: string;
+	    logo?: ModLogo;
+	    screenshots: ModScreenshot[];
+	    categories: ModCategory[];
+	    authors: ModAuthor[];
+	    latestFiles: ModFile[];
+	    mainFileId: number;
+	    allowModDistribution: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CurseForgeMod(source);
+	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
@@ -525,20 +1109,565 @@ export namespace news {
 
 }
 
-export namespace updater {
+export namespace patcher {
 	
-	export class Asset {
-	    url: string;
-	    sha256: string;
+	export class PatchMatch {
+	    ruleId: string;
+	    entry?: string;
+	    offset: number;
+	    before: string;
+	    after: string;
+	    context: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Asset(source);
+	        return new PatchMatch(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.url = source["url"];
-	        this.sha256 = source["sha256"];
+	        this.ruleId = source["ruleId"];
+	        this.entry = source["entry"];
+	        this.offset = source["offset"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.context = source["context"];
+	    }
+	}
+	export class DryRunFile {
+	    path: string;
+	    target: string;
+	    alreadyPatched: boolean;
+	    matchCount: number;
+	    matches: PatchMatch[];
+	    errors?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DryRunFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.target = source["target"];
+	        this.alreadyPatched = source["alreadyPatched"];
+	        this.matchCount = source["matchCount"];
+	        this.matches = this.convertValues(source["matches"], PatchMatch);
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DryRunReport {
+	    targetDomain: string;
+	    ruleSetVersion: number;
+	    ruleSetSource: string;
+	    totalMatches: number;
+	    files: DryRunFile[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DryRunReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.targetDomain = source["targetDomain"];
+	        this.ruleSetVersion = source["ruleSetVersion"];
+	        this.ruleSetSource = source["ruleSetSource"];
+	        this.totalMatches = source["totalMatches"];
+	        this.files = this.convertValues(source["files"], DryRunFile);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FileVerification {
+	    path: string;
+	    status: string;
+	    currentSha256: string;
+	    expectedSha256: string;
+	    backupStatus: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileVerification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.currentSha256 = source["currentSha256"];
+	        this.expectedSha256 = source["expectedSha256"];
+	        this.backupStatus = source["backupStatus"];
+	    }
+	}
+	
+	export class PatchVerification {
+	    status: string;
+	    patched: boolean;
+	    targetDomain: string;
+	    files: FileVerification[];
+	    issues?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PatchVerification(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.patched = source["patched"];
+	        this.targetDomain = source["targetDomain"];
+	        this.files = this.convertValues(source["files"], FileVerification);
+	        this.issues = source["issues"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace prefetch {
+	
+	export class BranchStatus {
+	    branch: string;
+	    installedBuild: number;
+	    latestBuild: number;
+	    state: string;
+	    downloaded: number;
+	    total: number;
+	    error?: string;
+	    lastChecked?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BranchStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branch = source["branch"];
+	        this.installedBuild = source["installedBuild"];
+	        this.latestBuild = source["latestBuild"];
+	        this.state = source["state"];
+	        this.downloaded = source["downloaded"];
+	        this.total = source["total"];
+	        this.error = source["error"];
+	        this.lastChecked = source["lastChecked"];
+	    }
+	}
+	export class Settings {
+	    enabled: boolean;
+	    intervalMinutes: number;
+	    windows: string[];
+	    maxKBps: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.intervalMinutes = source["intervalMinutes"];
+	        this.windows = source["windows"];
+	        this.maxKBps = source["maxKBps"];
+	    }
+	}
+
+}
+
+export namespace relocate {
+	
+	export class Job {
+	    source: string;
+	    dest: string;
+	    method: string;
+	    startedAt: string;
+	    instances: string[];
+	    moved: string[];
+	    switched: boolean;
+	    totalSize: number;
+	    sharedSize: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.dest = source["dest"];
+	        this.method = source["method"];
+	        this.startedAt = source["startedAt"];
+	        this.instances = source["instances"];
+	        this.moved = source["moved"];
+	        this.switched = source["switched"];
+	        this.totalSize = source["totalSize"];
+	        this.sharedSize = source["sharedSize"];
+	    }
+	}
+
+}
+
+export namespace server {
+	
+	export class ConsoleLine {
+	    branch: string;
+	    version: number;
+	    stream: string;
+	    text: string;
+	    time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConsoleLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.stream = source["stream"];
+	        this.text = source["text"];
+	        this.time = source["time"];
+	    }
+	}
+	export class PingResult {
+	    address: string;
+	    status: string;
+	    reachable: boolean;
+	    protocol?: string;
+	    latencyMs: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PingResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.address = source["address"];
+	        this.status = source["status"];
+	        this.reachable = source["reachable"];
+	        this.protocol = source["protocol"];
+	        this.latencyMs = source["latencyMs"];
+	        this.error = source["error"];
+	    }
+	}
+	export class Status {
+	    branch: string;
+	    version: number;
+	    running: boolean;
+	    ready: boolean;
+	    pid: number;
+	    port: number;
+	    address: string;
+	    dataDir: string;
+	    startedAt: string;
+	    exitCode: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.running = source["running"];
+	        this.ready = source["ready"];
+	        this.pid = source["pid"];
+	        this.port = source["port"];
+	        this.address = source["address"];
+	        this.dataDir = source["dataDir"];
+	        this.startedAt = source["startedAt"];
+	        this.exitCode = source["exitCode"];
+	        this.error = source["error"];
+	    }
+	}
+
+}
+
+export namespace storage {
+	
+	export class CachePolicy {
+	    maxSizeMB: number;
+	    keepPatches: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CachePolicy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxSizeMB = source["maxSizeMB"];
+	        this.keepPatches = source["keepPatches"];
+	    }
+	}
+	export class CacheUsage {
+	    patchFiles: number;
+	    patchSize: number;
+	    archives: number;
+	    tempFiles: number;
+	    other: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CacheUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.patchFiles = source["patchFiles"];
+	        this.patchSize = source["patchSize"];
+	        this.archives = source["archives"];
+	        this.tempFiles = source["tempFiles"];
+	        this.other = source["other"];
+	    }
+	}
+	export class Category {
+	    name: string;
+	    path: string;
+	    size: number;
+	    files: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.files = source["files"];
+	    }
+	}
+	export class CleanupOptions {
+	    cache: boolean;
+	    allPatches: boolean;
+	    tempFiles: boolean;
+	    logsOlderThanDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cache = source["cache"];
+	        this.allPatches = source["allPatches"];
+	        this.tempFiles = source["tempFiles"];
+	        this.logsOlderThanDays = source["logsOlderThanDays"];
+	    }
+	}
+	export class CleanupResult {
+	    freedBytes: number;
+	    removedFiles: number;
+	    skipped?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new CleanupResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.freedBytes = source["freedBytes"];
+	        this.removedFiles = source["removedFiles"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class Report {
+	    categories: Category[];
+	    instances: gamestore.InstanceUsage[];
+	    total: number;
+	    cache: CacheUsage;
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.categories = this.convertValues(source["categories"], Category);
+	        this.instances = this.convertValues(source["instances"], gamestore.InstanceUsage);
+	        this.total = source["total"];
+	        this.cache = this.convertValues(source["cache"], CacheUsage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace updater {
+	
+	export class Asset {
+	    url: string;
+	    sha256: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Asset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.sha256 = source["sha256"];
+	    }
+	}
+	export class RollbackInfo {
+	    previousVersion: string;
+	    rolledBackFrom?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RollbackInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.previousVersion = source["previousVersion"];
+	        this.rolledBackFrom = source["rolledBackFrom"];
+	    }
+	}
+	export class UpdateCheck {
+	    channel: string;
+	    currentVersion: string;
+	    version: string;
+	    notes: string;
+	    releaseDate?: string;
+	    downgrade: boolean;
+	    rollback: boolean;
+	    format: string;
+	    asset?: Asset;
+	    instructions?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.channel = source["channel"];
+	        this.currentVersion = source["currentVersion"];
+	        this.version = source["version"];
+	        this.notes = source["notes"];
+	        this.releaseDate = source["releaseDate"];
+	        this.downgrade = source["downgrade"];
+	        this.rollback = source["rollback"];
+	        this.format = source["format"];
+	        this.asset = this.convertValues(source["asset"], Asset);
+	        this.instructions = source["instructions"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace worlds {
+	
+	export class World {
+	    name: string;
+	    branch: string;
+	    version: number;
+	    root: string;
+	    path: string;
+	    size: number;
+	    fileCount: number;
+	    lastPlayed: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new World(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.branch = source["branch"];
+	        this.version = source["version"];
+	        this.root = source["root"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.fileCount = source["fileCount"];
+	        this.lastPlayed = source["lastPlayed"];
 	    }
 	}
 
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"HyVanila/internal/util/download"
 )
 
 const (
@@ -62,9 +64,7 @@ func FetchAuthTokens(uuid, name, authDomain string) (*AuthTokens, error) {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := download.NewClient(10 * time.Second)
 
 	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
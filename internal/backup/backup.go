@@ -0,0 +1,521 @@
+package backup
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+)
+
+// Snapshot reasons
+const (
+	ReasonManual       = "manual"
+	ReasonPreUpdate    = "pre-update"
+	ReasonSession      = "session"
+	ReasonPreRestore   = "pre-restore"
+	ReasonPreOverwrite = "pre-overwrite"
+)
+
+// storeMu serializes all operations on the backup store
+var storeMu sync.Mutex
+
+// Snapshot is a point-in-time copy of an instance's worlds. File contents live in
+// the shared object store, so unchanged files cost nothing in later snapshots.
+type Snapshot struct {
+	ID        string `json:"id"`
+	Branch    string `json:"branch"`
+	Version   int    `json:"version"`
+	Reason    string `json:"reason"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"createdAt"` // ISO 8601 format
+	// Roots are the instance-relative directories captured by the snapshot
+	Roots []string `json:"roots"`
+	Files []File   `json:"files"`
+	// TotalSize is the uncompressed size of all files
+	TotalSize int64 `json:"totalSize"`
+	// AddedSize is the compressed size of objects this snapshot added to the store
+	AddedSize int64 `json:"addedSize"`
+}
+
+// File is a single file in a snapshot. Path is relative to the instance directory.
+type File struct {
+	Path    string      `json:"path"`
+	Hash    string      `json:"hash"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime string      `json:"modTime"`
+}
+
+// Summary is a snapshot without its file list, for listings
+type Summary struct {
+	ID        string `json:"id"`
+	Branch    string `json:"branch"`
+	Version   int    `json:"version"`
+	Reason    string `json:"reason"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt string `json:"createdAt"`
+	FileCount int    `json:"fileCount"`
+	TotalSize int64  `json:"totalSize"`
+	AddedSize int64  `json:"addedSize"`
+}
+
+// worldRoots are the instance-relative directories holding worlds
+var worldRoots = []string{
+	"saves",
+	filepath.Join("UserData", "Saves"),
+}
+
+func getObjectsDir() string {
+	return filepath.Join(env.GetBackupsDir(), "objects")
+}
+
+func getSnapshotsDir() string {
+	return filepath.Join(env.GetBackupsDir(), "snapshots")
+}
+
+// objectPath returns the store path of a content hash
+func objectPath(hash string) string {
+	return filepath.Join(getObjectsDir(), hash[:2], hash+".gz")
+}
+
+// Create snapshots the worlds of an instance. It returns nil without error if the
+// instance has no worlds yet.
+func Create(branch string, version int, reason string, note string) (*Snapshot, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	snap, err := create(branch, version, reason, note)
+	if err != nil || snap == nil {
+		return snap, err
+	}
+	if err := applyRetention(); err != nil {
+		fmt.Printf("Warning: Failed to apply backup retention: %v\n", err)
+	}
+	return snap, nil
+}
+
+// create snapshots the worlds of an instance without applying retention. The
+// caller holds storeMu.
+func create(branch string, version int, reason string, note string) (*Snapshot, error) {
+	instanceDir := env.GetInstanceDir(branch, version)
+	now := time.Now()
+	snap := &Snapshot{
+		ID:        newSnapshotID(now),
+		Branch:    branch,
+		Version:   version,
+		Reason:    reason,
+		Note:      note,
+		CreatedAt: now.Format(time.RFC3339),
+		Files:     []File{},
+	}
+
+	// Reuse hashes of files unchanged since the last snapshot so frequent
+	// snapshots don't re-read every world file
+	known := make(map[string]File)
+	if prev := latestSnapshot(branch, version); prev != nil {
+		for _, f := range prev.Files {
+			known[f.Path] = f
+		}
+	}
+
+	for _, root := range worldRoots {
+		rootDir := filepath.Join(instanceDir, root)
+		if info, err := os.Stat(rootDir); err != nil || !info.IsDir() {
+			continue
+		}
+		snap.Roots = append(snap.Roots, filepath.ToSlash(root))
+
+		err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(instanceDir, path)
+			if err != nil {
+				return err
+			}
+
+			file := File{
+				Path:    filepath.ToSlash(rel),
+				Size:    info.Size(),
+				Mode:    info.Mode().Perm(),
+				ModTime: info.ModTime().UTC().Format(time.RFC3339Nano),
+			}
+			if prev, ok := known[file.Path]; ok && prev.Size == file.Size && prev.ModTime == file.ModTime {
+				if _, err := os.Stat(objectPath(prev.Hash)); err == nil {
+					file.Hash = prev.Hash
+				}
+			}
+			if file.Hash == "" {
+				hash, added, err := storeObject(path)
+				if err != nil {
+					return fmt.Errorf("failed to back up %s: %w", file.Path, err)
+				}
+				file.Hash = hash
+				snap.AddedSize += added
+			}
+
+			snap.TotalSize += file.Size
+			snap.Files = append(snap.Files, file)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(snap.Files) == 0 {
+		fmt.Printf("No worlds to back up for %s v%d\n", branch, version)
+		return nil, nil
+	}
+
+	if err := saveSnapshot(snap); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Backed up %d world file(s) for %s v%d (%s, %d new bytes)\n", len(snap.Files), branch, version, reason, snap.AddedSize)
+	return snap, nil
+}
+
+// storeObject adds a file to the object store and returns its hash and the number
+// of compressed bytes added (0 if the content was already stored)
+func storeObject(path string) (string, int64, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(getObjectsDir(), 0755); err != nil {
+		return "", 0, err
+	}
+
+	// Compress into a temp file while hashing, then move it into place by hash
+	tmp, err := os.CreateTemp(getObjectsDir(), "incoming-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	gz := gzip.NewWriter(tmp)
+	if _, err := io.Copy(io.MultiWriter(gz, hasher), src); err != nil {
+		gz.Close()
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	dest := objectPath(hash)
+	if _, err := os.Stat(dest); err == nil {
+		return hash, 0, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", 0, err
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return hash, 0, nil
+	}
+	return hash, info.Size(), nil
+}
+
+// openObject opens a stored object for reading its uncompressed content
+func openObject(hash string) (io.ReadCloser, error) {
+	f, err := os.Open(objectPath(hash))
+	if err != nil {
+		return nil, fmt.Errorf("backup object %s is missing: %w", hash, err)
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &objectReader{Reader: gz, file: f}, nil
+}
+
+// objectReader closes both the gzip stream and the underlying file
+type objectReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (r *objectReader) Close() error {
+	r.Reader.Close()
+	return r.file.Close()
+}
+
+// newSnapshotID returns a sortable unique snapshot ID
+func newSnapshotID(t time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	t = t.UTC()
+	return fmt.Sprintf("%s%06d-%s", t.Format("20060102-150405"), t.Nanosecond()/1000, hex.EncodeToString(suffix))
+}
+
+func saveSnapshot(snap *Snapshot) error {
+	if err := os.MkdirAll(getSnapshotsDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(getSnapshotsDir(), snap.ID+".json")
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadSnapshot reads a snapshot by ID
+func loadSnapshot(id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return nil, fmt.Errorf("invalid backup id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(getSnapshotsDir(), id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("backup not found: %s", id)
+		}
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("backup %s is corrupted: %w", id, err)
+	}
+	return &snap, nil
+}
+
+// loadAllSnapshots reads every snapshot, newest first
+func loadAllSnapshots() []*Snapshot {
+	entries, err := os.ReadDir(getSnapshotsDir())
+	if err != nil {
+		return nil
+	}
+
+	var snaps []*Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snap, err := loadSnapshot(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].ID > snaps[j].ID
+	})
+	return snaps
+}
+
+// latestSnapshot returns the newest snapshot of an instance (nil if there is none)
+func latestSnapshot(branch string, version int) *Snapshot {
+	for _, snap := range loadAllSnapshots() {
+		if snap.Branch == branch && snap.Version == version {
+			return snap
+		}
+	}
+	return nil
+}
+
+// Get returns a snapshot with its file list
+func Get(id string) (*Snapshot, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return loadSnapshot(id)
+}
+
+// List returns snapshot summaries, newest first. An empty branch lists all instances.
+func List(branch string, version int) []Summary {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	summaries := []Summary{}
+	for _, snap := range loadAllSnapshots() {
+		if branch != "" && (snap.Branch != branch || snap.Version != version) {
+			continue
+		}
+		summaries = append(summaries, Summary{
+			ID:        snap.ID,
+			Branch:    snap.Branch,
+			Version:   snap.Version,
+			Reason:    snap.Reason,
+			Note:      snap.Note,
+			CreatedAt: snap.CreatedAt,
+			FileCount: len(snap.Files),
+			TotalSize: snap.TotalSize,
+			AddedSize: snap.AddedSize,
+		})
+	}
+	return summaries
+}
+
+// Delete removes a snapshot and any objects no other snapshot uses
+func Delete(id string) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if _, err := loadSnapshot(id); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(getSnapshotsDir(), id+".json")); err != nil {
+		return err
+	}
+	_, err := collectGarbage()
+	return err
+}
+
+// Restore replaces the worlds of the target instance with a snapshot's content.
+// The target's current worlds are snapshotted first so a restore can be undone.
+func Restore(id string, branch string, version int) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	snap, err := loadSnapshot(id)
+	if err != nil {
+		return err
+	}
+
+	// Retention waits until the restore is done, so it can't prune the snapshot
+	// being restored or collect its objects
+	if _, err := create(branch, version, ReasonPreRestore, fmt.Sprintf("Before restoring %s", id)); err != nil {
+		return fmt.Errorf("failed to back up current worlds before restoring: %w", err)
+	}
+
+	// Stage every root before touching the live worlds
+	instanceDir := env.GetInstanceDir(branch, version)
+	var staged []string
+	discard := func() {
+		for _, stagingDir := range staged {
+			os.RemoveAll(stagingDir)
+		}
+	}
+	for _, root := range snap.Roots {
+		stagingDir := filepath.Join(instanceDir, filepath.FromSlash(root)) + ".restoring"
+		os.RemoveAll(stagingDir)
+		staged = append(staged, stagingDir)
+		// Empty roots have no files to create the directory
+		if err := os.MkdirAll(stagingDir, 0755); err != nil {
+			discard()
+			return fmt.Errorf("failed to restore %s: %w", root, err)
+		}
+
+		prefix := root + "/"
+		for _, file := range snap.Files {
+			if !strings.HasPrefix(file.Path, prefix) {
+				continue
+			}
+			dest := filepath.Join(stagingDir, filepath.FromSlash(strings.TrimPrefix(file.Path, prefix)))
+			if err := extractObject(file, dest); err != nil {
+				discard()
+				return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+			}
+		}
+	}
+
+	// Swap the restored directories in only once all of them are complete
+	for i, root := range snap.Roots {
+		rootDir := filepath.Join(instanceDir, filepath.FromSlash(root))
+		if err := os.RemoveAll(rootDir); err != nil {
+			discard()
+			return fmt.Errorf("failed to clear %s: %w", root, err)
+		}
+		if err := os.Rename(staged[i], rootDir); err != nil {
+			discard()
+			return fmt.Errorf("failed to move restored %s into place: %w", root, err)
+		}
+	}
+
+	fmt.Printf("Restored backup %s to %s v%d\n", id, branch, version)
+	if err := applyRetention(); err != nil {
+		fmt.Printf("Warning: Failed to apply backup retention: %v\n", err)
+	}
+	return nil
+}
+
+// extractObject writes a snapshot file's content to dest
+func extractObject(file File, dest string) error {
+	src, err := openObject(file.Hash)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	mode := file.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if modTime, err := time.Parse(time.RFC3339Nano, file.ModTime); err == nil {
+		os.Chtimes(dest, modTime, modTime)
+	}
+	return nil
+}
+
+// GetStoreSize returns the on-disk size of the backup object store in bytes
+func GetStoreSize() int64 {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return storeSize()
+}
+
+func storeSize() int64 {
+	var total int64
+	filepath.WalkDir(getObjectsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
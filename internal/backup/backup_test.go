@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+)
+
+// useTempAppDir points the launcher data directory at a fresh temp dir
+func useTempAppDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	useTempAppDir(t)
+	instanceDir := env.GetInstanceDir("release", 3)
+	world := filepath.Join(instanceDir, "UserData", "Saves", "World", "level.dat")
+	// A standard instance has an empty saves folder next to the real worlds
+	if err := os.MkdirAll(filepath.Join(instanceDir, "saves"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, world, "original")
+
+	snap, err := Create("release", 3, ReasonManual, "")
+	if err != nil || snap == nil {
+		t.Fatalf("Create = %v, %v", snap, err)
+	}
+
+	writeFile(t, world, "changed")
+	writeFile(t, filepath.Join(instanceDir, "UserData", "Saves", "Other", "level.dat"), "new world")
+
+	if err := Restore(snap.ID, "release", 3); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := readFile(t, world); got != "original" {
+		t.Errorf("restored world = %q, want %q", got, "original")
+	}
+	if _, err := os.Stat(filepath.Join(instanceDir, "UserData", "Saves", "Other")); !os.IsNotExist(err) {
+		t.Errorf("world created after the snapshot survived the restore: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(instanceDir, "saves")); err != nil || !info.IsDir() {
+		t.Errorf("empty saves folder is gone after restore: %v", err)
+	}
+	for _, root := range []string{"saves", filepath.Join("UserData", "Saves")} {
+		if _, err := os.Stat(filepath.Join(instanceDir, root) + ".restoring"); !os.IsNotExist(err) {
+			t.Errorf("staging folder for %s left behind", root)
+		}
+	}
+
+	// The changed worlds were backed up before being replaced
+	var preRestore *Summary
+	for _, s := range List("release", 3) {
+		if s.Reason == ReasonPreRestore {
+			preRestore = &s
+			break
+		}
+	}
+	if preRestore == nil {
+		t.Fatal("no pre-restore backup was taken")
+	}
+	if err := Restore(preRestore.ID, "release", 3); err != nil {
+		t.Fatalf("undoing the restore: %v", err)
+	}
+	if got := readFile(t, world); got != "changed" {
+		t.Errorf("undone world = %q, want %q", got, "changed")
+	}
+}
+
+func TestRestoreSurvivesRetention(t *testing.T) {
+	useTempAppDir(t)
+	defer SetPolicy(GetPolicy())
+	SetPolicy(Policy{KeepLast: 1})
+
+	instanceDir := env.GetInstanceDir("release", 4)
+	world := filepath.Join(instanceDir, "UserData", "Saves", "World", "level.dat")
+	writeFile(t, world, "first")
+	snap, err := Create("release", 4, ReasonSession, "")
+	if err != nil || snap == nil {
+		t.Fatalf("Create = %v, %v", snap, err)
+	}
+
+	// The pre-restore backup would leave the restored snapshot outside KeepLast
+	writeFile(t, world, "second")
+	if err := Restore(snap.ID, "release", 4); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got := readFile(t, world); got != "first" {
+		t.Errorf("restored world = %q, want %q", got, "first")
+	}
+}
@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Export writes a snapshot's files to a zip archive at destPath. Paths inside the
+// archive are relative to the instance directory (e.g. "saves/MyWorld/...").
+func Export(id string, destPath string) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	snap, err := loadSnapshot(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+	tmpPath := destPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if err := writeArchive(out, snap); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	fmt.Printf("Exported backup %s to %s\n", id, destPath)
+	return nil
+}
+
+// writeArchive streams every file of a snapshot into a zip writer
+func writeArchive(w io.Writer, snap *Snapshot) error {
+	zw := zip.NewWriter(w)
+	for _, file := range snap.Files {
+		header := &zip.FileHeader{Name: file.Path, Method: zip.Deflate}
+		if modTime, err := time.Parse(time.RFC3339Nano, file.ModTime); err == nil {
+			header.Modified = modTime
+		}
+		header.SetMode(file.Mode)
+
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := openObject(file.Hash)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, src)
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", file.Path, err)
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy controls when automatic backups are taken and how long they are kept
+type Policy struct {
+	// OnUpdate snapshots worlds before a game update is applied
+	OnUpdate bool `json:"onUpdate"`
+	// OnSessionExit snapshots worlds after the game exits
+	OnSessionExit bool `json:"onSessionExit"`
+	// KeepLast keeps the N newest automatic snapshots per instance (0 = no limit)
+	KeepLast int `json:"keepLast"`
+	// KeepDaily additionally keeps the newest snapshot of each of the last M days
+	KeepDaily int `json:"keepDaily"`
+	// MaxSizeMB caps the object store size; oldest automatic snapshots are pruned
+	// first (0 = no cap)
+	MaxSizeMB int `json:"maxSizeMB"`
+}
+
+var (
+	policyMu sync.RWMutex
+	policy   = Policy{OnUpdate: true, OnSessionExit: true, KeepLast: 10, KeepDaily: 7, MaxSizeMB: 2048}
+)
+
+// SetPolicy replaces the active backup policy
+func SetPolicy(p Policy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// GetPolicy returns the active backup policy
+func GetPolicy() Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
+
+// ApplyRetention prunes snapshots according to the active policy
+func ApplyRetention() error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return applyRetention()
+}
+
+// applyRetention prunes automatic snapshots that fall outside the policy. Manual
+// snapshots are only ever removed by the user.
+func applyRetention() error {
+	p := GetPolicy()
+	snaps := loadAllSnapshots()
+
+	keep := make(map[string]bool)
+	byInstance := make(map[string][]*Snapshot)
+	for _, snap := range snaps {
+		k := fmt.Sprintf("%s-%d", snap.Branch, snap.Version)
+		byInstance[k] = append(byInstance[k], snap)
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -p.KeepDaily)
+	for _, instanceSnaps := range byInstance {
+		// The newest snapshot of every instance always survives
+		keep[instanceSnaps[0].ID] = true
+
+		auto := 0
+		days := make(map[string]bool)
+		for _, snap := range instanceSnaps {
+			if snap.Reason == ReasonManual {
+				keep[snap.ID] = true
+				continue
+			}
+			auto++
+			if p.KeepLast <= 0 || auto <= p.KeepLast {
+				keep[snap.ID] = true
+			}
+			created, err := time.Parse(time.RFC3339, snap.CreatedAt)
+			if err != nil {
+				keep[snap.ID] = true
+				continue
+			}
+			day := created.Local().Format("2006-01-02")
+			if p.KeepDaily > 0 && created.After(cutoff) && !days[day] {
+				days[day] = true
+				keep[snap.ID] = true
+			}
+		}
+	}
+
+	removed := 0
+	for _, snap := range snaps {
+		if !keep[snap.ID] {
+			if err := os.Remove(filepath.Join(getSnapshotsDir(), snap.ID+".json")); err == nil {
+				removed++
+			}
+		}
+	}
+
+	freed, err := collectGarbage()
+	if err != nil {
+		return err
+	}
+
+	// Enforce the size cap by dropping the oldest automatic snapshots
+	if p.MaxSizeMB > 0 {
+		limit := int64(p.MaxSizeMB) * 1024 * 1024
+		for storeSize() > limit {
+			victim := oldestPrunable(loadAllSnapshots())
+			if victim == nil {
+				fmt.Printf("Warning: Backups exceed %d MB but nothing more can be pruned\n", p.MaxSizeMB)
+				break
+			}
+			if err := os.Remove(filepath.Join(getSnapshotsDir(), victim.ID+".json")); err != nil {
+				return err
+			}
+			removed++
+			n, err := collectGarbage()
+			if err != nil {
+				return err
+			}
+			freed += n
+		}
+	}
+
+	if removed > 0 {
+		fmt.Printf("Pruned %d backup(s), freed %d bytes\n", removed, freed)
+	}
+	return nil
+}
+
+// oldestPrunable returns the oldest automatic snapshot that isn't the newest of
+// its instance (snaps must be sorted newest first)
+func oldestPrunable(snaps []*Snapshot) *Snapshot {
+	newest := make(map[string]bool)
+	for _, snap := range snaps {
+		k := fmt.Sprintf("%s-%d", snap.Branch, snap.Version)
+		if !newest[k] {
+			newest[k] = true
+			newest[snap.ID] = true
+		}
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].Reason != ReasonManual && !newest[snaps[i].ID] {
+			return snaps[i]
+		}
+	}
+	return nil
+}
+
+// collectGarbage deletes objects no snapshot references and returns the bytes freed
+func collectGarbage() (int64, error) {
+	used := make(map[string]bool)
+	for _, snap := range loadAllSnapshots() {
+		for _, f := range snap.Files {
+			used[f.Hash] = true
+		}
+	}
+
+	var freed int64
+	err := filepath.WalkDir(getObjectsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		// Leftovers from interrupted writes are garbage too
+		if !strings.HasPrefix(name, "incoming-") && used[strings.TrimSuffix(name, ".gz")] {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			freed += info.Size()
+		}
+		os.Remove(path)
+		return nil
+	})
+	return freed, err
+}
@@ -0,0 +1,8 @@
+package config
+
+// BandwidthRule replaces the download limits during a time-of-day window
+type BandwidthRule struct {
+	Window         string `toml:"window" json:"window"` // "HH:MM-HH:MM", may wrap past midnight
+	ForegroundKBps int    `toml:"foreground_kbps" json:"foregroundKBps"`
+	BackgroundKBps int    `toml:"background_kbps" json:"backgroundKBps"`
+}
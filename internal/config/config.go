@@ -1,8 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"HyVanila/internal/env"
 
@@ -13,8 +18,51 @@ func configPath() string {
 	return filepath.Join(env.GetDefaultAppDir(), "config.toml")
 }
 
-// Save saves the configuration to disk
+// backupPath holds the config as it was before the last save
+func backupPath() string {
+	return configPath() + ".bak"
+}
+
+// saveMu keeps concurrent saves from interleaving the backup and the write
+var saveMu sync.Mutex
+
+// LoadError describes what went wrong loading the config. The config returned
+// with it is still usable.
+type LoadError struct {
+	// Corrupt is where an unreadable config was moved, so saving can't overwrite it
+	Corrupt string
+	// Cause is why the config couldn't be read
+	Cause error
+	// Recovered is the file the config was restored from ("" = defaults were used)
+	Recovered string
+	// Invalid lists values that were reset to their defaults or need attention
+	Invalid ValidationErrors
+}
+
+func (e *LoadError) Error() string {
+	var parts []string
+	if e.Corrupt != "" {
+		source := "defaults were"
+		if e.Recovered != "" {
+			source = "the last good config was"
+		}
+		parts = append(parts, fmt.Sprintf("config could not be read (%v); it was moved to %s and %s loaded instead", e.Cause, e.Corrupt, source))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid settings: "+e.Invalid.Error())
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Save writes the config atomically, keeping the one it replaces as a backup
+// if that one still loads
 func Save(cfg *Config) error {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	if schemaOf(cfg.Version) < SchemaVersion {
+		cfg.Version = strconv.Itoa(SchemaVersion)
+	}
 	data, err := toml.Marshal(cfg)
 	if err != nil {
 		return err
@@ -25,10 +73,46 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	return os.WriteFile(configPath(), data, 0644)
+	if current, err := os.ReadFile(configPath()); err == nil {
+		var raw map[string]any
+		if toml.Unmarshal(current, &raw) == nil {
+			if err := writeFile(backupPath(), current); err != nil {
+				fmt.Printf("Warning: Failed to back up config: %v\n", err)
+			}
+		}
+	}
+
+	return writeFile(configPath(), data)
 }
 
-// Load loads the configuration from disk
+// writeFile replaces a file so that a crash leaves either the old or the new content
+func writeFile(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Load reads the config, migrating older schema versions and filling settings
+// it doesn't have with their defaults. Invalid values are reset to defaults and
+// an unreadable file is moved aside and replaced by the backup or defaults; both
+// are reported in a *LoadError alongside the usable config.
 func Load() (*Config, error) {
 	data, err := os.ReadFile(configPath())
 	if err != nil {
@@ -42,10 +126,71 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	loadErr := &LoadError{}
+	cfg, changed, err := decode(data)
+	if err != nil {
+		// Only write over the file once it has been moved aside
+		cfg, changed = recoverCorrupt(loadErr, err)
+	}
+
+	loadErr.Invalid = repair(cfg)
+	for _, invalid := range loadErr.Invalid {
+		changed = changed || !invalid.missingPath
+	}
+	// Write migrated and repaired values back; the file they came from becomes the backup
+	if changed {
+		if err := Save(cfg); err != nil {
+			fmt.Printf("Warning: Failed to save config: %v\n", err)
+		}
+	}
+
+	if loadErr.Corrupt == "" && len(loadErr.Invalid) == 0 {
+		return cfg, nil
+	}
+	return cfg, loadErr
+}
+
+// recoverCorrupt moves an unreadable config aside and returns the last good
+// config, or defaults if there is none. It reports whether the file was moved.
+func recoverCorrupt(loadErr *LoadError, cause error) (*Config, bool) {
+	loadErr.Cause = cause
+	loadErr.Corrupt = configPath() + ".corrupt-" + time.Now().Format("20060102-150405")
+	moved := true
+	if err := os.Rename(configPath(), loadErr.Corrupt); err != nil {
+		fmt.Printf("Warning: Failed to move unreadable config aside: %v\n", err)
+		loadErr.Corrupt = configPath()
+		moved = false
+	}
+
+	if data, err := os.ReadFile(backupPath()); err == nil {
+		if cfg, _, err := decode(data); err == nil {
+			loadErr.Recovered = backupPath()
+			return cfg, moved
+		}
+	}
+	return Default(), moved
+}
+
+// decode parses a config file, migrates it to the current schema and lays it
+// over the defaults, so settings it doesn't mention keep their default values.
+// It reports whether a migration ran.
+func decode(data []byte) (*Config, bool, error) {
+	var raw map[string]any
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, false, err
+	}
+	migrated, err := migrate(raw)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return &cfg, nil
+	merged, err := toml.Marshal(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	cfg := Default()
+	if err := toml.Unmarshal(merged, cfg); err != nil {
+		return nil, false, err
+	}
+	return cfg, migrated, nil
 }
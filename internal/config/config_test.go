@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		domain  string
+		wantErr bool
+	}{
+		{"", false},
+		{"auth.example.com", false},
+		{"localhost", false},
+		{"my-server.example.io:8443", false},
+		{"127.0.0.1", false},
+		{"10.0.0.2:3000", false},
+		{"[::1]:8080", false},
+		{"https://auth.example.com", true},
+		{"auth.example.com/login", true},
+		{"auth example.com", true},
+		{"auth.example.com:", true},
+		{"auth.example.com:0", true},
+		{"auth.example.com:70000", true},
+		{"auth.example.com:http", true},
+		{"auth..example.com", true},
+		{".example.com", true},
+		{"-auth.example.com", true},
+		{"auth-.example.com", true},
+		{"auth_1.example.com", true},
+		{"äuth.example.com", true},
+		{strings.Repeat("a", 64) + ".com", true},
+		{strings.Repeat("abcdefghi.", 26) + "com", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateDomain(tt.domain); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateDomain(%q) = %v, want error %v", tt.domain, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	defaults := Default()
+	tests := []struct {
+		name     string
+		raw      map[string]any
+		want     map[string]any
+		migrated bool
+	}{
+		{
+			name:     "unversioned config gets zero settings repaired",
+			raw:      map[string]any{"max_memory": int64(0), "server_port": int64(0), "proxy_mode": "", "version_type": "prerelease"},
+			want:     map[string]any{"version": "2", "max_memory": int64(defaults.MaxMemory), "server_port": int64(defaults.ServerPort), "proxy_mode": defaults.ProxyMode, "version_type": "pre-release"},
+			migrated: true,
+		},
+		{
+			name:     "schema 1 keeps real choices",
+			raw:      map[string]any{"version": "1.0.0", "max_memory": int64(6144), "version_type": "release"},
+			want:     map[string]any{"version": "2", "max_memory": int64(6144), "version_type": "release"},
+			migrated: true,
+		},
+		{
+			name: "current schema is left alone",
+			raw:  map[string]any{"version": "2", "max_memory": int64(0)},
+			want: map[string]any{"version": "2", "max_memory": int64(0)},
+		},
+		{
+			name: "newer schema is left alone",
+			raw:  map[string]any{"version": "3", "server_port": int64(0)},
+			want: map[string]any{"version": "3", "server_port": int64(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := migrate(tt.raw)
+			if err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			if migrated != tt.migrated {
+				t.Errorf("migrate reported %v, want %v", migrated, tt.migrated)
+			}
+			for key, want := range tt.want {
+				if got := tt.raw[key]; got != want {
+					t.Errorf("%s = %v (%T), want %v (%T)", key, got, got, want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	tests := map[string]int{
+		"":      1,
+		"1.0.0": 1,
+		"2":     2,
+		"3.1":   3,
+		"0":     1,
+		"-4":    1,
+		"abc":   1,
+	}
+	for version, want := range tests {
+		if got := schemaOf(version); got != want {
+			t.Errorf("schemaOf(%q) = %d, want %d", version, got, want)
+		}
+	}
+}
@@ -1,27 +1,56 @@
 package config
 
+import "strconv"
+
 // Config represents the launcher configuration
 type Config struct {
-	Version           string `toml:"version" json:"version"`
-	Nick              string `toml:"nick" json:"nick"`
-	MusicEnabled      bool   `toml:"music_enabled" json:"musicEnabled"`
-	VersionType       string `toml:"version_type" json:"versionType"`
-	SelectedVersion   int    `toml:"selected_version" json:"selectedVersion"`
-	CustomInstanceDir string `toml:"custom_instance_dir" json:"customInstanceDir"` // Custom path for instances
-	AutoUpdateLatest  bool   `toml:"auto_update_latest" json:"autoUpdateLatest"`   // Auto-update latest instance
-	OnlineMode        bool   `toml:"online_mode" json:"onlineMode"`                // Enable online multiplayer
-	AuthDomain        string `toml:"auth_domain" json:"authDomain"`                // Custom auth server domain
-	JavaPath          string `toml:"java_path" json:"javaPath"`                    // Custom path to Java executable
-	DiscordRPCEnabled bool   `toml:"discord_rpc_enabled" json:"discordRPCEnabled"` // Enable Discord Rich Presence
-	MaxMemory         int    `toml:"max_memory" json:"maxMemory"`                  // Maximum memory in MB
-	MinMemory         int    `toml:"min_memory" json:"minMemory"`                  // Minimum memory in MB
-	FullScreen        bool   `toml:"full_screen" json:"fullScreen"`                // Launch in full screen
+	Version           string            `toml:"version" json:"version"` // Config schema version (see SchemaVersion)
+	Nick              string            `toml:"nick" json:"nick"`
+	MusicEnabled      bool              `toml:"music_enabled" json:"musicEnabled"`
+	VersionType       string            `toml:"version_type" json:"versionType"`
+	SelectedVersion   int               `toml:"selected_version" json:"selectedVersion"`
+	CustomInstanceDir string            `toml:"custom_instance_dir" json:"customInstanceDir"` // Custom path for instances
+	AutoUpdateLatest  bool              `toml:"auto_update_latest" json:"autoUpdateLatest"`   // Auto-update latest instance
+	OnlineMode        bool              `toml:"online_mode" json:"onlineMode"`                // Enable online multiplayer
+	AuthDomain        string            `toml:"auth_domain" json:"authDomain"`                // Custom auth server domain
+	JavaPath          string            `toml:"java_path" json:"javaPath"`                    // Custom path to Java executable
+	DiscordRPCEnabled bool              `toml:"discord_rpc_enabled" json:"discordRPCEnabled"` // Enable Discord Rich Presence
+	MaxMemory         int               `toml:"max_memory" json:"maxMemory"`                  // Maximum memory in MB
+	MinMemory         int               `toml:"min_memory" json:"minMemory"`                  // Minimum memory in MB
+	FullScreen        bool              `toml:"full_screen" json:"fullScreen"`                // Launch in full screen
+	ServerMaxMemory   int               `toml:"server_max_memory" json:"serverMaxMemory"`     // Dedicated server maximum memory in MB
+	ServerMinMemory   int               `toml:"server_min_memory" json:"serverMinMemory"`     // Dedicated server minimum memory in MB
+	ServerPort        int               `toml:"server_port" json:"serverPort"`                // Dedicated server port
+	ServerArgs        string            `toml:"server_args" json:"serverArgs"`                // Extra dedicated server arguments
+	Servers           []SavedServer     `toml:"servers" json:"servers"`                       // Saved server favorites
+	BackupOnUpdate    bool              `toml:"backup_on_update" json:"backupOnUpdate"`       // Back up worlds before game updates
+	BackupOnExit      bool              `toml:"backup_on_exit" json:"backupOnExit"`           // Back up worlds after each session
+	BackupKeepLast    int               `toml:"backup_keep_last" json:"backupKeepLast"`       // Automatic backups kept per instance
+	BackupKeepDaily   int               `toml:"backup_keep_daily" json:"backupKeepDaily"`     // Days with one kept daily backup
+	BackupMaxSizeMB   int               `toml:"backup_max_size_mb" json:"backupMaxSizeMB"`    // Backup storage cap in MB (0 = none)
+	ShareGameFiles    bool              `toml:"share_game_files" json:"shareGameFiles"`       // Hardlink identical game files between instances
+	CacheMaxSizeMB    int               `toml:"cache_max_size_mb" json:"cacheMaxSizeMB"`      // Download cache cap in MB (0 = none)
+	CacheKeepPatches  int               `toml:"cache_keep_patches" json:"cacheKeepPatches"`   // Game patches kept for reinstalls
+	PrefetchInterval  int               `toml:"prefetch_interval" json:"prefetchInterval"`    // Minutes between background update checks
+	PrefetchWindows   []string          `toml:"prefetch_windows" json:"prefetchWindows"`      // "HH:MM-HH:MM" download windows (empty = any time)
+	PrefetchMaxKBps   int               `toml:"prefetch_max_kbps" json:"prefetchMaxKBps"`     // Background download cap in KB/s (0 = none)
+	ForegroundKBps    int               `toml:"foreground_kbps" json:"foregroundKBps"`        // Limit for downloads the user waits on in KB/s (0 = none)
+	BackgroundKBps    int               `toml:"background_kbps" json:"backgroundKBps"`        // Limit for background downloads in KB/s (0 = none)
+	BandwidthRules    []BandwidthRule   `toml:"bandwidth_rules" json:"bandwidthRules"`        // Time-of-day overrides of the limits
+	ProxyMode         string            `toml:"proxy_mode" json:"proxyMode"`                  // "system", "none" or "manual"
+	ProxyURL          string            `toml:"proxy_url" json:"proxyURL"`                    // Manual proxy, e.g. http://proxy:3128 or socks5://proxy:1080
+	ProxyUsername     string            `toml:"proxy_username" json:"proxyUsername"`          // Proxy auth user (empty = none)
+	ProxyPassword     string            `toml:"proxy_password" json:"proxyPassword"`          // Proxy auth password
+	NoProxy           []string          `toml:"no_proxy" json:"noProxy"`                      // Hosts, domains and CIDR ranges reached directly
+	CABundles         []string          `toml:"ca_bundles" json:"caBundles"`                  // Extra trusted CA certificate files (PEM)
+	UpdateChannel     string            `toml:"update_channel" json:"updateChannel"`          // Launcher update channel: "stable", "beta" or "nightly" (empty = the build's own)
+	InstanceRuntimes  map[string]string `toml:"instance_runtimes" json:"instanceRuntimes"`    // Java runtime ID per instance folder (missing = default)
 }
 
 // Default returns the default configuration
 func Default() *Config {
 	return &Config{
-		Version:           "1.0.0",
+		Version:           strconv.Itoa(SchemaVersion),
 		Nick:              "Steven",
 		MusicEnabled:      true,
 		VersionType:       "release",
@@ -35,5 +64,30 @@ func Default() *Config {
 		MaxMemory:         2560,
 		MinMemory:         512,
 		FullScreen:        false,
+		ServerMaxMemory:   4096,
+		ServerMinMemory:   1024,
+		ServerPort:        5520,
+		ServerArgs:        "",
+		Servers:           []SavedServer{},
+		BackupOnUpdate:    true,
+		BackupOnExit:      true,
+		BackupKeepLast:    10,
+		BackupKeepDaily:   7,
+		BackupMaxSizeMB:   2048,
+		ShareGameFiles:    true,
+		CacheMaxSizeMB:    4096,
+		CacheKeepPatches:  1,
+		PrefetchInterval:  60,
+		PrefetchWindows:   []string{},
+		PrefetchMaxKBps:   0,
+		ForegroundKBps:    0,
+		BackgroundKBps:    0,
+		BandwidthRules:    []BandwidthRule{},
+		ProxyMode:         "system",
+		ProxyURL:          "",
+		NoProxy:           []string{},
+		CABundles:         []string{},
+		UpdateChannel:     "", // Empty follows the channel of the installed build
+		InstanceRuntimes:  map[string]string{},
 	}
 }
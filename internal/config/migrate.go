@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the config layout this launcher writes. Configs record it in
+// their version key and are migrated on load when it is older.
+const SchemaVersion = 2
+
+// migrations upgrade a raw config from the schema version they are keyed by to
+// the next one
+var migrations = map[int]func(raw map[string]any){
+	1: migrateV1,
+}
+
+// schemaOf reads the schema version from a version key. Launchers before
+// schema 2 wrote "1.0.0" or nothing.
+func schemaOf(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	schema, err := strconv.Atoi(major)
+	if err != nil || schema < 1 {
+		return 1
+	}
+	return schema
+}
+
+// migrate brings a raw config up to SchemaVersion and reports whether it had to.
+// Configs from newer launchers are left as they are.
+func migrate(raw map[string]any) (bool, error) {
+	version, _ := raw["version"].(string)
+	schema := schemaOf(version)
+	if schema > SchemaVersion {
+		fmt.Printf("Warning: Config was written by a newer launcher (schema %d), loading it as schema %d\n", schema, SchemaVersion)
+		return false, nil
+	}
+	if schema == SchemaVersion {
+		return false, nil
+	}
+	for ; schema < SchemaVersion; schema++ {
+		step, ok := migrations[schema]
+		if !ok {
+			return false, fmt.Errorf("no migration from config schema %d", schema)
+		}
+		step(raw)
+		fmt.Printf("Migrated config from schema %d to %d\n", schema, schema+1)
+	}
+	raw["version"] = strconv.Itoa(SchemaVersion)
+	return true, nil
+}
+
+// migrateV1 repairs settings older launchers saved as zero: they loaded configs
+// without defaults, so settings added after the file was created were written
+// back empty. Only values that can't be a real choice are reset.
+func migrateV1(raw map[string]any) {
+	defaults := Default()
+	zeroInts := map[string]int{
+		"max_memory":        defaults.MaxMemory,
+		"min_memory":        defaults.MinMemory,
+		"server_max_memory": defaults.ServerMaxMemory,
+		"server_min_memory": defaults.ServerMinMemory,
+		"server_port":       defaults.ServerPort,
+		"prefetch_interval": defaults.PrefetchInterval,
+	}
+	for key, value := range zeroInts {
+		if n, ok := raw[key].(int64); ok && n == 0 {
+			raw[key] = int64(value)
+		}
+	}
+
+	emptyStrings := map[string]string{
+		"version_type": defaults.VersionType,
+		"proxy_mode":   defaults.ProxyMode,
+	}
+	for key, value := range emptyStrings {
+		if s, ok := raw[key].(string); ok && s == "" {
+			raw[key] = value
+		}
+	}
+
+	// Older launchers stored the pre-release branch without the hyphen
+	if raw["version_type"] == "prerelease" {
+		raw["version_type"] = "pre-release"
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+// SavedServer is a server in the favorites list
+type SavedServer struct {
+	ID      string `toml:"id" json:"id"`
+	Name    string `toml:"name" json:"name"`
+	Address string `toml:"address" json:"address"` // host:port
+	// AuthDomain is the auth domain the server requires (empty for offline servers)
+	AuthDomain string `toml:"auth_domain" json:"authDomain"`
+	// Branch and Version select the instance to launch (empty branch uses the selected instance)
+	Branch  string `toml:"branch" json:"branch"`
+	Version int    `toml:"version" json:"version"`
+	// Nick is the player name to join with (empty uses the launcher nick)
+	Nick       string `toml:"nick" json:"nick"`
+	AddedAt    string `toml:"added_at" json:"addedAt"`       // ISO 8601 format
+	LastPlayed string `toml:"last_played" json:"lastPlayed"` // ISO 8601 format
+}
+
+// FindServer returns the saved server with the given ID (nil if not found)
+func (c *Config) FindServer(id string) *SavedServer {
+	for i := range c.Servers {
+		if c.Servers[i].ID == id {
+			return &c.Servers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Memory limits accepted for the game and the dedicated server, in MB
+const (
+	MinMemoryMB = 256
+	MaxMemoryMB = 65536
+)
+
+// FieldError is an invalid config value
+type FieldError struct {
+	Key     string // Setting name as in config.toml
+	Message string
+	// related is reset together with Key, for limits that must agree
+	related string
+	// missingPath marks a file or folder that isn't there; it may be on a
+	// drive that comes back, so it isn't reset
+	missingPath bool
+}
+
+func (e *FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// ValidationErrors lists the invalid values of a config
+type ValidationErrors []*FieldError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks every setting and returns ValidationErrors naming each invalid one
+func (c *Config) Validate() error {
+	if errs := c.validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *Config) validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(key, format string, args ...any) {
+		errs = append(errs, &FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	errs = append(errs, memoryErrors("min_memory", "max_memory", c.MinMemory, c.MaxMemory)...)
+	errs = append(errs, memoryErrors("server_min_memory", "server_max_memory", c.ServerMinMemory, c.ServerMaxMemory)...)
+	if c.ServerPort < 1 || c.ServerPort > 65535 {
+		add("server_port", "must be between 1 and 65535 (got %d)", c.ServerPort)
+	}
+	if c.PrefetchInterval < 5 {
+		add("prefetch_interval", "must be at least 5 minutes (got %d)", c.PrefetchInterval)
+	}
+
+	switch c.VersionType {
+	case "release", "pre-release":
+	default:
+		add("version_type", `must be "release" or "pre-release" (got %q)`, c.VersionType)
+	}
+	switch c.ProxyMode {
+	case "system", "none", "manual":
+	default:
+		add("proxy_mode", `must be "system", "none" or "manual" (got %q)`, c.ProxyMode)
+	}
+	switch c.UpdateChannel {
+	case "", "stable", "beta", "nightly":
+	default:
+		add("update_channel", `must be "stable", "beta" or "nightly" (got %q)`, c.UpdateChannel)
+	}
+	if err := ValidateDomain(c.AuthDomain); err != nil {
+		add("auth_domain", "%v", err)
+	}
+
+	for key, value := range map[string]int{
+		"backup_keep_last":   c.BackupKeepLast,
+		"backup_keep_daily":  c.BackupKeepDaily,
+		"backup_max_size_mb": c.BackupMaxSizeMB,
+		"cache_max_size_mb":  c.CacheMaxSizeMB,
+		"cache_keep_patches": c.CacheKeepPatches,
+		"prefetch_max_kbps":  c.PrefetchMaxKBps,
+		"foreground_kbps":    c.ForegroundKBps,
+		"background_kbps":    c.BackgroundKBps,
+	} {
+		if value < 0 {
+			add(key, "can't be negative (got %d)", value)
+		}
+	}
+
+	if c.CustomInstanceDir != "" {
+		if info, err := os.Stat(c.CustomInstanceDir); err != nil || !info.IsDir() {
+			errs = append(errs, &FieldError{Key: "custom_instance_dir", Message: fmt.Sprintf("folder %s not found", c.CustomInstanceDir), missingPath: true})
+		}
+	}
+	if c.JavaPath != "" {
+		if info, err := os.Stat(c.JavaPath); err != nil || info.IsDir() {
+			errs = append(errs, &FieldError{Key: "java_path", Message: fmt.Sprintf("Java executable %s not found", c.JavaPath), missingPath: true})
+		}
+	}
+	for _, bundle := range c.CABundles {
+		if _, err := os.Stat(bundle); err != nil {
+			errs = append(errs, &FieldError{Key: "ca_bundles", Message: fmt.Sprintf("certificate file %s not found", bundle), missingPath: true})
+		}
+	}
+	return errs
+}
+
+// memoryErrors checks a pair of memory limits in MB
+func memoryErrors(minKey, maxKey string, minMB, maxMB int) ValidationErrors {
+	var errs ValidationErrors
+	for key, value := range map[string]int{minKey: minMB, maxKey: maxMB} {
+		if value < MinMemoryMB || value > MaxMemoryMB {
+			errs = append(errs, &FieldError{Key: key, Message: fmt.Sprintf("must be between %d and %d MB (got %d)", MinMemoryMB, MaxMemoryMB, value)})
+		}
+	}
+	if len(errs) == 0 && minMB > maxMB {
+		errs = append(errs, &FieldError{Key: minKey, Message: fmt.Sprintf("can't be above %s (%d > %d MB)", maxKey, minMB, maxMB), related: maxKey})
+	}
+	return errs
+}
+
+// ValidateMemory checks a minimum and maximum memory setting in MB
+func ValidateMemory(minMB, maxMB int) error {
+	if errs := memoryErrors("minimum memory", "maximum memory", minMB, maxMB); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateDomain checks an auth domain: a host name or IP address with an
+// optional port, without scheme or path. Empty is the default domain.
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return nil
+	}
+	if strings.Contains(domain, "://") || strings.ContainsAny(domain, "/ ") {
+		return fmt.Errorf("%q must be a domain like auth.example.com, without http:// or a path", domain)
+	}
+
+	host := domain
+	if strings.Contains(domain, ":") {
+		h, port, err := net.SplitHostPort(domain)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid domain: %v", domain, err)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%q has an invalid port", domain)
+		}
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	if len(host) > 253 {
+		return fmt.Errorf("%q is too long", domain)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("%q is not a valid domain", domain)
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return fmt.Errorf("%q contains invalid character %q", domain, r)
+			}
+		}
+	}
+	return nil
+}
+
+// repair resets invalid settings to their defaults and returns what was wrong.
+// Missing paths are reported but kept.
+func repair(cfg *Config) ValidationErrors {
+	errs := cfg.validate()
+	defaults := Default()
+	for _, err := range errs {
+		if err.missingPath {
+			fmt.Printf("Warning: Config %v\n", err)
+			continue
+		}
+		fmt.Printf("Warning: Config %v, using the default\n", err)
+		resetKey(cfg, defaults, err.Key)
+		if err.related != "" {
+			resetKey(cfg, defaults, err.related)
+		}
+	}
+	return errs
+}
+
+// resetKey copies the setting stored under a config.toml key from defaults
+func resetKey(cfg, defaults *Config, key string) {
+	target := reflect.ValueOf(cfg).Elem()
+	source := reflect.ValueOf(defaults).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		name, _, _ := strings.Cut(target.Type().Field(i).Tag.Get("toml"), ",")
+		if name == key {
+			target.Field(i).Set(source.Field(i))
+			return
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 )
 
 // IsFlatpak returns true if running inside a Flatpak sandbox
@@ -62,7 +64,6 @@ func CreateFolders() error {
 		filepath.Join(appDir, "cache"),
 		filepath.Join(appDir, "logs"),
 		filepath.Join(appDir, "crashes"),
-		filepath.Join(appDir, "UserData"),
 	}
 
 	// Create instances directory in custom location if configured, otherwise in AppData
@@ -85,6 +86,11 @@ func GetCacheDir() string {
 	return filepath.Join(GetDefaultAppDir(), "cache")
 }
 
+// GetBackupsDir returns the world backups directory
+func GetBackupsDir() string {
+	return filepath.Join(GetDefaultAppDir(), "backups")
+}
+
 // GetLogsDir returns the logs directory
 func GetLogsDir() string {
 	return filepath.Join(GetDefaultAppDir(), "logs")
@@ -157,6 +163,20 @@ func GetInstanceUserDataDir(branch string, version int) string {
 	return filepath.Join(GetInstanceDir(branch, version), "UserData")
 }
 
+// GetInstanceBuild returns the game build number installed in an instance.
+// For "latest" instances this reads version.txt; returns 0 if unknown.
+func GetInstanceBuild(branch string, version int) int {
+	data, err := os.ReadFile(filepath.Join(GetInstanceDir(branch, version), "version.txt"))
+	if err != nil {
+		return version
+	}
+	var build int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(data)), "%d", &build); err != nil {
+		return version
+	}
+	return build
+}
+
 // CreateInstanceFolders creates all necessary folders for an instance
 func CreateInstanceFolders(branch string, version int) error {
 	folders := []string{
@@ -240,7 +260,6 @@ func IsVersionInstalled(branch string, version int) bool {
 // This verifies that each version directory actually has game files
 func GetInstalledVersions(branch string) []int {
 	instancesDir := GetInstancesDir()
-	prefix := branch + "-v"
 	
 	entries, err := os.ReadDir(instancesDir)
 	if err != nil {
@@ -262,16 +281,41 @@ func GetInstalledVersions(branch string) []int {
 	
 	// Check for versioned instances (release-v4, pre-release-v8, etc.)
 	for _, entry := range entries {
-		if entry.IsDir() && len(entry.Name()) > len(prefix) && entry.Name()[:len(prefix)] == prefix {
-			versionStr := entry.Name()[len(prefix):]
-			var v int
-			if _, err := fmt.Sscanf(versionStr, "%d", &v); err == nil && v > 0 {
-				// Verify the game is actually installed in this version
-				if IsVersionInstalled(branch, v) {
-					versions = append(versions, v)
-				}
+		if !entry.IsDir() {
+			continue
+		}
+		if b, v, ok := ParseInstanceName(entry.Name()); ok && b == branch && v > 0 {
+			// Verify the game is actually installed in this version
+			if IsVersionInstalled(branch, v) {
+				versions = append(versions, v)
 			}
 		}
 	}
 	return versions
 }
+
+// Branches are the game branches instances are installed from
+var Branches = []string{"release", "pre-release"}
+
+// ParseInstanceName splits an instance folder name made by GetInstanceDir, such
+// as "release-v4" or "pre-release-latest", into its branch and version (0 for
+// latest). Folders of any other name are not instances.
+func ParseInstanceName(name string) (string, int, bool) {
+	for _, branch := range Branches {
+		rest, ok := strings.CutPrefix(name, branch+"-")
+		if !ok {
+			continue
+		}
+		if rest == "latest" {
+			return branch, 0, true
+		}
+		digits, ok := strings.CutPrefix(rest, "v")
+		if !ok || digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+			continue
+		}
+		if version, err := strconv.Atoi(digits); err == nil && version > 0 {
+			return branch, version, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,30 @@
+package env
+
+import "testing"
+
+func TestParseInstanceName(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		version int
+		ok      bool
+	}{
+		{"release-v4", "release", 4, true},
+		{"pre-release-v12", "pre-release", 12, true},
+		{"release-latest", "release", 0, true},
+		{"pre-release-latest", "pre-release", 0, true},
+		{"release-v0", "", 0, false},
+		{"release-v", "", 0, false},
+		{"release-v4a", "", 0, false},
+		{"release-v-4", "", 0, false},
+		{"beta-v4", "", 0, false},
+		{"latest", "", 0, false},
+		{"Minecraft", "", 0, false},
+	}
+	for _, tt := range tests {
+		branch, version, ok := ParseInstanceName(tt.name)
+		if branch != tt.branch || version != tt.version || ok != tt.ok {
+			t.Errorf("ParseInstanceName(%q) = %q, %d, %v, want %q, %d, %v", tt.name, branch, version, ok, tt.branch, tt.version, tt.ok)
+		}
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"runtime"
 	"sync"
 
+	"HyVanila/internal/backup"
 	"HyVanila/internal/env"
+	"HyVanila/internal/gamestore"
+	"HyVanila/internal/integrity"
 	"HyVanila/internal/java"
 	"HyVanila/internal/pwr"
 	"HyVanila/internal/pwr/butler"
+	"HyVanila/internal/storage"
 )
 
 var (
@@ -36,6 +40,13 @@ func EnsureInstalled(ctx context.Context, progress func(stage string, progress f
 		installMutex.Unlock()
 	}()
 
+	// Keep cleanup away from downloads in progress, then trim the cache once done
+	releaseCache := storage.MarkInUse(env.GetCacheDir())
+	defer func() {
+		releaseCache()
+		storage.ApplyCachePolicy()
+	}()
+
 	// Download JRE
 	if err := java.DownloadJRE(ctx, progress); err != nil {
 		return fmt.Errorf("failed to download Java Runtime: %w", err)
@@ -116,6 +127,13 @@ func EnsureInstalledVersion(ctx context.Context, versionType string, progress fu
 		installMutex.Unlock()
 	}()
 
+	// Keep cleanup away from downloads in progress, then trim the cache once done
+	releaseCache := storage.MarkInUse(env.GetCacheDir())
+	defer func() {
+		releaseCache()
+		storage.ApplyCachePolicy()
+	}()
+
 	// Download JRE
 	if err := java.DownloadJRE(ctx, progress); err != nil {
 		return fmt.Errorf("failed to download Java Runtime: %w", err)
@@ -170,6 +188,13 @@ func EnsureInstalledVersionSpecific(ctx context.Context, versionType string, ver
 		installMutex.Unlock()
 	}()
 
+	// Keep cleanup away from downloads in progress, then trim the cache once done
+	releaseCache := storage.MarkInUse(env.GetCacheDir())
+	defer func() {
+		releaseCache()
+		storage.ApplyCachePolicy()
+	}()
+
 	// Check if this specific version is already installed in instance folder
 	instanceGameDir := env.GetInstanceGameDir(versionType, version)
 	var clientPath string
@@ -219,9 +244,50 @@ func EnsureInstalledVersionSpecific(ctx context.Context, versionType string, ver
 	return nil
 }
 
+// UpdateLatestInstance installs the newest build into a branch's "-latest" instance,
+// using a pre-downloaded patch from the cache if there is one
+func UpdateLatestInstance(ctx context.Context, versionType string, progress func(stage string, progress float64, msg string, file string, speed string, down, total int64)) error {
+	// Prevent multiple simultaneous installations
+	installMutex.Lock()
+	if isInstalling {
+		installMutex.Unlock()
+		return fmt.Errorf("installation already in progress")
+	}
+	isInstalling = true
+	installMutex.Unlock()
+
+	defer func() {
+		installMutex.Lock()
+		isInstalling = false
+		installMutex.Unlock()
+	}()
+
+	releaseCache := storage.MarkInUse(env.GetCacheDir())
+	defer func() {
+		releaseCache()
+		storage.ApplyCachePolicy()
+	}()
+
+	if _, err := butler.InstallButler(ctx, progress); err != nil {
+		return fmt.Errorf("failed to install Butler tool: %w", err)
+	}
+	if err := InstallGameToInstance(ctx, versionType, 0, progress); err != nil {
+		return fmt.Errorf("failed to update game: %w", err)
+	}
+	return nil
+}
+
+// IsInstalling reports whether an installation or update is in progress
+func IsInstalling() bool {
+	installMutex.Lock()
+	defer installMutex.Unlock()
+	return isInstalling
+}
+
 // InstallGameToInstance installs the game to an instance-specific directory
 func InstallGameToInstance(ctx context.Context, versionType string, version int, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) error {
 	instanceGameDir := env.GetInstanceGameDir(versionType, version)
+	defer storage.MarkInUse(instanceGameDir)()
 
 	// For "latest" instance (version 0), we need to get the actual latest version
 	actualVersion := version
@@ -246,12 +312,32 @@ func InstallGameToInstance(ctx context.Context, versionType string, version int,
 	}
 	fmt.Printf("Patch file size: %d bytes\n", info.Size())
 
+	// Protect existing worlds before the update touches the instance
+	if backup.GetPolicy().OnUpdate && env.IsVersionInstalled(versionType, version) {
+		if progressCallback != nil {
+			progressCallback("install", 0, "Backing up worlds...", "", "", 0, 0)
+		}
+		if _, err := backup.Create(versionType, version, backup.ReasonPreUpdate, fmt.Sprintf("Before installing v%d", actualVersion)); err != nil {
+			return fmt.Errorf("failed to back up worlds before update: %w", err)
+		}
+	}
+
 	// Apply the patch to instance directory
 	if progressCallback != nil {
 		progressCallback("install", 0, "Installing game...", "", "", 0, 0)
 	}
 
-	if err := pwr.ApplyPWRToDir(ctx, pwrPath, instanceGameDir, progressCallback); err != nil {
+	// Files shared with other instances must not be patched in place
+	restoreShared, err := gamestore.Detach(instanceGameDir)
+	if err != nil {
+		return fmt.Errorf("failed to unshare game files before update: %w", err)
+	}
+
+	err = pwr.ApplyPWRToDir(ctx, pwrPath, instanceGameDir, progressCallback)
+	if restoreErr := restoreShared(); restoreErr != nil {
+		fmt.Printf("Warning: %v\n", restoreErr)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to apply game patch: %w", err)
 	}
 
@@ -275,6 +361,21 @@ func InstallGameToInstance(ctx context.Context, versionType string, version int,
 	versionFile := filepath.Join(env.GetInstanceDir(versionType, version), "version.txt")
 	os.WriteFile(versionFile, []byte(fmt.Sprintf("%d", actualVersion)), 0644)
 
+	// Record what was installed so the instance can be verified and repaired later
+	if _, err := integrity.Record(versionType, version, actualVersion, progressCallback); err != nil {
+		fmt.Printf("Warning: Failed to record install manifest: %v\n", err)
+	}
+
+	// Share identical game files with other instances
+	if gamestore.IsEnabled() {
+		if _, err := gamestore.Dedupe(instanceGameDir, progressCallback); err != nil {
+			fmt.Printf("Warning: Failed to share game files: %v\n", err)
+		}
+		if _, _, err := gamestore.CollectGarbage(); err != nil {
+			fmt.Printf("Warning: Failed to clean up game file store: %v\n", err)
+		}
+	}
+
 	if progressCallback != nil {
 		if version == 0 {
 			progressCallback("complete", 100, fmt.Sprintf("%s latest (v%d) installed successfully", versionType, actualVersion), "", "", 0, 0)
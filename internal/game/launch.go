@@ -1,6 +1,7 @@
 package game
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
@@ -13,7 +14,9 @@ import (
 
 	"HyVanila/internal/auth"
 	"HyVanila/internal/env"
+	"HyVanila/internal/java"
 	"HyVanila/internal/patcher"
+	"HyVanila/internal/storage"
 )
 
 // LaunchOptions contains options for launching the game
@@ -23,10 +26,11 @@ type LaunchOptions struct {
 	Version    int
 	OnlineMode bool   // If true, use online auth mode with patched binaries
 	AuthDomain string // Custom auth domain (empty for default)
-	JavaPath   string // Custom Java path
+	JavaPath   string // Java executable (empty for the default managed runtime)
 	MaxMemory  int    // Max memory in MB
 	MinMemory  int    // Min memory in MB
 	FullScreen bool   // Full screen mode
+	ConnectTo  string // Server address (host:port) to join on startup
 	// Callbacks
 	OnExit func() // Called when the game process exits
 }
@@ -99,36 +103,34 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 	_ = os.MkdirAll(userDataDir, 0755)
 
 	// Set up Java path
-	var jrePath string
-	jreDir := filepath.Join(baseDir, "jre")
-	if opts.JavaPath != "" {
-		jrePath = opts.JavaPath
-	} else {
-		switch runtime.GOOS {
-		case "darwin":
-			javaDir := filepath.Join(baseDir, "java")
-			javaHomeBin := filepath.Join(javaDir, "Contents", "Home", "bin")
-			
-			if _, err := os.Stat(javaHomeBin); err != nil {
-				os.RemoveAll(javaDir)
-				os.MkdirAll(filepath.Join(javaDir, "Contents", "Home"), 0755)
-				os.Symlink(filepath.Join(jreDir, "bin"), filepath.Join(javaDir, "Contents", "Home", "bin"))
-				os.Symlink(filepath.Join(jreDir, "lib"), filepath.Join(javaDir, "Contents", "Home", "lib"))
-			}
-			jrePath = filepath.Join(baseDir, "java", "Contents", "Home", "bin", "java")
-		case "windows":
-			jrePath = filepath.Join(jreDir, "bin", "java.exe")
-		default:
-			jrePath = filepath.Join(jreDir, "bin", "java")
+	jrePath := opts.JavaPath
+	if jrePath == "" {
+		var err error
+		if jrePath, err = java.GetJavaExec(); err != nil {
+			return err
 		}
 	}
 
-	if _, err := os.Stat(jrePath); err != nil {
-		return fmt.Errorf("Java not found at %s: %w", jrePath, err)
+	// Refuse runtimes the game can't start on before anything is patched
+	if _, err := java.Validate(context.Background(), jrePath); err != nil {
+		return err
 	}
+	// Keep the runtime from being replaced by an update while the game runs on it
+	releaseJava := storage.MarkInUse(java.HomeOf(jrePath))
+
+	if runtime.GOOS == "darwin" && java.IsManaged(jrePath) {
+		// The client expects a macOS JDK layout; link it to the chosen runtime
+		jreDir := java.HomeOf(jrePath)
+		javaHome := filepath.Join(baseDir, "java", "Contents", "Home")
+		if target, err := os.Readlink(filepath.Join(javaHome, "bin")); err != nil || target != filepath.Join(jreDir, "bin") {
+			os.RemoveAll(filepath.Join(baseDir, "java"))
+			os.MkdirAll(javaHome, 0755)
+			os.Symlink(filepath.Join(jreDir, "bin"), filepath.Join(javaHome, "bin"))
+			os.Symlink(filepath.Join(jreDir, "lib"), filepath.Join(javaHome, "lib"))
+		}
+		jrePath = filepath.Join(javaHome, "bin", "java")
 
-	// On macOS, sign Java runtime to avoid Gatekeeper issues
-	if runtime.GOOS == "darwin" && opts.JavaPath == "" {
+		// On macOS, sign Java runtime to avoid Gatekeeper issues
 		signMacOSBinaries(jreDir, jrePath)
 	}
 
@@ -151,6 +153,7 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 		
 		// Create patcher and patch game binaries
 		clientPatcher := patcher.NewClientPatcher(authDomain)
+		clientPatcher.SetGameVersion(opts.Branch, env.GetInstanceBuild(opts.Branch, opts.Version))
 		fmt.Println("Patching game binaries for online mode...")
 		
 		patchResult := clientPatcher.EnsurePatched(gameDir, func(msg string, percent int) {
@@ -158,6 +161,7 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 		})
 		
 		if !patchResult.Success {
+			releaseJava()
 			return fmt.Errorf("failed to patch game for online mode: %s", patchResult.Error)
 		}
 		
@@ -219,6 +223,10 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 		commonArgs = append(commonArgs, "--fullscreen")
 	}
 
+	if opts.ConnectTo != "" {
+		commonArgs = append(commonArgs, "--server", opts.ConnectTo)
+	}
+
 	// Add auth tokens if available and in authenticated mode
 	if authMode == "authenticated" && tokens != nil {
 		// When using identity token, also pass username for profile
@@ -243,19 +251,9 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 		xattrCmd := exec.Command("xattr", "-cr", appBundlePath)
 		xattrCmd.Run() // Ignore errors
 		
-		// CRITICAL: Remove .original backup files from app bundle before signing
-		// The patcher creates these and they break codesign --deep
-		macOSDir := filepath.Join(appBundlePath, "Contents", "MacOS")
-		if entries, err := os.ReadDir(macOSDir); err == nil {
-			for _, entry := range entries {
-				if strings.HasSuffix(entry.Name(), ".original") {
-					backupPath := filepath.Join(macOSDir, entry.Name())
-					fmt.Printf("Removing backup file before signing: %s\n", entry.Name())
-					os.Remove(backupPath)
-				}
-			}
-		}
-		
+		// Signing rewrites the patched client, so its manifest hash is refreshed after
+		recordSigned := patcher.TrackSigning(gameDir)
+
 		// Sign with codesign - use ad-hoc signature
 		// Must use --force to overwrite existing signature after patching
 		codesignCmd := exec.Command("codesign", 
@@ -275,6 +273,7 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 		} else {
 			fmt.Println("App signed successfully")
 		}
+		recordSigned()
 		
 		args := append([]string{appBundlePath, "--args"}, commonArgs...)
 		cmd = exec.Command("open", args...)
@@ -300,6 +299,7 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Start(); err != nil {
+		releaseJava()
 		return fmt.Errorf("failed to start game: %w", err)
 	}
 
@@ -308,6 +308,7 @@ func LaunchInstanceWithOptions(opts LaunchOptions) error {
 	
 	go func() {
 		cmd.Wait()
+		releaseJava()
 		gameProcess = nil
 		gameRunning = false
 		if opts.OnExit != nil {
@@ -405,9 +406,9 @@ func GetGameLogs() (string, error) {
 	// Try multiple log paths based on typical Hytale log locations
 	paths := []string{
 		// UserData logs
-		filepath.Join(baseDir, "UserData", "logs", "latest.log"),
-		filepath.Join(baseDir, "UserData", "logs", "game.log"),
-		filepath.Join(baseDir, "UserData", "logs", "client.log"),
+		filepath.Join(env.GetInstanceUserDataDir("release", 0), "logs", "latest.log"),
+		filepath.Join(env.GetInstanceUserDataDir("release", 0), "logs", "game.log"),
+		filepath.Join(env.GetInstanceUserDataDir("release", 0), "logs", "client.log"),
 		// Instance logs (latest)
 		filepath.Join(env.GetInstanceGameDir("release", 0), "logs", "latest.log"),
 		filepath.Join(env.GetInstanceGameDir("release", 0), "logs", "game.log"),
@@ -443,8 +444,8 @@ func GetGameLogs() (string, error) {
 	debug.WriteString("No game logs found. Checking directories:\n\n")
 	
 	checkDirs := []string{
-		filepath.Join(baseDir, "UserData"),
-		filepath.Join(baseDir, "UserData", "logs"),
+		env.GetInstanceUserDataDir("release", 0),
+		filepath.Join(env.GetInstanceUserDataDir("release", 0), "logs"),
 		env.GetInstanceDir("release", 0),
 		filepath.Join(env.GetInstanceGameDir("release", 0), "logs"),
 	}
@@ -0,0 +1,430 @@
+package gamestore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/util"
+)
+
+// minFileSize is the smallest file worth sharing; tiny files cost more in
+// bookkeeping than they save
+const minFileSize = 64 * 1024
+
+// detachedDirName is where Detach keeps shared files while a game directory is updated
+const detachedDirName = ".detached"
+
+// ErrUnsupported is returned when instances can't share files with the store,
+// e.g. because they are on a different drive than the launcher's data
+var ErrUnsupported = errors.New("the instances directory is on a different file system than the game file store")
+
+var (
+	storeMu sync.Mutex
+	enabled = true
+)
+
+// SetEnabled turns sharing of game files between instances on or off
+func SetEnabled(on bool) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	enabled = on
+}
+
+// IsEnabled reports whether game files are shared between instances
+func IsEnabled() bool {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return enabled
+}
+
+// GetStoreDir returns the directory of the content-addressed game file store
+func GetStoreDir() string {
+	return filepath.Join(env.GetDefaultAppDir(), "gamestore")
+}
+
+func getObjectsDir() string {
+	return filepath.Join(GetStoreDir(), "objects")
+}
+
+func objectPath(hash string) string {
+	return filepath.Join(getObjectsDir(), hash[:2], hash)
+}
+
+// Lookup returns the store object with the given SHA256, or "" if the store has
+// no intact copy. Objects share their data with instance files, so an object
+// changed through one of them is rejected.
+func Lookup(hash string) string {
+	if len(hash) < 2 {
+		return ""
+	}
+	path := objectPath(hash)
+	if actual, err := util.FileSHA256(path); err != nil || actual != hash {
+		return ""
+	}
+	return path
+}
+
+// DedupeResult summarizes a Dedupe run
+type DedupeResult struct {
+	Files       int   `json:"files"`
+	LinkedFiles int   `json:"linkedFiles"`
+	NewObjects  int   `json:"newObjects"`
+	SavedBytes  int64 `json:"savedBytes"`
+}
+
+// Dedupe moves the files of an installed game directory into the store and
+// replaces them with hard links to it, so identical files of other instances
+// share disk space. Files already linked are skipped, so it can be rerun.
+func Dedupe(gameDir string, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (*DedupeResult, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	var files []string
+	var total int64
+	err := filepath.WalkDir(gameDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "staging-temp" || d.Name() == detachedDirName) {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() >= minFileSize {
+			files = append(files, path)
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DedupeResult{Files: len(files)}
+	var done int64
+	lastReport := time.Time{}
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return result, err
+		}
+		if progressCallback != nil && time.Since(lastReport) > 200*time.Millisecond {
+			rel, _ := filepath.Rel(gameDir, path)
+			progressCallback("dedupe", float64(done)/float64(total)*100, "Sharing game files between instances...", rel, "", done, total)
+			lastReport = time.Now()
+		}
+		done += info.Size()
+
+		if links, err := util.LinkCount(path); err == nil && links > 1 {
+			result.LinkedFiles++
+			continue
+		}
+
+		linked, created, err := linkFile(path, info)
+		if err != nil {
+			return result, err
+		}
+		if linked {
+			result.LinkedFiles++
+			if created {
+				result.NewObjects++
+			} else {
+				result.SavedBytes += info.Size()
+			}
+		}
+	}
+
+	if progressCallback != nil {
+		progressCallback("dedupe", 100, "Game files shared", "", "", total, total)
+	}
+	fmt.Printf("Game store: linked %d/%d files in %s, saved %s\n",
+		result.LinkedFiles, result.Files, gameDir, util.FormatBytes(result.SavedBytes))
+	return result, nil
+}
+
+// linkFile makes path a hard link to its store object, adding the object if it
+// is new. It reports whether the file is now linked and whether the object was created.
+func linkFile(path string, info fs.FileInfo) (bool, bool, error) {
+	hash, err := util.FileSHA256(path)
+	if err != nil {
+		return false, false, err
+	}
+	obj := objectPath(hash)
+	if err := os.MkdirAll(filepath.Dir(obj), 0755); err != nil {
+		return false, false, err
+	}
+
+	objInfo, err := os.Stat(obj)
+	if os.IsNotExist(err) {
+		// Adopt the instance's file as the object
+		if err := os.Link(path, obj); err != nil {
+			if util.IsCrossDevice(err) {
+				return false, false, ErrUnsupported
+			}
+			return false, false, err
+		}
+		return true, true, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if objInfo.Size() != info.Size() {
+		return false, false, fmt.Errorf("store object %s is corrupt (size mismatch)", hash)
+	}
+
+	// Links share one mode, so keep every permission any instance needs (e.g. executables)
+	if objInfo.Mode().Perm()|info.Mode().Perm() != objInfo.Mode().Perm() {
+		os.Chmod(obj, objInfo.Mode().Perm()|info.Mode().Perm())
+	}
+
+	tmpPath := path + ".linking"
+	os.Remove(tmpPath)
+	if err := os.Link(obj, tmpPath); err != nil {
+		if util.IsCrossDevice(err) {
+			return false, false, ErrUnsupported
+		}
+		return false, false, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return false, false, err
+	}
+	return true, false, nil
+}
+
+// Detach moves the shared files of a game directory aside so an update writes
+// new files in their place instead of patching the shared data, which would
+// change other instances too. Moving is a rename, so nothing is copied. The
+// returned function puts back the files the update didn't replace and must be
+// called once it is done, whether it succeeded or not. Files left aside by an
+// interrupted update are put back first.
+func Detach(gameDir string) (func() error, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if err := restoreDetached(gameDir); err != nil {
+		return nil, err
+	}
+
+	heldDir := filepath.Join(gameDir, detachedDirName)
+	moved := 0
+	err := filepath.WalkDir(gameDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() && (d.Name() == "staging-temp" || path == heldDir) {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if links, err := util.LinkCount(path); err != nil || links <= 1 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(gameDir, path)
+		if err != nil {
+			return err
+		}
+		held := filepath.Join(heldDir, rel)
+		if err := os.MkdirAll(filepath.Dir(held), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(path, held); err != nil {
+			return err
+		}
+		moved++
+		return nil
+	})
+	if err != nil {
+		restoreDetached(gameDir)
+		return nil, err
+	}
+	if moved > 0 {
+		fmt.Printf("Game store: moved %d shared files aside in %s\n", moved, gameDir)
+	}
+	return func() error {
+		storeMu.Lock()
+		defer storeMu.Unlock()
+		return restoreDetached(gameDir)
+	}, nil
+}
+
+// restoreDetached moves the files Detach set aside back to every path that is
+// still empty and drops the rest, which the update replaced
+func restoreDetached(gameDir string) error {
+	heldDir := filepath.Join(gameDir, detachedDirName)
+	if _, err := os.Stat(heldDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	restored := 0
+	err := filepath.WalkDir(heldDir, func(held string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(heldDir, held)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(gameDir, rel)
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(held, path); err != nil {
+			return err
+		}
+		restored++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put back shared game files: %w", err)
+	}
+	if restored > 0 {
+		fmt.Printf("Game store: put back %d shared files in %s\n", restored, gameDir)
+	}
+	return os.RemoveAll(heldDir)
+}
+
+// CollectGarbage deletes store objects no instance links to anymore and
+// returns how many were removed and the bytes freed
+func CollectGarbage() (int, int64, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	removed := 0
+	var freed int64
+	err := filepath.WalkDir(getObjectsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		links, err := util.LinkCount(path)
+		if err != nil || links > 1 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		freed += info.Size()
+		return nil
+	})
+	if removed > 0 {
+		fmt.Printf("Game store: removed %d unreferenced objects (%s)\n", removed, util.FormatBytes(freed))
+	}
+	return removed, freed, err
+}
+
+// InstanceUsage is the disk usage of one instance
+type InstanceUsage struct {
+	Name string `json:"name"`
+	// ApparentSize is the total size of the instance's files
+	ApparentSize int64 `json:"apparentSize"`
+	// SharedSize is the part stored once in the game file store
+	SharedSize int64 `json:"sharedSize"`
+	// ExclusiveSize is the disk space only this instance uses
+	ExclusiveSize int64 `json:"exclusiveSize"`
+	Files         int   `json:"files"`
+	SharedFiles   int   `json:"sharedFiles"`
+}
+
+// StorageReport compares apparent and real disk usage of all instances
+type StorageReport struct {
+	Enabled   bool            `json:"enabled"`
+	Instances []InstanceUsage `json:"instances"`
+	// StoreSize is the disk space of the shared store objects
+	StoreSize    int64 `json:"storeSize"`
+	StoreObjects int   `json:"storeObjects"`
+	// UnreferencedSize is store space that garbage collection would free
+	UnreferencedSize int64 `json:"unreferencedSize"`
+	// ApparentTotal is what the instances would take without sharing
+	ApparentTotal int64 `json:"apparentTotal"`
+	// ActualTotal is the disk space instances and store really use
+	ActualTotal int64 `json:"actualTotal"`
+	SavedBytes  int64 `json:"savedBytes"`
+}
+
+// GetStorageReport measures real versus apparent disk usage per instance
+func GetStorageReport() (*StorageReport, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	report := &StorageReport{Enabled: enabled, Instances: []InstanceUsage{}}
+	names, err := env.ListInstances()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		usage := InstanceUsage{Name: name}
+		filepath.WalkDir(filepath.Join(env.GetInstancesDir(), name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			usage.Files++
+			usage.ApparentSize += info.Size()
+			if links, err := util.LinkCount(path); err == nil && links > 1 {
+				usage.SharedFiles++
+				usage.SharedSize += info.Size()
+			} else {
+				usage.ExclusiveSize += info.Size()
+			}
+			return nil
+		})
+		report.Instances = append(report.Instances, usage)
+		report.ApparentTotal += usage.ApparentSize
+		report.ActualTotal += usage.ExclusiveSize
+	}
+
+	filepath.WalkDir(getObjectsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		report.StoreObjects++
+		report.StoreSize += info.Size()
+		if links, err := util.LinkCount(path); err == nil && links <= 1 {
+			report.UnreferencedSize += info.Size()
+		}
+		return nil
+	})
+	report.ActualTotal += report.StoreSize
+	report.SavedBytes = report.ApparentTotal - report.ActualTotal
+	return report, nil
+}
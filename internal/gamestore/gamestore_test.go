@@ -0,0 +1,92 @@
+package gamestore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/util"
+)
+
+// newInstances creates two game directories holding the same files and shares them
+func newInstances(t *testing.T, files ...string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	if err := env.CreateFolders(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gameDirs []string
+	for _, name := range []string{"release-v1", "release-v2"} {
+		gameDir := filepath.Join(env.GetInstancesDir(), name, "game")
+		for i, file := range files {
+			path := filepath.Join(gameDir, file)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, bytes.Repeat([]byte{byte(i + 1)}, minFileSize), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := Dedupe(gameDir, nil); err != nil {
+			t.Fatal(err)
+		}
+		gameDirs = append(gameDirs, gameDir)
+	}
+	return gameDirs[0], gameDirs[1]
+}
+
+func TestDetachOnlyUnsharesReplacedFiles(t *testing.T) {
+	updated, other := newInstances(t, "Client/changed.dat", "Client/kept.dat")
+
+	restore, err := Detach(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The update writes one file and leaves the other alone
+	changed := filepath.Join(updated, "Client", "changed.dat")
+	if err := os.WriteFile(changed, []byte("new build"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(other, "Client", "changed.dat")); err != nil || !bytes.Equal(data, bytes.Repeat([]byte{1}, minFileSize)) {
+		t.Errorf("the update changed another instance: %v", err)
+	}
+	if data, err := os.ReadFile(changed); err != nil || string(data) != "new build" {
+		t.Errorf("updated file = %q, %v", data, err)
+	}
+	kept := filepath.Join(updated, "Client", "kept.dat")
+	if links, err := util.LinkCount(kept); err != nil || links < 2 {
+		t.Errorf("untouched file is no longer shared: %d links, %v", links, err)
+	}
+	if _, err := os.Stat(filepath.Join(updated, detachedDirName)); !os.IsNotExist(err) {
+		t.Errorf("%s left behind: %v", detachedDirName, err)
+	}
+}
+
+func TestDetachPutsBackInterruptedUpdate(t *testing.T) {
+	updated, _ := newInstances(t, "Client/kept.dat")
+	if _, err := Detach(updated); err != nil {
+		t.Fatal(err)
+	}
+
+	// The launcher closed before the update finished
+	restore, err := Detach(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(updated, "Client", "kept.dat")); err != nil {
+		t.Errorf("file set aside by the interrupted update is missing: %v", err)
+	}
+}
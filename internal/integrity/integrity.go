@@ -0,0 +1,517 @@
+package integrity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/gamestore"
+	"HyVanila/internal/patcher"
+	"HyVanila/internal/pwr"
+	"HyVanila/internal/pwr/butler"
+	"HyVanila/internal/storage"
+	"HyVanila/internal/util"
+)
+
+// manifestFileName is the install manifest stored in each instance directory
+// (next to version.txt, so it is not itself part of the game files)
+const manifestFileName = "install_manifest.json"
+
+// refDirName is the folder a reference copy of the game is applied to during repair
+const refDirName = ".repair-ref"
+
+// Manifest records the expected game files of an instance at install time
+type Manifest struct {
+	Branch    string `json:"branch"`
+	Version   int    `json:"version"`
+	Build     int    `json:"build"`
+	CreatedAt string `json:"createdAt"` // ISO 8601 format
+	Files     []File `json:"files"`
+}
+
+// File is one expected game file. Path is relative to the game directory.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	Mode   uint32 `json:"mode"`
+}
+
+// Issue is a game file that differs from the manifest
+type Issue struct {
+	Path string `json:"path"`
+	// Kind is one of: missing, modified, extra
+	Kind           string `json:"kind"`
+	ExpectedSHA256 string `json:"expectedSha256,omitempty"`
+	CurrentSHA256  string `json:"currentSha256,omitempty"`
+}
+
+// Report is the result of verifying or repairing an instance
+type Report struct {
+	Branch  string `json:"branch"`
+	Version int    `json:"version"`
+	Build   int    `json:"build"`
+	// Status is one of: ok, damaged, no-manifest
+	Status       string   `json:"status"`
+	CheckedFiles int      `json:"checkedFiles"`
+	Missing      []Issue  `json:"missing"`
+	Modified     []Issue  `json:"modified"`
+	Extra        []Issue  `json:"extra"`
+	// Patched lists files that differ only because the auth patcher changed them
+	Patched []string `json:"patched,omitempty"`
+	// Repaired lists the files a repair replaced or removed
+	Repaired []string `json:"repaired,omitempty"`
+}
+
+// ProgressFunc reports progress in the launcher's progress format
+type ProgressFunc func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)
+
+func getManifestPath(branch string, version int) string {
+	return filepath.Join(env.GetInstanceDir(branch, version), manifestFileName)
+}
+
+// LoadManifest reads the install manifest of an instance (nil if there is none)
+func LoadManifest(branch string, version int) (*Manifest, error) {
+	data, err := os.ReadFile(getManifestPath(branch, version))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("install manifest is corrupt: %w", err)
+	}
+	return &manifest, nil
+}
+
+func saveManifest(branch string, version int, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := getManifestPath(branch, version)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Record hashes the game files of a freshly installed instance into its install manifest
+func Record(branch string, version int, build int, progress ProgressFunc) (*Manifest, error) {
+	progress = orNoop(progress)
+	files, err := scan(env.GetInstanceGameDir(branch, version), progress, "Recording game files...")
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Branch:    branch,
+		Version:   version,
+		Build:     build,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Files:     files,
+	}
+	if err := saveManifest(branch, version, manifest); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Recorded install manifest for %s v%d (%d files)\n", branch, version, len(files))
+	return manifest, nil
+}
+
+// Verify checks an instance's game files against its install manifest
+func Verify(branch string, version int, progress ProgressFunc) (*Report, error) {
+	progress = orNoop(progress)
+	manifest, err := LoadManifest(branch, version)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Branch: branch, Version: version, Build: env.GetInstanceBuild(branch, version)}
+	if manifest == nil {
+		report.Status = "no-manifest"
+		return report, nil
+	}
+	if err := compare(env.GetInstanceGameDir(branch, version), manifest, report, progress); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Repair restores missing and modified game files of an instance. Broken files
+// are first copied from intact local copies: the game file store or other
+// instances that installed the same file. Only if some are left, or the instance
+// has no manifest, is the build's full patch (from the cache if kept there)
+// applied to a reference folder to copy the rest from; the instance then gets its
+// manifest from the reference. Extra files are removed only if removeExtra is set.
+func Repair(ctx context.Context, branch string, version int, removeExtra bool, progress ProgressFunc) (*Report, error) {
+	instanceDir := env.GetInstanceDir(branch, version)
+	gameDir := env.GetInstanceGameDir(branch, version)
+	progress = orNoop(progress)
+	defer storage.MarkInUse(instanceDir)()
+
+	report, err := Verify(branch, version, progress)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == "ok" {
+		if removeExtra {
+			removeExtraFiles(gameDir, report)
+		}
+		progress("complete", 100, "All game files are intact", "", "", 0, 0)
+		return report, nil
+	}
+
+	if report.Status == "damaged" {
+		repairLocally(gameDir, report, newLocalSources(branch, version), progress)
+		if len(report.Missing) == 0 && len(report.Modified) == 0 {
+			if removeExtra {
+				removeExtraFiles(gameDir, report)
+			}
+			return repaired(report, progress), nil
+		}
+		fmt.Printf("No local copy of %d broken files, rebuilding them from the game patch\n", len(report.Missing)+len(report.Modified))
+	}
+	localRepairs := report.Repaired
+
+	build := env.GetInstanceBuild(branch, version)
+	if build <= 0 {
+		return nil, fmt.Errorf("the installed build of %s v%d is unknown; reinstall the instance instead", branch, version)
+	}
+
+	if _, err := butler.InstallButler(ctx, progress); err != nil {
+		return nil, fmt.Errorf("failed to install Butler tool: %w", err)
+	}
+	releaseCache := storage.MarkInUse(env.GetCacheDir())
+	pwrPath, err := pwr.DownloadPWR(ctx, branch, 0, build, progress)
+	if err != nil {
+		releaseCache()
+		return nil, fmt.Errorf("failed to download game patch: %w", err)
+	}
+
+	refDir := filepath.Join(instanceDir, refDirName)
+	os.RemoveAll(refDir)
+	defer os.RemoveAll(refDir)
+	err = pwr.ApplyPWRToDir(ctx, pwrPath, refDir, progress)
+	releaseCache()
+	storage.ApplyCachePolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build reference copy: %w", err)
+	}
+
+	// The reference is authoritative, so it also replaces a missing or stale manifest
+	refFiles, err := scan(refDir, progress, "Checking reference files...")
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{Branch: branch, Version: version, Build: build, CreatedAt: time.Now().Format(time.RFC3339), Files: refFiles}
+
+	report = &Report{Branch: branch, Version: version, Build: build, Repaired: localRepairs}
+	if err := compare(gameDir, manifest, report, progress); err != nil {
+		return nil, err
+	}
+
+	broken := append(append([]Issue{}, report.Missing...), report.Modified...)
+	for i, issue := range broken {
+		progress("repair", float64(i)/float64(len(broken))*100, "Repairing game files...", issue.Path, "", int64(i), int64(len(broken)))
+		if err := replaceFile(filepath.Join(refDir, filepath.FromSlash(issue.Path)), filepath.Join(gameDir, filepath.FromSlash(issue.Path))); err != nil {
+			return nil, fmt.Errorf("failed to repair %s: %w", issue.Path, err)
+		}
+		// A patched file replaced by the original has to be patched again on launch
+		if err := patcher.ForgetPatchedFile(gameDir, issue.Path); err != nil {
+			fmt.Printf("Warning: Failed to update patch state of %s: %v\n", issue.Path, err)
+		}
+		report.Repaired = append(report.Repaired, issue.Path)
+	}
+	if removeExtra {
+		removeExtraFiles(gameDir, report)
+	}
+
+	if err := saveManifest(branch, version, manifest); err != nil {
+		return nil, err
+	}
+	return repaired(report, progress), nil
+}
+
+// repaired marks a report as repaired and reports it
+func repaired(report *Report, progress ProgressFunc) *Report {
+	report.Missing, report.Modified = nil, nil
+	report.Status = "ok"
+	progress("complete", 100, fmt.Sprintf("Repaired %d game files", len(report.Repaired)), "", "", 0, 0)
+	fmt.Printf("Repaired %s v%d: %d files replaced or removed\n", report.Branch, report.Version, len(report.Repaired))
+	return report
+}
+
+// localSources finds intact copies of game files on disk, so repairs don't have
+// to download them
+type localSources struct {
+	// others maps a SHA256 to the files of other instances that had it when installed
+	others map[string][]string
+}
+
+func newLocalSources(branch string, version int) *localSources {
+	sources := &localSources{others: make(map[string][]string)}
+	for _, b := range env.Branches {
+		for _, v := range env.GetInstalledVersions(b) {
+			if b == branch && v == version {
+				continue
+			}
+			manifest, err := LoadManifest(b, v)
+			if err != nil || manifest == nil {
+				continue
+			}
+			gameDir := env.GetInstanceGameDir(b, v)
+			for _, f := range manifest.Files {
+				sources.others[f.SHA256] = append(sources.others[f.SHA256], filepath.Join(gameDir, filepath.FromSlash(f.Path)))
+			}
+		}
+	}
+	return sources
+}
+
+// find returns an intact copy of a file with the given SHA256, or "" if there is none
+func (s *localSources) find(hash string) string {
+	if path := gamestore.Lookup(hash); path != "" {
+		return path
+	}
+	for _, path := range s.others[hash] {
+		if actual, err := util.FileSHA256(path); err == nil && actual == hash {
+			return path
+		}
+	}
+	return ""
+}
+
+// repairLocally replaces broken files with intact local copies; the report keeps
+// the issues no copy was found for
+func repairLocally(gameDir string, report *Report, sources *localSources, progress ProgressFunc) {
+	total := len(report.Missing) + len(report.Modified)
+	done := 0
+	repair := func(issues []Issue) []Issue {
+		left := []Issue{}
+		for _, issue := range issues {
+			progress("repair", float64(done)/float64(total)*100, "Repairing game files...", issue.Path, "", int64(done), int64(total))
+			done++
+			source := sources.find(issue.ExpectedSHA256)
+			if source == "" {
+				left = append(left, issue)
+				continue
+			}
+			if err := replaceFile(source, filepath.Join(gameDir, filepath.FromSlash(issue.Path))); err != nil {
+				fmt.Printf("Warning: Failed to repair %s from %s: %v\n", issue.Path, source, err)
+				left = append(left, issue)
+				continue
+			}
+			if err := patcher.ForgetPatchedFile(gameDir, issue.Path); err != nil {
+				fmt.Printf("Warning: Failed to update patch state of %s: %v\n", issue.Path, err)
+			}
+			report.Repaired = append(report.Repaired, issue.Path)
+		}
+		return left
+	}
+	report.Missing = repair(report.Missing)
+	report.Modified = repair(report.Modified)
+}
+
+// removeExtraFiles deletes the files the report lists as extra
+func removeExtraFiles(gameDir string, report *Report) {
+	for _, issue := range report.Extra {
+		if err := os.Remove(filepath.Join(gameDir, filepath.FromSlash(issue.Path))); err == nil {
+			report.Repaired = append(report.Repaired, issue.Path)
+		}
+	}
+	report.Extra = []Issue{}
+}
+
+// compare fills the report with the differences between a game directory and a manifest
+func compare(gameDir string, manifest *Manifest, report *Report, progress ProgressFunc) error {
+	report.Missing, report.Modified, report.Extra = []Issue{}, []Issue{}, []Issue{}
+
+	patched := make(map[string]patcher.PatchedFile)
+	if files, err := patcher.PatchedFiles(gameDir); err == nil {
+		for _, f := range files {
+			patched[f.Path] = f
+		}
+	}
+
+	var total int64
+	for _, f := range manifest.Files {
+		total += f.Size
+	}
+
+	expected := make(map[string]bool, len(manifest.Files))
+	var done int64
+	lastReport := time.Time{}
+	for _, f := range manifest.Files {
+		expected[f.Path] = true
+		if time.Since(lastReport) > 200*time.Millisecond {
+			progress("verify", percent(done, total), "Verifying game files...", f.Path, "", done, total)
+			lastReport = time.Now()
+		}
+		done += f.Size
+		report.CheckedFiles++
+
+		path := filepath.Join(gameDir, filepath.FromSlash(f.Path))
+		info, err := os.Stat(path)
+		if err != nil {
+			report.Missing = append(report.Missing, Issue{Path: f.Path, Kind: "missing", ExpectedSHA256: f.SHA256})
+			continue
+		}
+		hash := ""
+		if _, isPatched := patched[f.Path]; info.Size() == f.Size || isPatched {
+			if hash, err = util.FileSHA256(path); err != nil {
+				return err
+			}
+		}
+		switch {
+		case hash == f.SHA256:
+		case hash != "" && patched[f.Path].Matches(hash):
+			report.Patched = append(report.Patched, f.Path)
+		default:
+			report.Modified = append(report.Modified, Issue{Path: f.Path, Kind: "modified", ExpectedSHA256: f.SHA256, CurrentSHA256: hash})
+		}
+	}
+
+	err := filepath.WalkDir(gameDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(gameDir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if ignored(filepath.ToSlash(rel), d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !expected[filepath.ToSlash(rel)] {
+			report.Extra = append(report.Extra, Issue{Path: filepath.ToSlash(rel), Kind: "extra"})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(report.Missing) > 0 || len(report.Modified) > 0 {
+		report.Status = "damaged"
+	} else {
+		report.Status = "ok"
+	}
+	progress("verify", 100, "Verification complete", "", "", total, total)
+	return nil
+}
+
+// scan hashes every game file below dir, sorted by path
+func scan(dir string, progress ProgressFunc, message string) ([]File, error) {
+	var paths []string
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if ignored(filepath.ToSlash(rel), d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(paths))
+	var done int64
+	lastReport := time.Time{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		if time.Since(lastReport) > 200*time.Millisecond {
+			progress("verify", percent(done, total), message, rel, "", done, total)
+			lastReport = time.Now()
+		}
+		hash, err := util.FileSHA256(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Path: filepath.ToSlash(rel), Size: info.Size(), SHA256: hash, Mode: uint32(info.Mode().Perm())})
+		done += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// ignored reports whether a path in the game directory belongs to the launcher
+// or the game's runtime output rather than the installed game
+func ignored(rel string, d fs.DirEntry) bool {
+	name := d.Name()
+	if d.IsDir() {
+		return name == "staging-temp" || name == "logs" || name == "_CodeSignature" || name == ".patch_backups" || name == ".detached"
+	}
+	if name == ".patch_manifest.json" || name == ".installing" {
+		return true
+	}
+	for _, suffix := range []string{".patched_custom", ".original", ".tmp", ".patching", ".linking", ".detaching", ".relocating"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceFile copies src over dst via a temp file, breaking any hard link dst had
+func replaceFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	tmpPath := dst + ".tmp"
+	if err := util.CopyFile(src, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	os.Chmod(tmpPath, info.Mode().Perm())
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// orNoop returns progress, or a callback that ignores updates if it is nil
+func orNoop(progress ProgressFunc) ProgressFunc {
+	if progress == nil {
+		return func(string, float64, string, string, string, int64, int64) {}
+	}
+	return progress
+}
+
+func percent(done, total int64) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(done) / float64(total) * 100
+}
@@ -0,0 +1,52 @@
+package integrity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+)
+
+// installGame lays out an instance with a client and records its install manifest
+func installGame(t *testing.T, branch string, version int, client string) string {
+	t.Helper()
+	gameDir := env.GetInstanceGameDir(branch, version)
+	clientPath := filepath.Join(gameDir, "Client", "HytaleClient")
+	if err := os.MkdirAll(filepath.Dir(clientPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clientPath, []byte(client), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Record(branch, version, 7, nil); err != nil {
+		t.Fatal(err)
+	}
+	return clientPath
+}
+
+func TestRepairFromAnotherInstance(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+
+	installGame(t, "release", 1, "client build 7")
+	broken := installGame(t, "release", 2, "client build 7")
+	if err := os.WriteFile(broken, []byte("corrupted data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// No butler or game patch is available here, so only a local copy can repair it
+	report, err := Repair(context.Background(), "release", 2, false, nil)
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if report.Status != "ok" || len(report.Repaired) != 1 {
+		t.Errorf("Repair = %+v", report)
+	}
+	if data, err := os.ReadFile(broken); err != nil || string(data) != "client build 7" {
+		t.Errorf("client = %q, %v", data, err)
+	}
+}
@@ -8,39 +8,38 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"HyVanila/internal/env"
+	"HyVanila/internal/tools"
 	"HyVanila/internal/util"
 	"HyVanila/internal/util/download"
 )
 
-// JREPlatform represents a JRE download for a specific platform
-type JREPlatform struct {
-	URL    string `json:"url"`
-	SHA256 string `json:"sha256"`
-}
+// adoptiumAssetsURL lists the latest Temurin build of a feature release
+const adoptiumAssetsURL = "https://api.adoptium.net/v3/assets/latest/%d/hotspot?architecture=%s&image_type=jre&os=%s&vendor=eclipse"
 
-// JREJSON represents the JRE configuration
-type JREJSON struct {
-	Version     string                            `json:"version"`
-	DownloadURL map[string]map[string]JREPlatform `json:"download_url"`
-}
+// DefaultMajor is the Java release installed for the game unless an instance picks another
+const DefaultMajor = RequiredMajor
 
-const (
-	// Use Adoptium Temurin JRE directly instead of referencing another launcher
-	jreConfigURL = "https://raw.githubusercontent.com/7osteradev/HyVanila/main/jre.json"
-	jreVersion   = "25"
-)
+// release is a downloadable Temurin JRE build
+type release struct {
+	Version string // Temurin release, e.g. "25.0.1+8"
+	URL     string
+	SHA256  string
+}
 
-// DownloadJRE downloads the Java Runtime Environment
+// DownloadJRE makes sure the default Java runtime is installed and, at most once
+// per check interval, on the build pinned in tools.json. An installed runtime keeps
+// being used if the update fails; a game running on it keeps its release until
+// it closes.
 func DownloadJRE(ctx context.Context, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) error {
-	jreDir := env.GetJREDir()
-	javaBin := getJavaBinaryName()
-	javaPath := filepath.Join(jreDir, javaBin)
+	migrateLegacyJRE()
 
-	// Check if JRE already exists
-	if _, err := os.Stat(javaPath); err == nil {
+	dir := runtimeHome(DefaultMajor)
+	info := readManagedInfo(dir)
+	ready := func() error {
 		fmt.Println("Java Runtime already installed")
 		if progressCallback != nil {
 			progressCallback("jre", 100, "Java Runtime ready", "", "", 0, 0)
@@ -48,113 +47,77 @@ func DownloadJRE(ctx context.Context, progressCallback func(stage string, progre
 		return nil
 	}
 
-	if progressCallback != nil {
-		progressCallback("jre", 0, "Downloading Java Runtime...", "", "", 0, 0)
-	}
+	if info != nil {
+		// Releases an update left behind for a running game go once it has closed
+		installMu.Lock()
+		pruneRuntimes(DefaultMajor)
+		installMu.Unlock()
 
-	// Fetch JRE config
-	jreConfig, err := fetchJREConfig(ctx)
-	if err != nil {
-		// Fall back to default Adoptium URL
-		return downloadAdoptiumJRE(ctx, jreDir, progressCallback)
-	}
-
-	// Get platform-specific URL
-	osName := runtime.GOOS
-	arch := runtime.GOARCH
-	
-	if osName == "darwin" {
-		osName = "macos"
-	}
-	
-	if arch == "amd64" {
-		arch = "x64"
-	}
-
-	platform, ok := jreConfig.DownloadURL[osName]
-	if !ok {
-		return downloadAdoptiumJRE(ctx, jreDir, progressCallback)
-	}
-
-	archConfig, ok := platform[arch]
-	if !ok {
-		return downloadAdoptiumJRE(ctx, jreDir, progressCallback)
-	}
-
-	// Download JRE
-	archiveExt := ".tar.gz"
-	if runtime.GOOS == "windows" {
-		archiveExt = ".zip"
-	}
-
-	archivePath := filepath.Join(env.GetCacheDir(), "jre"+archiveExt)
-
-	if err := download.DownloadWithProgress(archivePath, archConfig.URL, "jre", 0.8, progressCallback); err != nil {
-		return fmt.Errorf("failed to download JRE: %w", err)
-	}
-
-	// Verify checksum
-	if archConfig.SHA256 != "" {
-		if progressCallback != nil {
-			progressCallback("jre", 85, "Verifying checksum...", "", "", 0, 0)
+		if !tools.CheckDue(ManagedID(DefaultMajor)) {
+			return ready()
 		}
-		if err := util.VerifySHA256(archivePath, archConfig.SHA256); err != nil {
-			os.Remove(archivePath)
-			return fmt.Errorf("JRE checksum verification failed: %w", err)
+		pinned, err := pinnedRelease()
+		switch {
+		case err != nil:
+			fmt.Printf("Warning: Could not check for Java Runtime updates: %v\n", err)
+			return ready()
+		case pinned.Version == info.Release:
+			recordRuntime(dir, info)
+			return ready()
 		}
+		fmt.Printf("Updating Java Runtime %s to %s\n", info.Release, pinned.Version)
 	}
 
-	// Extract
-	if progressCallback != nil {
-		progressCallback("jre", 90, "Extracting Java Runtime...", "", "", 0, 0)
-	}
-
-	if err := util.ExtractArchive(archivePath, jreDir); err != nil {
-		return fmt.Errorf("failed to extract JRE: %w", err)
-	}
-
-	// Cleanup
-	os.Remove(archivePath)
-
-	// Find and move java binary to expected location
-	if err := normalizeJREStructure(jreDir); err != nil {
-		return fmt.Errorf("failed to normalize JRE structure: %w", err)
-	}
-
-	if progressCallback != nil {
-		progressCallback("jre", 100, "Java Runtime installed", "", "", 0, 0)
+	if _, err := InstallRuntime(ctx, DefaultMajor, progressCallback); err != nil {
+		if info == nil {
+			return err
+		}
+		fmt.Printf("Warning: Could not update Java Runtime, keeping %s: %v\n", info.Release, err)
+		return ready()
 	}
-
 	return nil
 }
 
-func fetchJREConfig(ctx context.Context) (*JREJSON, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", jreConfigURL, nil)
-	if err != nil {
-		return nil, err
+// resolveRelease finds the build to install for a Java feature release: the one
+// pinned in tools.json for the default release, otherwise Adoptium's latest with
+// the checksum the Adoptium API lists for it. The default release is only ever
+// installed from its pin.
+func resolveRelease(ctx context.Context, major int) (*release, error) {
+	if major == DefaultMajor {
+		rel, err := pinnedRelease()
+		if err != nil {
+			return nil, fmt.Errorf("no pinned Java Runtime %d for this platform: %w", major, err)
+		}
+		return rel, nil
 	}
 
-	resp, err := client.Do(req)
+	rel, err := adoptiumRelease(ctx, major)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch JRE config: %d", resp.StatusCode)
+	if rel.SHA256 == "" {
+		return nil, fmt.Errorf("no checksum available for Java %s, refusing to install it", rel.Version)
 	}
+	return rel, nil
+}
+
+// pinnedRelease returns this platform's build of the default runtime from tools.json
+func pinnedRelease() (*release, error) {
+	return pinnedReleaseFor(runtime.GOOS, runtime.GOARCH)
+}
 
-	var config JREJSON
-	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+// pinnedReleaseFor returns the build of the default runtime for an OS and arch
+func pinnedReleaseFor(osName, arch string) (*release, error) {
+	pin, err := tools.PinFor(ManagedID(DefaultMajor), osName, arch)
+	if err != nil {
 		return nil, err
 	}
-
-	return &config, nil
+	return &release{Version: pin.Version, URL: pin.URL, SHA256: pin.SHA256}, nil
 }
 
-func downloadAdoptiumJRE(ctx context.Context, jreDir string, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) error {
+// adoptiumRelease asks the Adoptium API for the latest Temurin JRE of a feature release
+func adoptiumRelease(ctx context.Context, major int) (*release, error) {
 	osName := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -168,41 +131,42 @@ func downloadAdoptiumJRE(ctx context.Context, jreDir string, progressCallback fu
 		osName = "mac"
 	}
 
-	archiveType := "tar.gz"
-	if osName == "windows" {
-		archiveType = "zip"
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(adoptiumAssetsURL, major, arch, osName), nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("User-Agent", download.UserAgent)
 
-	url := fmt.Sprintf(
-		"https://api.adoptium.net/v3/binary/latest/%s/ga/%s/%s/jre/hotspot/normal/eclipse?project=jdk",
-		jreVersion, osName, arch,
-	)
-
-	archivePath := filepath.Join(env.GetCacheDir(), "jre."+archiveType)
-
-	if err := download.DownloadWithProgress(archivePath, url, "jre", 0.8, progressCallback); err != nil {
-		return fmt.Errorf("failed to download JRE from Adoptium: %w", err)
+	resp, err := download.NewClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Adoptium: %w", err)
 	}
-
-	if progressCallback != nil {
-		progressCallback("jre", 90, "Extracting Java Runtime...", "", "", 0, 0)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query Adoptium: HTTP %d", resp.StatusCode)
 	}
 
-	if err := util.ExtractArchive(archivePath, jreDir); err != nil {
-		return fmt.Errorf("failed to extract JRE: %w", err)
+	var assets []struct {
+		Binary struct {
+			Package struct {
+				Link     string `json:"link"`
+				Checksum string `json:"checksum"`
+			} `json:"package"`
+		} `json:"binary"`
+		ReleaseName string `json:"release_name"`
 	}
-
-	os.Remove(archivePath)
-
-	if err := normalizeJREStructure(jreDir); err != nil {
-		return fmt.Errorf("failed to normalize JRE structure: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(&assets); err != nil {
+		return nil, fmt.Errorf("failed to parse Adoptium response: %w", err)
 	}
-
-	if progressCallback != nil {
-		progressCallback("jre", 100, "Java Runtime installed", "", "", 0, 0)
+	if len(assets) == 0 || assets[0].Binary.Package.Link == "" {
+		return nil, fmt.Errorf("Adoptium has no Java %d JRE for %s/%s", major, osName, arch)
 	}
 
-	return nil
+	return &release{
+		Version: strings.TrimPrefix(assets[0].ReleaseName, "jdk-"),
+		URL:     assets[0].Binary.Package.Link,
+		SHA256:  assets[0].Binary.Package.Checksum,
+	}, nil
 }
 
 func normalizeJREStructure(jreDir string) error {
@@ -215,7 +179,7 @@ func normalizeJREStructure(jreDir string) error {
 	// If there's a single directory, move its contents up
 	if len(entries) == 1 && entries[0].IsDir() {
 		subDir := filepath.Join(jreDir, entries[0].Name())
-		
+
 		// On macOS, the structure is different
 		if runtime.GOOS == "darwin" {
 			contentsDir := filepath.Join(subDir, "Contents", "Home")
@@ -233,7 +197,7 @@ func normalizeJREStructure(jreDir string) error {
 		for _, entry := range subEntries {
 			oldPath := filepath.Join(subDir, entry.Name())
 			newPath := filepath.Join(jreDir, entry.Name())
-			
+
 			if err := os.Rename(oldPath, newPath); err != nil {
 				// Try copy instead
 				if entry.IsDir() {
@@ -262,11 +226,10 @@ func getJavaBinaryName() string {
 	return filepath.Join("bin", "java")
 }
 
-// GetJavaExec returns the path to the Java executable
+// GetJavaExec returns the path to the Java executable of the default runtime
 func GetJavaExec() (string, error) {
-	jreDir := env.GetJREDir()
-	javaBin := getJavaBinaryName()
-	javaPath := filepath.Join(jreDir, javaBin)
+	migrateLegacyJRE()
+	javaPath := filepath.Join(runtimeHome(DefaultMajor), getJavaBinaryName())
 
 	if _, err := os.Stat(javaPath); err != nil {
 		return "", fmt.Errorf("Java not found at %s", javaPath)
@@ -279,3 +242,14 @@ func GetJavaExec() (string, error) {
 
 	return javaPath, nil
 }
+
+// HomeOf returns the runtime folder of a Java executable (the parent of bin)
+func HomeOf(javaPath string) string {
+	return filepath.Dir(filepath.Dir(javaPath))
+}
+
+// IsManaged reports whether a Java executable belongs to a launcher-installed runtime
+func IsManaged(javaPath string) bool {
+	rel, err := filepath.Rel(env.GetJREDir(), javaPath)
+	return err == nil && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel)
+}
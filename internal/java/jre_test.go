@@ -0,0 +1,23 @@
+package java
+
+import "testing"
+
+func TestPinnedReleaseOnSupportedPlatforms(t *testing.T) {
+	platforms := []struct{ os, arch string }{
+		{"windows", "amd64"},
+		{"linux", "amd64"},
+		{"linux", "arm64"},
+		{"darwin", "amd64"},
+		{"darwin", "arm64"},
+	}
+	for _, p := range platforms {
+		rel, err := pinnedReleaseFor(p.os, p.arch)
+		if err != nil {
+			t.Errorf("%s/%s: %v", p.os, p.arch, err)
+			continue
+		}
+		if rel.Version == "" || rel.URL == "" || len(rel.SHA256) != 64 {
+			t.Errorf("%s/%s: incomplete pin %+v", p.os, p.arch, rel)
+		}
+	}
+}
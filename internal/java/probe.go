@@ -0,0 +1,186 @@
+package java
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"HyVanila/internal/util"
+)
+
+// RequiredMajor is the oldest Java feature release the game runs on
+const RequiredMajor = 25
+
+// probeTimeout bounds how long a Java binary gets to print its properties
+const probeTimeout = 15 * time.Second
+
+// Runtime sources
+const (
+	SourceManaged = "managed" // Installed by the launcher
+	SourceSystem  = "system"  // Found on the system
+	SourceCustom  = "custom"  // Picked by the user
+)
+
+// Runtime describes a Java installation
+type Runtime struct {
+	// ID selects the runtime: "temurin-<major>" for managed runtimes, the
+	// executable path otherwise
+	ID      string `json:"id"`
+	Path    string `json:"path"`    // java executable
+	Home    string `json:"home"`    // java.home
+	Version string `json:"version"` // java.version, e.g. "25.0.1"
+	Major   int    `json:"major"`
+	Vendor  string `json:"vendor"`
+	Arch    string `json:"arch"` // In GOARCH terms, e.g. "amd64"
+	Source  string `json:"source"`
+	// Problem explains why the game can't use this runtime (empty if it can)
+	Problem string `json:"problem,omitempty"`
+}
+
+// Compatible reports whether the game can run on this runtime
+func (r *Runtime) Compatible() bool {
+	return r.Problem == ""
+}
+
+type probeKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+var (
+	probeMu    sync.Mutex
+	probeCache = make(map[probeKey]*Runtime)
+)
+
+// Probe runs a Java binary to find its version, vendor and architecture. Results
+// are cached until the binary changes.
+func Probe(ctx context.Context, javaPath string) (*Runtime, error) {
+	info, err := os.Stat(javaPath)
+	if err != nil {
+		return nil, fmt.Errorf("Java not found at %s", javaPath)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a folder, not a Java executable", javaPath)
+	}
+	key := probeKey{path: javaPath, size: info.Size(), modTime: info.ModTime()}
+
+	probeMu.Lock()
+	cached, ok := probeCache[key]
+	probeMu.Unlock()
+	if ok {
+		rt := *cached
+		return &rt, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(probeCtx, javaPath, "-XshowSettings:properties", "-version")
+	util.HideConsoleWindow(cmd)
+	// The properties are printed to stderr
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if probeCtx.Err() != nil {
+			return nil, fmt.Errorf("%s did not respond", javaPath)
+		}
+		return nil, fmt.Errorf("failed to run %s: %w", javaPath, err)
+	}
+
+	rt, err := parseProperties(output)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", javaPath, err)
+	}
+	rt.Path = javaPath
+	rt.ID = javaPath
+	rt.Problem = checkCompatible(rt)
+
+	probeMu.Lock()
+	probeCache[key] = rt
+	probeMu.Unlock()
+	copied := *rt
+	return &copied, nil
+}
+
+// parseProperties reads the "key = value" lines of -XshowSettings:properties
+func parseProperties(output []byte) (*Runtime, error) {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " = ")
+		if ok {
+			props[key] = value
+		}
+	}
+
+	rt := &Runtime{
+		Home:    props["java.home"],
+		Version: props["java.version"],
+		Vendor:  props["java.vendor"],
+		Arch:    normalizeArch(props["os.arch"]),
+	}
+	if rt.Version == "" {
+		return nil, fmt.Errorf("not a Java runtime (no java.version reported)")
+	}
+	rt.Major = parseMajor(props["java.specification.version"])
+	if rt.Major == 0 {
+		rt.Major = parseMajor(rt.Version)
+	}
+	return rt, nil
+}
+
+// parseMajor returns the feature release of a Java version: "25.0.1" is 25,
+// "1.8.0_402" is 8
+func parseMajor(version string) int {
+	version = strings.TrimPrefix(version, "1.")
+	end := strings.IndexFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		version = version[:end]
+	}
+	major, _ := strconv.Atoi(version)
+	return major
+}
+
+// normalizeArch maps os.arch values to GOARCH names
+func normalizeArch(arch string) string {
+	switch arch {
+	case "x86_64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64":
+		return "arm64"
+	case "x86", "i386", "i486", "i586", "i686":
+		return "386"
+	}
+	return arch
+}
+
+// checkCompatible returns why the game can't run on a runtime, or "" if it can
+func checkCompatible(rt *Runtime) string {
+	if rt.Major < RequiredMajor {
+		return fmt.Sprintf("Java %d is too old, the game needs Java %d or newer", rt.Major, RequiredMajor)
+	}
+	if rt.Arch != runtime.GOARCH {
+		return fmt.Sprintf("this Java is built for %s, but the game runs on %s", rt.Arch, runtime.GOARCH)
+	}
+	return ""
+}
+
+// Validate probes a Java binary and returns an error if the game can't use it
+func Validate(ctx context.Context, javaPath string) (*Runtime, error) {
+	rt, err := Probe(ctx, javaPath)
+	if err != nil {
+		return nil, err
+	}
+	if !rt.Compatible() {
+		return rt, fmt.Errorf("%s can't run the game: %s", filepath.Clean(javaPath), rt.Problem)
+	}
+	return rt, nil
+}
@@ -0,0 +1,449 @@
+package java
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/storage"
+	"HyVanila/internal/tools"
+	"HyVanila/internal/util"
+	"HyVanila/internal/util/download"
+)
+
+// managedPrefix starts the ID and folder name of runtimes installed by the launcher
+const managedPrefix = "temurin-"
+
+// managedInfoFile records what a managed runtime folder contains
+const managedInfoFile = "runtime.json"
+
+// managedInfo is the install record of a managed runtime
+type managedInfo struct {
+	Major       int    `json:"major"`
+	Release     string `json:"release"` // Temurin release, e.g. "25.0.1+8"
+	URL         string `json:"url"`
+	SHA256      string `json:"sha256"`
+	InstalledAt string `json:"installedAt"` // ISO 8601 format
+}
+
+// installMu keeps two installs from writing the same runtime folder
+var installMu sync.Mutex
+
+// ManagedID returns the runtime ID of a managed Java feature release
+func ManagedID(major int) string {
+	return managedPrefix + strconv.Itoa(major)
+}
+
+// managedDir holds the installed releases of a managed runtime, one folder each
+func managedDir(major int) string {
+	return filepath.Join(env.GetJREDir(), ManagedID(major))
+}
+
+// releaseFolder names the folder of a Temurin release inside its managed runtime folder
+func releaseFolder(release string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(".+-_", r) {
+			return r
+		}
+		return '_'
+	}, release)
+	if strings.Trim(name, ".") == "" {
+		return "unknown"
+	}
+	return name
+}
+
+// runtimeHome returns the folder of the release a managed runtime uses: the one
+// recorded in the tools manifest, else a runtime older launchers installed
+// directly in the managed runtime folder
+func runtimeHome(major int) string {
+	dir := managedDir(major)
+	if tool, ok := tools.Get(ManagedID(major)); ok && filepath.Dir(tool.Path) == dir && readManagedInfo(tool.Path) != nil {
+		return tool.Path
+	}
+	return dir
+}
+
+// pruneRuntimes removes the releases of a managed runtime other than the one it
+// uses, skipping any a running game still uses. The caller holds installMu.
+func pruneRuntimes(major int) {
+	dir := managedDir(major)
+	home := runtimeHome(major)
+	if home == dir {
+		// Installed by an older launcher, nothing to prune around it
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if path == home || storage.IsInUse(path) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Printf("Warning: Failed to remove old Java Runtime files %s: %v\n", path, err)
+		}
+	}
+}
+
+func readManagedInfo(dir string) *managedInfo {
+	data, err := os.ReadFile(filepath.Join(dir, managedInfoFile))
+	if err != nil {
+		return nil
+	}
+	var info managedInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, getJavaBinaryName())); err != nil {
+		return nil
+	}
+	return &info
+}
+
+func writeManagedInfo(dir string, info *managedInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, managedInfoFile), data, 0644)
+}
+
+// recordRuntime stores a managed runtime in the tools manifest
+func recordRuntime(dir string, info *managedInfo) {
+	err := tools.Record(ManagedID(info.Major), tools.Tool{
+		Version:     info.Release,
+		Path:        dir,
+		SHA256:      info.SHA256,
+		Source:      info.URL,
+		InstalledAt: info.InstalledAt,
+	})
+	if err != nil {
+		fmt.Printf("Warning: Failed to record Java Runtime %s: %v\n", info.Release, err)
+	}
+}
+
+// migrateLegacyJRE moves a runtime installed directly in the JRE folder, as
+// older launchers did, into a release folder of its managed runtime
+func migrateLegacyJRE() {
+	jreDir := env.GetJREDir()
+	legacyJava := filepath.Join(jreDir, getJavaBinaryName())
+	if _, err := os.Stat(legacyJava); err != nil {
+		return
+	}
+
+	installMu.Lock()
+	defer installMu.Unlock()
+
+	rt, err := Probe(context.Background(), legacyJava)
+	if err != nil {
+		fmt.Printf("Warning: Can't identify the installed Java Runtime, leaving it in place: %v\n", err)
+		return
+	}
+	dest := managedDir(rt.Major)
+	if _, err := os.Stat(dest); err == nil {
+		return
+	}
+
+	staging := dest + ".tmp"
+	release := filepath.Join(staging, releaseFolder(rt.Version))
+	os.RemoveAll(staging)
+	if err := os.MkdirAll(release, 0755); err != nil {
+		return
+	}
+	entries, err := os.ReadDir(jreDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, managedPrefix) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if err := os.Rename(filepath.Join(jreDir, name), filepath.Join(release, name)); err != nil {
+			fmt.Printf("Warning: Failed to move Java Runtime: %v\n", err)
+			return
+		}
+	}
+	info := &managedInfo{
+		Major:       rt.Major,
+		Release:     rt.Version,
+		InstalledAt: time.Now().Format(time.RFC3339),
+	}
+	writeManagedInfo(release, info)
+	if err := os.Rename(staging, dest); err != nil {
+		fmt.Printf("Warning: Failed to move Java Runtime: %v\n", err)
+		return
+	}
+	home := filepath.Join(dest, releaseFolder(rt.Version))
+	recordRuntime(home, info)
+	fmt.Printf("Moved Java Runtime %s to %s\n", rt.Version, home)
+}
+
+// InstallRuntime installs or updates the Temurin JRE of a Java feature release
+// next to the other managed runtimes. Each release gets its own folder, so an
+// update never touches the files of a runtime a game is running on; releases no
+// longer in use are removed afterwards. The archive's checksum is verified and the
+// runtime is probed before it is used.
+func InstallRuntime(ctx context.Context, major int, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (*Runtime, error) {
+	installMu.Lock()
+	defer installMu.Unlock()
+
+	if progressCallback != nil {
+		progressCallback("jre", 0, fmt.Sprintf("Downloading Java %d Runtime...", major), "", "", 0, 0)
+	}
+
+	rel, err := resolveRelease(ctx, major)
+	if err != nil {
+		return nil, err
+	}
+
+	home := runtimeHome(major)
+	javaBin := getJavaBinaryName()
+	if info := readManagedInfo(home); info != nil && info.Release == rel.Version {
+		recordRuntime(home, info)
+		pruneRuntimes(major)
+		if progressCallback != nil {
+			progressCallback("jre", 100, "Java Runtime ready", "", "", 0, 0)
+		}
+		return managedRuntime(ctx, major, home)
+	}
+
+	archiveExt := ".tar.gz"
+	if runtime.GOOS == "windows" {
+		archiveExt = ".zip"
+	}
+	archivePath := filepath.Join(env.GetCacheDir(), ManagedID(major)+archiveExt)
+
+	// The checksum is verified before the archive is moved into place
+	err = download.Fetch(ctx, download.Request{
+		URL:      rel.URL,
+		Dest:     archivePath,
+		SHA256:   rel.SHA256,
+		Stage:    "jre",
+		Weight:   0.8,
+		Progress: progressCallback,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download JRE: %w", err)
+	}
+	defer os.Remove(archivePath)
+
+	if progressCallback != nil {
+		progressCallback("jre", 90, "Extracting Java Runtime...", "", "", 0, 0)
+	}
+
+	// Extract next to the installed release so a failed install leaves it working
+	dir := filepath.Join(managedDir(major), releaseFolder(rel.Version))
+	staging := dir + ".tmp"
+	os.RemoveAll(staging)
+	if err := util.ExtractArchive(archivePath, staging); err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("failed to extract JRE: %w", err)
+	}
+	if err := normalizeJREStructure(staging); err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("failed to normalize JRE structure: %w", err)
+	}
+	if runtime.GOOS != "windows" {
+		os.Chmod(filepath.Join(staging, javaBin), 0755)
+	}
+
+	rt, err := Probe(ctx, filepath.Join(staging, javaBin))
+	if err == nil && rt.Major != major {
+		err = fmt.Errorf("downloaded runtime is Java %d, expected %d", rt.Major, major)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("downloaded Java Runtime doesn't work: %w", err)
+	}
+
+	info := &managedInfo{
+		Major:       major,
+		Release:     rel.Version,
+		URL:         rel.URL,
+		SHA256:      rel.SHA256,
+		InstalledAt: time.Now().Format(time.RFC3339),
+	}
+	if err := writeManagedInfo(staging, info); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+
+	// A broken earlier install of this release is replaced, unless a game started on it
+	if storage.IsInUse(dir) {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("Java Runtime %s is in use by the game, close it and try again", rel.Version)
+	}
+	os.RemoveAll(dir)
+	if err := os.Rename(staging, dir); err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("failed to install Java Runtime: %w", err)
+	}
+	recordRuntime(dir, info)
+	pruneRuntimes(major)
+
+	fmt.Printf("Installed Java Runtime %s\n", rel.Version)
+	if progressCallback != nil {
+		progressCallback("jre", 100, "Java Runtime installed", "", "", 0, 0)
+	}
+	return managedRuntime(ctx, major, dir)
+}
+
+// RemoveRuntime deletes a managed runtime. The default runtime is kept.
+func RemoveRuntime(id string) error {
+	major, ok := parseManagedID(id)
+	if !ok {
+		return fmt.Errorf("%s is not a runtime installed by the launcher", id)
+	}
+	if major == DefaultMajor {
+		return fmt.Errorf("the default Java %d Runtime can't be removed", major)
+	}
+
+	installMu.Lock()
+	defer installMu.Unlock()
+	dir := managedDir(major)
+	if storage.IsInUse(dir) {
+		return fmt.Errorf("Java %d Runtime is in use by the game", major)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return tools.Remove(id)
+}
+
+func parseManagedID(id string) (int, bool) {
+	if !strings.HasPrefix(id, managedPrefix) {
+		return 0, false
+	}
+	major, err := strconv.Atoi(strings.TrimPrefix(id, managedPrefix))
+	return major, err == nil && major > 0
+}
+
+// managedRuntime probes a release folder of a managed runtime
+func managedRuntime(ctx context.Context, major int, dir string) (*Runtime, error) {
+	rt, err := Probe(ctx, filepath.Join(dir, getJavaBinaryName()))
+	if err != nil {
+		return nil, err
+	}
+	rt.ID = ManagedID(major)
+	rt.Source = SourceManaged
+	if info := readManagedInfo(dir); info != nil && info.Release != "" {
+		rt.Version = info.Release
+	}
+	return rt, nil
+}
+
+// ListManaged returns the runtimes installed by the launcher
+func ListManaged(ctx context.Context) []Runtime {
+	migrateLegacyJRE()
+	entries, err := os.ReadDir(env.GetJREDir())
+	if err != nil {
+		return nil
+	}
+
+	var runtimes []Runtime
+	for _, entry := range entries {
+		major, ok := parseManagedID(entry.Name())
+		if !ok || !entry.IsDir() {
+			continue
+		}
+		rt, err := managedRuntime(ctx, major, runtimeHome(major))
+		if err != nil {
+			fmt.Printf("Warning: Skipping broken Java Runtime %s: %v\n", entry.Name(), err)
+			continue
+		}
+		runtimes = append(runtimes, *rt)
+	}
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i].Major > runtimes[j].Major })
+	return runtimes
+}
+
+// systemCandidates returns where Java installations commonly live on this platform
+func systemCandidates() []string {
+	javaBin := getJavaBinaryName()
+	var candidates []string
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		candidates = append(candidates, filepath.Join(home, javaBin))
+	}
+	if path, err := exec.LookPath("java"); err == nil {
+		candidates = append(candidates, path)
+	}
+
+	var patterns []string
+	switch runtime.GOOS {
+	case "windows":
+		for _, base := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)"), os.Getenv("LOCALAPPDATA") + `\Programs`} {
+			if base != "" && base != `\Programs` {
+				// e.g. C:\Program Files\Eclipse Adoptium\jdk-25.0.1.8-hotspot\bin\java.exe
+				patterns = append(patterns, filepath.Join(base, "*", "*", javaBin))
+			}
+		}
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		for _, base := range []string{"/Library/Java/JavaVirtualMachines", filepath.Join(home, "Library", "Java", "JavaVirtualMachines")} {
+			patterns = append(patterns, filepath.Join(base, "*", "Contents", "Home", javaBin))
+		}
+		patterns = append(patterns, "/opt/homebrew/opt/openjdk*/bin/java", "/usr/local/opt/openjdk*/bin/java")
+	default:
+		home, _ := os.UserHomeDir()
+		patterns = append(patterns,
+			"/usr/lib/jvm/*/bin/java",
+			"/usr/java/*/bin/java",
+			"/opt/java/*/bin/java",
+			filepath.Join(home, ".sdkman", "candidates", "java", "*", "bin", "java"),
+		)
+	}
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		candidates = append(candidates, matches...)
+	}
+	return candidates
+}
+
+// DetectSystem finds Java installations outside the launcher, compatible or not
+func DetectSystem(ctx context.Context) []Runtime {
+	seen := make(map[string]bool)
+	var runtimes []Runtime
+	for _, candidate := range systemCandidates() {
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil || seen[resolved] || IsManaged(resolved) {
+			continue
+		}
+		seen[resolved] = true
+
+		rt, err := Probe(ctx, resolved)
+		if err != nil {
+			continue
+		}
+		rt.Source = SourceSystem
+		runtimes = append(runtimes, *rt)
+	}
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i].Major > runtimes[j].Major })
+	return runtimes
+}
+
+// Resolve returns the Java executable for a runtime ID: a managed runtime
+// ("temurin-25") or the path of any Java executable. An empty ID is the default
+// runtime. Whether the executable exists and works is left to Validate.
+func Resolve(id string) (string, error) {
+	if id == "" {
+		return GetJavaExec()
+	}
+	if major, ok := parseManagedID(id); ok {
+		return filepath.Join(runtimeHome(major), getJavaBinaryName()), nil
+	}
+	return id, nil
+}
@@ -0,0 +1,84 @@
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/storage"
+)
+
+// fakeRelease lays out a release folder of a managed runtime
+func fakeRelease(t *testing.T, major int, release string) string {
+	t.Helper()
+	dir := filepath.Join(managedDir(major), releaseFolder(release))
+	javaPath := filepath.Join(dir, getJavaBinaryName())
+	if err := os.MkdirAll(filepath.Dir(javaPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(javaPath, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeManagedInfo(dir, &managedInfo{Major: major, Release: release}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPruneKeepsReleasesInUse(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	if err := env.CreateFolders(); err != nil {
+		t.Fatal(err)
+	}
+
+	running := fakeRelease(t, 25, "25.0.1+8")
+	stale := fakeRelease(t, 25, "25.0.0+36")
+	recordRuntime(running, &managedInfo{Major: 25, Release: "25.0.1+8"})
+	if got := runtimeHome(25); got != running {
+		t.Fatalf("runtimeHome = %s, want %s", got, running)
+	}
+
+	// The game keeps running on the old release while the new one is installed
+	release := storage.MarkInUse(running)
+	current := fakeRelease(t, 25, "25.0.2+10")
+	recordRuntime(current, &managedInfo{Major: 25, Release: "25.0.2+10"})
+	pruneRuntimes(25)
+
+	if got := runtimeHome(25); got != current {
+		t.Errorf("runtimeHome = %s, want %s", got, current)
+	}
+	if _, err := os.Stat(running); err != nil {
+		t.Errorf("release in use was removed: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("unused release was kept: %v", err)
+	}
+
+	release()
+	pruneRuntimes(25)
+	if _, err := os.Stat(running); !os.IsNotExist(err) {
+		t.Errorf("release was kept after the game closed: %v", err)
+	}
+	if _, err := os.Stat(current); err != nil {
+		t.Errorf("current release was removed: %v", err)
+	}
+}
+
+func TestReleaseFolder(t *testing.T) {
+	tests := map[string]string{
+		"25.0.1+8": "25.0.1+8",
+		"25.0.1 8": "25.0.1_8",
+		"../x":     ".._x",
+		"..":       "unknown",
+		"":         "unknown",
+	}
+	for release, want := range tests {
+		if got := releaseFolder(release); got != want {
+			t.Errorf("releaseFolder(%q) = %q, want %q", release, got, want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/mods"
+	"HyVanila/internal/util"
+)
+
+// legacyLayoutID identifies the legacy global layout migration in the state file
+const legacyLayoutID = "legacy-layout-v1"
+
+// Report describes what a migration did
+type Report struct {
+	ID          string `json:"id"`
+	StartedAt   string `json:"startedAt"`
+	CompletedAt string `json:"completedAt"`
+	// Target is the instance the legacy data was moved into
+	TargetBranch  string `json:"targetBranch"`
+	TargetVersion int    `json:"targetVersion"`
+	MovedFiles    int    `json:"movedFiles"`
+	// Duplicates are legacy files identical to a file already in the instance
+	Duplicates int `json:"duplicates"`
+	// Conflicts are legacy files kept next to a different instance file with a .legacy suffix
+	Conflicts  []string `json:"conflicts,omitempty"`
+	MergedMods int      `json:"mergedMods"`
+	Version    string   `json:"version,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+	// Skipped is set when there was no legacy data to migrate
+	Skipped bool `json:"skipped"`
+}
+
+// state records which migrations have completed
+type state struct {
+	Completed map[string]string `json:"completed"` // migration ID -> report path
+}
+
+func getMigrationsDir() string {
+	return filepath.Join(env.GetDefaultAppDir(), "migrations")
+}
+
+func getStatePath() string {
+	return filepath.Join(getMigrationsDir(), "state.json")
+}
+
+func loadState() *state {
+	st := &state{Completed: make(map[string]string)}
+	data, err := os.ReadFile(getStatePath())
+	if err != nil {
+		return st
+	}
+	if err := json.Unmarshal(data, st); err != nil || st.Completed == nil {
+		st.Completed = make(map[string]string)
+	}
+	return st
+}
+
+func saveState(st *state) error {
+	if err := os.MkdirAll(getMigrationsDir(), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(getStatePath(), data, 0644)
+}
+
+// GetReport returns the report of the legacy layout migration (nil if it hasn't run)
+func GetReport() *Report {
+	path, ok := loadState().Completed[legacyLayoutID]
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil
+	}
+	return &report
+}
+
+// RunLegacyLayout moves the legacy global UserData (including Mods and the mod
+// manifest) and version.txt into the matching instance. It runs once; an
+// interrupted run is safe to repeat since every step skips work already done.
+func RunLegacyLayout() (*Report, error) {
+	st := loadState()
+	if _, done := st.Completed[legacyLayoutID]; done {
+		return nil, nil
+	}
+
+	report := &Report{ID: legacyLayoutID, StartedAt: time.Now().Format(time.RFC3339)}
+	appDir := env.GetDefaultAppDir()
+	legacyUserData := env.GetUserDataDir()
+	legacyVersionFile := filepath.Join(appDir, "version.txt")
+
+	hasUserData := dirHasFiles(legacyUserData)
+	_, versionErr := os.Stat(legacyVersionFile)
+	hasVersion := versionErr == nil
+
+	if !hasUserData && !hasVersion {
+		report.Skipped = true
+	} else {
+		fmt.Println("Migrating legacy UserData and mods into the instance layout...")
+		report.TargetBranch, report.TargetVersion = legacyTarget(legacyVersionFile)
+		instanceDir := env.GetInstanceDir(report.TargetBranch, report.TargetVersion)
+		if err := os.MkdirAll(instanceDir, 0755); err != nil {
+			return nil, err
+		}
+
+		if hasUserData {
+			migrateUserData(legacyUserData, env.GetInstanceUserDataDir(report.TargetBranch, report.TargetVersion), report)
+		}
+		if hasVersion {
+			migrateVersion(legacyVersionFile, instanceDir, report)
+		}
+		removeEmptyDirs(legacyUserData)
+	}
+
+	if len(report.Errors) > 0 {
+		// Leave the migration pending so the remaining files are retried next start
+		return report, fmt.Errorf("legacy migration finished with %d error(s)", len(report.Errors))
+	}
+
+	report.CompletedAt = time.Now().Format(time.RFC3339)
+	reportPath := filepath.Join(getMigrationsDir(), legacyLayoutID+".json")
+	if err := os.MkdirAll(getMigrationsDir(), 0755); err != nil {
+		return report, err
+	}
+	data, _ := json.MarshalIndent(report, "", "  ")
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return report, err
+	}
+
+	st.Completed[legacyLayoutID] = reportPath
+	if err := saveState(st); err != nil {
+		return report, err
+	}
+
+	if !report.Skipped {
+		fmt.Printf("Legacy migration complete: %d file(s) moved into %s, %d conflict(s)\n",
+			report.MovedFiles, filepath.Base(env.GetInstanceDir(report.TargetBranch, report.TargetVersion)), len(report.Conflicts))
+	}
+	return report, nil
+}
+
+// legacyTarget picks the instance for legacy data: the versioned release instance
+// matching the legacy build if it is installed, otherwise release-latest
+func legacyTarget(legacyVersionFile string) (string, int) {
+	if data, err := os.ReadFile(legacyVersionFile); err == nil {
+		if build, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && build > 0 {
+			if _, err := os.Stat(env.GetInstanceGameDir("release", build)); err == nil {
+				return "release", build
+			}
+		}
+	}
+	return "release", 0
+}
+
+// migrateUserData moves every legacy file into the instance UserData. The mod
+// manifest is merged rather than moved, with file paths rewritten.
+func migrateUserData(legacyDir, instanceDir string, report *Report) {
+	legacyManifest := filepath.Join(legacyDir, "Mods", "manifest.json")
+
+	filepath.WalkDir(legacyDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			return nil
+		}
+		if d.IsDir() || path == legacyManifest {
+			return nil
+		}
+		rel, err := filepath.Rel(legacyDir, path)
+		if err != nil {
+			return nil
+		}
+		dest := filepath.Join(instanceDir, rel)
+
+		if _, err := os.Stat(dest); err == nil {
+			same, err := sameContent(path, dest)
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				return nil
+			}
+			if same {
+				os.Remove(path)
+				report.Duplicates++
+				return nil
+			}
+			// Keep the instance's file and park the legacy one next to it
+			dest += ".legacy"
+			report.Conflicts = append(report.Conflicts, filepath.ToSlash(rel))
+		}
+
+		if err := moveFile(path, dest); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		report.MovedFiles++
+		return nil
+	})
+
+	if _, err := os.Stat(legacyManifest); err == nil {
+		if err := mergeModManifest(legacyManifest, filepath.Join(legacyDir, "Mods"), filepath.Join(instanceDir, "Mods"), report); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("mod manifest: %v", err))
+		}
+	}
+}
+
+// mergeModManifest adds legacy mods to the instance manifest (instance entries win)
+// and points their file paths at the instance Mods folder
+func mergeModManifest(legacyPath, legacyModsDir, instanceModsDir string, report *Report) error {
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	var legacy mods.ModManifest
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	instancePath := filepath.Join(instanceModsDir, "manifest.json")
+	merged := mods.ModManifest{Mods: []mods.Mod{}, Version: legacy.Version}
+	if data, err := os.ReadFile(instancePath); err == nil {
+		if err := json.Unmarshal(data, &merged); err != nil {
+			return fmt.Errorf("instance manifest is unreadable: %w", err)
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, mod := range merged.Mods {
+		known[mod.ID] = true
+	}
+	for _, mod := range legacy.Mods {
+		if known[mod.ID] {
+			continue
+		}
+		if rel, err := filepath.Rel(legacyModsDir, mod.FilePath); err == nil && !strings.HasPrefix(rel, "..") {
+			mod.FilePath = filepath.Join(instanceModsDir, rel)
+		}
+		merged.Mods = append(merged.Mods, mod)
+		report.MergedMods++
+	}
+
+	if err := os.MkdirAll(instanceModsDir, 0755); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(instancePath, out, 0644); err != nil {
+		return err
+	}
+	return os.Remove(legacyPath)
+}
+
+// migrateVersion moves the legacy version.txt into the instance unless it already has one
+func migrateVersion(legacyFile, instanceDir string, report *Report) {
+	data, err := os.ReadFile(legacyFile)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return
+	}
+	report.Version = strings.TrimSpace(string(data))
+
+	dest := filepath.Join(instanceDir, "version.txt")
+	if _, err := os.Stat(dest); err == nil {
+		os.Rename(legacyFile, legacyFile+".migrated")
+		return
+	}
+	if err := moveFile(legacyFile, dest); err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("version.txt: %v", err))
+	}
+}
+
+// moveFile renames a file, falling back to copy and delete across file systems
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := util.CopyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// sameContent reports whether two files have identical content
+func sameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+	hashA, err := util.FileSHA256(a)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := util.FileSHA256(b)
+	if err != nil {
+		return false, err
+	}
+	return hashA == hashB, nil
+}
+
+// dirHasFiles reports whether a directory tree contains at least one file
+func dirHasFiles(dir string) bool {
+	errFound := errors.New("found")
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return errFound
+		}
+		return nil
+	})
+	return errors.Is(err, errFound)
+}
+
+// removeEmptyDirs removes a directory tree bottom-up as long as it holds no files
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		}
+	}
+	os.Remove(dir) // Fails harmlessly if not empty
+}
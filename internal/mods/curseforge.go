@@ -165,7 +165,7 @@ func SearchMods(ctx context.Context, params SearchModsParams) (*SearchResult, er
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search mods: %w", err)
@@ -213,7 +213,7 @@ func GetModDetails(ctx context.Context, modID int) (*CurseForgeMod, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -249,7 +249,7 @@ func GetModFiles(ctx context.Context, modID int) ([]ModFile, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -375,7 +375,7 @@ func DownloadModFile(ctx context.Context, modID int, fileID int, progressCallbac
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -583,7 +583,7 @@ func DownloadModFileToInstance(ctx context.Context, modID int, fileID int, branc
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -735,7 +735,7 @@ func GetCategories(ctx context.Context) ([]ModCategory, error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("x-api-key", cfAPIKey)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := download.NewClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -38,9 +38,9 @@ type ModManifest struct {
 }
 
 // GetModsDir returns the mods directory path (legacy - for backwards compatibility)
-// Mods should be in UserData/Mods as that's where the game reads them
+// The global UserData/Mods folder is migrated into release-latest, so legacy callers use that instance
 func GetModsDir() string {
-	return filepath.Join(env.GetDefaultAppDir(), "UserData", "Mods")
+	return GetInstanceModsDir("release", 0)
 }
 
 // GetInstanceModsDir returns the mods directory for a specific instance
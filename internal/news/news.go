@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"HyVanila/internal/util/download"
 )
 
 const CDN_URL = "https://cdn.hytale.com/variants/blog_thumb_"
@@ -30,9 +32,7 @@ type NewsItem struct {
 
 // FetchNews fetches news from hytale.com blog api
 func FetchNews(limit int) ([]NewsItem, error) {
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
+	client := download.NewClient(15 * time.Second)
 
 	req, err := http.NewRequest("GET", fmt.Sprintf("https://hytale.com/api/blog/post/published?limit=%d", limit), nil)
 	if err != nil {
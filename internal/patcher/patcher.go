@@ -2,9 +2,11 @@ package patcher
 
 import (
 	"archive/zip"
-	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +15,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"HyVanila/internal/util"
 )
 
 const (
@@ -32,16 +36,24 @@ type PatchFlag struct {
 
 // PatchResult contains the result of a patching operation
 type PatchResult struct {
-	Success        bool   `json:"success"`
-	AlreadyPatched bool   `json:"alreadyPatched"`
-	PatchCount     int    `json:"patchCount"`
-	Error          string `json:"error,omitempty"`
+	Success        bool          `json:"success"`
+	AlreadyPatched bool          `json:"alreadyPatched"`
+	PatchCount     int           `json:"patchCount"`
+	Error          string        `json:"error,omitempty"`
+	Files          []PatchedFile `json:"files,omitempty"`
+	// Repatched is set when binaries patched for a different domain were restored first
+	Repatched bool `json:"repatched,omitempty"`
 }
 
 // ClientPatcher handles patching Hytale binaries to use custom auth server
 type ClientPatcher struct {
 	targetDomain string
 	patchedFlag  string
+	rules        *RuleSet
+	branch       string
+	build        int
+	// rulesErr is why the rule set couldn't be loaded; patching fails with it
+	rulesErr error
 }
 
 // NewClientPatcher creates a new patcher with the specified target domain
@@ -55,12 +67,32 @@ func NewClientPatcher(targetDomain string) *ClientPatcher {
 			targetDomain, len(targetDomain), OriginalDomain, len(OriginalDomain))
 		targetDomain = DefaultAuthDomain
 	}
+	// Without rules the patcher can still locate and restore files, but it
+	// refuses to patch rather than silently patching nothing
+	rules, err := LoadRuleSet()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		rules = &RuleSet{}
+	}
 	return &ClientPatcher{
 		targetDomain: targetDomain,
 		patchedFlag:  ".patched_custom",
+		rules:        rules,
+		rulesErr:     err,
 	}
 }
 
+// SetGameVersion sets the branch and build number used to select version-specific rules
+func (p *ClientPatcher) SetGameVersion(branch string, build int) {
+	p.branch = branch
+	p.build = build
+}
+
+// GetRuleSet returns the rule set used by this patcher
+func (p *ClientPatcher) GetRuleSet() *RuleSet {
+	return p.rules
+}
+
 // stringToUTF16LE converts a string to UTF-16LE bytes (how .NET stores strings)
 func stringToUTF16LE(s string) []byte {
 	buf := make([]byte, len(s)*2)
@@ -75,88 +107,63 @@ func stringToUTF8(s string) []byte {
 	return []byte(s)
 }
 
-// findAndReplaceDomainSmart handles domain replacement for .NET AOT binaries
-// .NET stores strings in various formats (UTF-16LE, length-prefixed, etc.)
-// Optimized version: modifies data in-place without extra allocations
-func (p *ClientPatcher) findAndReplaceDomainSmart(data []byte) ([]byte, int) {
-	count := 0
-
-	// Get UTF-16LE patterns for old and new domains (without last char)
-	oldNoLast := stringToUTF16LE(OriginalDomain[:len(OriginalDomain)-1])
-	newNoLast := stringToUTF16LE(p.targetDomain[:len(p.targetDomain)-1])
-
-	oldLastCharByte := OriginalDomain[len(OriginalDomain)-1]
-	newLastCharByte := p.targetDomain[len(p.targetDomain)-1]
-
-	// Find and replace in-place for better performance
-	pos := 0
-	for pos < len(data) {
-		idx := bytes.Index(data[pos:], oldNoLast)
-		if idx == -1 {
-			break
-		}
-		idx += pos
-
-		lastCharPos := idx + len(oldNoLast)
-		if lastCharPos+1 > len(data) {
-			pos = idx + 1
+// rulesFor compiles the rules that apply to a target for the current game version
+func (p *ClientPatcher) rulesFor(target string) ([]*compiledRule, error) {
+	if p.rulesErr != nil {
+		return nil, p.rulesErr
+	}
+	var compiled []*compiledRule
+	for _, rule := range p.rules.Rules {
+		if rule.Target != target || !rule.appliesTo(p.branch, p.build) {
 			continue
 		}
-
-		lastCharFirstByte := data[lastCharPos]
-
-		// Check if this looks like a valid domain occurrence
-		if lastCharFirstByte == oldLastCharByte {
-			// Copy new domain (without last char) in-place
-			copy(data[idx:], newNoLast)
-			// Update last char
-			data[lastCharPos] = newLastCharByte
-			count++
+		cr, err := rule.compile(OriginalDomain, p.targetDomain)
+		if err != nil {
+			return nil, err
 		}
-		pos = idx + 1
+		compiled = append(compiled, cr)
 	}
-
-	return data, count
+	return compiled, nil
 }
 
-// findAndReplaceDomainUTF8 handles domain replacement for Java JAR files
-// Optimized: modifies data in-place
-func (p *ClientPatcher) findAndReplaceDomainUTF8(data []byte) ([]byte, int) {
-	count := 0
-
-	oldUTF8 := stringToUTF8(OriginalDomain)
-	newUTF8 := stringToUTF8(p.targetDomain)
-
-	// Find and replace in-place
-	pos := 0
-	for pos < len(data) {
-		idx := bytes.Index(data[pos:], oldUTF8)
-		if idx == -1 {
-			break
-		}
-		idx += pos
-		copy(data[idx:], newUTF8)
-		count++
-		pos = idx + len(oldUTF8)
+// readPatchFlag reads the patch flag of a binary (nil if it isn't patched)
+func (p *ClientPatcher) readPatchFlag(binaryPath string) *PatchFlag {
+	data, err := os.ReadFile(binaryPath + p.patchedFlag)
+	if err != nil {
+		return nil
 	}
 
-	return data, count
+	var flag PatchFlag
+	if err := json.Unmarshal(data, &flag); err != nil {
+		return nil
+	}
+	return &flag
 }
 
-// isPatchedAlready checks if a binary has already been patched
+// isPatchedAlready checks if a binary has already been patched for the target domain
 func (p *ClientPatcher) isPatchedAlready(binaryPath string) bool {
-	flagFile := binaryPath + p.patchedFlag
-	data, err := os.ReadFile(flagFile)
-	if err != nil {
-		return false
-	}
+	flag := p.readPatchFlag(binaryPath)
+	return flag != nil && flag.TargetDomain == p.targetDomain
+}
 
-	var flag PatchFlag
-	if err := json.Unmarshal(data, &flag); err != nil {
-		return false
+// PatchedDomain returns the auth domain a game directory is currently patched for,
+// or an empty string if neither the client nor the server is patched
+func PatchedDomain(gameDir string) string {
+	if manifest, err := loadPatchManifest(gameDir); err == nil && manifest != nil && len(manifest.Files) > 0 {
+		return manifest.TargetDomain
 	}
 
-	return flag.TargetDomain == p.targetDomain
+	// Fall back to the flag files for instances patched before manifests existed
+	p := &ClientPatcher{patchedFlag: ".patched_custom"}
+	for _, path := range []string{p.FindClientPath(gameDir), p.FindServerPath(gameDir)} {
+		if path == "" {
+			continue
+		}
+		if flag := p.readPatchFlag(path); flag != nil {
+			return flag.TargetDomain
+		}
+	}
+	return ""
 }
 
 // markAsPatched creates a flag file indicating the binary was patched
@@ -176,36 +183,109 @@ func (p *ClientPatcher) markAsPatched(binaryPath string) error {
 	return os.WriteFile(binaryPath+p.patchedFlag, data, 0644)
 }
 
-// backupBinary creates a backup of the original binary
-func (p *ClientPatcher) backupBinary(binaryPath string) (string, error) {
-	backupPath := binaryPath + ".original"
-	if _, err := os.Stat(backupPath); err == nil {
-		fmt.Println("  Backup already exists")
-		return backupPath, nil
+// backupBinary creates a backup of the original binary and returns its path and hash.
+// An existing backup that doesn't match originalHash is stale (the game was updated
+// since it was made) and gets replaced.
+func (p *ClientPatcher) backupBinary(binaryPath string, originalHash string) (string, string, error) {
+	if backupPath := existingBackup(binaryPath); backupPath != "" {
+		backupHash, err := util.FileSHA256(backupPath)
+		if err != nil {
+			return "", "", err
+		}
+		if originalHash == "" || backupHash == originalHash {
+			fmt.Println("  Backup already exists")
+			return backupPath, backupHash, nil
+		}
+		fmt.Println("  Existing backup doesn't match current binary, refreshing it")
+		os.Remove(backupPath)
 	}
 
-	fmt.Printf("  Creating backup at %s\n", filepath.Base(backupPath))
-	src, err := os.ReadFile(binaryPath)
+	backupPath := backupPathFor(binaryPath)
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err != nil {
+		return "", "", err
+	}
+	fmt.Printf("  Creating backup at %s\n", backupPath)
+	backupHash, err := copyFileAtomic(binaryPath, backupPath, 0644)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	return backupPath, os.WriteFile(backupPath, src, 0644)
+	return backupPath, backupHash, nil
 }
 
-// RestoreBinary restores the original binary from backup
-func (p *ClientPatcher) RestoreBinary(binaryPath string) error {
-	backupPath := binaryPath + ".original"
-	if _, err := os.Stat(backupPath); err != nil {
-		return fmt.Errorf("no backup found to restore: %s", backupPath)
+// sha256Hex returns the hex-encoded SHA256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// copyFileAtomic streams src into a temp file next to dst, syncs it and renames it
+// over dst, so dst is never left half-written. Returns the SHA256 of the copied data.
+func copyFileAtomic(src, dst string, perm os.FileMode) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
 	}
+	defer in.Close()
 
-	fmt.Printf("Restoring backup from %s\n", filepath.Base(backupPath))
-	src, err := os.ReadFile(backupPath)
+	hasher := sha256.New()
+	err = writeAtomic(dst, perm, func(w io.Writer) error {
+		_, err := io.Copy(io.MultiWriter(w, hasher), in)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// writeFileAtomic writes data to path via a temp file and rename
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic runs write against a temp file next to path, syncs it and renames it
+// into place. A leftover temp file from an interrupted run is simply overwritten.
+func writeAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
 	if err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(binaryPath, src, 0755); err != nil {
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// RestoreBinary restores the original binary from backup
+func (p *ClientPatcher) RestoreBinary(binaryPath string) error {
+	backupPath := existingBackup(binaryPath)
+	if backupPath == "" {
+		return fmt.Errorf("no backup found to restore: %s", backupPathFor(binaryPath))
+	}
+
+	fmt.Printf("Restoring backup from %s\n", filepath.Base(backupPath))
+	if _, err := copyFileAtomic(backupPath, binaryPath, 0755); err != nil {
 		return err
 	}
 
@@ -333,12 +413,6 @@ func (p *ClientPatcher) PatchClient(clientPath string, progressCallback func(msg
 		return PatchResult{Success: true, AlreadyPatched: true, PatchCount: 0}
 	}
 
-	progressCallback("Preparing to patch client...", 10)
-	fmt.Println("Creating backup...")
-	if _, err := p.backupBinary(clientPath); err != nil {
-		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create backup: %v", err)}
-	}
-
 	progressCallback("Reading client binary...", 20)
 	fmt.Println("Reading client binary...")
 	data, err := os.ReadFile(clientPath)
@@ -346,10 +420,27 @@ func (p *ClientPatcher) PatchClient(clientPath string, progressCallback func(msg
 		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to read client: %v", err)}
 	}
 	fmt.Printf("Binary size: %.2f MB\n", float64(len(data))/1024/1024)
+	originalHash := sha256Hex(data)
+
+	rules, err := p.rulesFor(TargetClient)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Invalid patch rules: %v", err)}
+	}
 
 	progressCallback("Patching domain references...", 50)
-	fmt.Printf("Patching domain references (in-place optimization)...\n")
-	patchedData, count := p.findAndReplaceDomainSmart(data)
+	fmt.Printf("Applying %d patch rule(s) (in-place optimization)...\n", len(rules))
+	count := 0
+	ruleMatches := make(map[string]int)
+	for _, rule := range rules {
+		ruleCount := rule.apply(data, nil)
+		fmt.Printf("  Rule %s: %d match(es)\n", rule.rule.ID, ruleCount)
+		if err := rule.rule.checkCount(ruleCount); err != nil {
+			return PatchResult{Success: false, Error: err.Error()}
+		}
+		ruleMatches[rule.rule.ID] = ruleCount
+		count += ruleCount
+	}
+	patchedData := data
 
 	fmt.Printf("Patched %d domain occurrences\n", count)
 
@@ -358,9 +449,17 @@ func (p *ClientPatcher) PatchClient(clientPath string, progressCallback func(msg
 		return PatchResult{Success: true, PatchCount: 0}
 	}
 
+	// Only back up once we know the binary still holds the original domain
+	progressCallback("Creating backup...", 70)
+	fmt.Println("Creating backup...")
+	_, backupHash, err := p.backupBinary(clientPath, originalHash)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create backup: %v", err)}
+	}
+
 	progressCallback("Writing patched binary...", 80)
 	fmt.Println("Writing patched binary...")
-	if err := os.WriteFile(clientPath, patchedData, 0755); err != nil {
+	if err := writeFileAtomic(clientPath, patchedData, 0755); err != nil {
 		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to write patched client: %v", err)}
 	}
 
@@ -375,7 +474,9 @@ func (p *ClientPatcher) PatchClient(clientPath string, progressCallback func(msg
 	fmt.Printf("Successfully patched %d domain occurrences\n", count)
 	fmt.Println("=== Patching Complete ===")
 
-	return PatchResult{Success: true, PatchCount: count}
+	return PatchResult{Success: true, PatchCount: count, Files: []PatchedFile{
+		p.newPatchedFile(clientPath, TargetClient, originalHash, sha256Hex(patchedData), backupHash, count, ruleMatches),
+	}}
 }
 
 // PatchServer patches the server JAR to use custom auth server
@@ -401,9 +502,9 @@ func (p *ClientPatcher) PatchServer(serverPath string, progressCallback func(msg
 	}
 
 	progressCallback("Preparing to patch server...", 10)
-	fmt.Println("Creating backup...")
-	if _, err := p.backupBinary(serverPath); err != nil {
-		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create backup: %v", err)}
+	originalHash, err := util.FileSHA256(serverPath)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to hash JAR: %v", err)}
 	}
 
 	progressCallback("Opening server JAR...", 20)
@@ -418,76 +519,88 @@ func (p *ClientPatcher) PatchServer(serverPath string, progressCallback func(msg
 
 	fmt.Printf("JAR contains %d entries\n", len(reader.File))
 
-	progressCallback("Patching class files...", 40)
-	fmt.Println("Scanning JAR entries for domain references...")
-
-	oldUTF8 := stringToUTF8(OriginalDomain)
-	totalCount := 0
-
-	// Create new JAR in memory
-	var buf bytes.Buffer
-	writer := zip.NewWriter(&buf)
-
-	for _, file := range reader.File {
-		// Only patch relevant file types
-		shouldPatch := strings.HasSuffix(file.Name, ".class") ||
-			strings.HasSuffix(file.Name, ".properties") ||
-			strings.HasSuffix(file.Name, ".json") ||
-			strings.HasSuffix(file.Name, ".xml") ||
-			strings.HasSuffix(file.Name, ".yml")
-
-		rc, err := file.Open()
-		if err != nil {
-			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to read JAR entry: %v", err)}
-		}
+	rules, err := p.rulesFor(TargetServer)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Invalid patch rules: %v", err)}
+	}
 
-		data, err := io.ReadAll(rc)
-		rc.Close()
-		if err != nil {
-			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to read entry data: %v", err)}
-		}
+	// Stream the patched JAR to a temp file next to the original. The original is
+	// only replaced by an atomic rename once the new JAR is complete, so an
+	// interrupted run leaves it untouched and can simply be retried.
+	tmpPath := serverPath + ".patching"
+	if _, err := os.Stat(tmpPath); err == nil {
+		fmt.Println("Removing leftover JAR from an interrupted patch")
+		os.Remove(tmpPath)
+	}
 
-		// Only patch if domain is present (optimization)
-		if shouldPatch && bytes.Contains(data, oldUTF8) {
-			patchedData, count := p.findAndReplaceDomainUTF8(data)
-			if count > 0 {
-				totalCount += count
-				data = patchedData
-			}
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create temp JAR: %v", err)}
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			out.Close()
+			os.Remove(tmpPath)
 		}
+	}()
 
-		// Write entry to new JAR
-		header, err := zip.FileInfoHeader(file.FileInfo())
-		if err != nil {
-			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create header: %v", err)}
-		}
-		header.Name = file.Name
-		header.Method = file.Method
+	hasher := sha256.New()
+	writer := zip.NewWriter(io.MultiWriter(out, hasher))
 
-		w, err := writer.CreateHeader(header)
-		if err != nil {
-			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create entry: %v", err)}
-		}
+	progressCallback("Patching class files...", 30)
+	fmt.Println("Scanning JAR entries for domain references...")
 
-		if _, err := w.Write(data); err != nil {
-			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to write entry: %v", err)}
-		}
+	counts, err := p.rewriteJAR(reader, writer, rules, func(done, total int) {
+		percent := 30 + done*50/total
+		progressCallback(fmt.Sprintf("Patching server entries (%d/%d)...", done, total), percent)
+	})
+	if err != nil {
+		return PatchResult{Success: false, Error: err.Error()}
 	}
 
 	if err := writer.Close(); err != nil {
 		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to close JAR: %v", err)}
 	}
 
+	ruleMatches := make(map[string]int)
+	totalCount := 0
+	for i, rule := range rules {
+		fmt.Printf("  Rule %s: %d match(es)\n", rule.rule.ID, counts[i])
+		if err := rule.rule.checkCount(counts[i]); err != nil {
+			return PatchResult{Success: false, Error: err.Error()}
+		}
+		ruleMatches[rule.rule.ID] = counts[i]
+		totalCount += counts[i]
+	}
+
 	if totalCount == 0 {
 		fmt.Println("No occurrences of hytale.com found in server JAR entries")
 		return PatchResult{Success: true, PatchCount: 0}
 	}
 
-	progressCallback("Writing patched JAR...", 80)
+	if err := out.Sync(); err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to flush patched JAR: %v", err)}
+	}
+	if err := out.Close(); err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to close patched JAR: %v", err)}
+	}
+	// Release the original before replacing it (required on Windows)
+	reader.Close()
+
+	progressCallback("Creating backup...", 85)
+	fmt.Println("Creating backup...")
+	_, backupHash, err := p.backupBinary(serverPath, originalHash)
+	if err != nil {
+		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to create backup: %v", err)}
+	}
+
+	progressCallback("Writing patched JAR...", 90)
 	fmt.Println("Writing patched JAR...")
-	if err := os.WriteFile(serverPath, buf.Bytes(), 0644); err != nil {
+	if err := os.Rename(tmpPath, serverPath); err != nil {
 		return PatchResult{Success: false, Error: fmt.Sprintf("Failed to write patched JAR: %v", err)}
 	}
+	committed = true
 
 	if err := p.markAsPatched(serverPath); err != nil {
 		fmt.Printf("Warning: Failed to mark as patched: %v\n", err)
@@ -497,7 +610,84 @@ func (p *ClientPatcher) PatchServer(serverPath string, progressCallback func(msg
 	fmt.Printf("Successfully patched %d occurrences in server\n", totalCount)
 	fmt.Println("=== Server Patching Complete ===")
 
-	return PatchResult{Success: true, PatchCount: totalCount}
+	return PatchResult{Success: true, PatchCount: totalCount, Files: []PatchedFile{
+		p.newPatchedFile(serverPath, TargetServer, originalHash, hex.EncodeToString(hasher.Sum(nil)), backupHash, totalCount, ruleMatches),
+	}}
+}
+
+// rewriteJAR copies every entry of reader into writer, applying the rules to
+// matching entries. Entries without matches are copied raw without recompressing;
+// only patched entries are decompressed and written again. Returns per-rule counts.
+func (p *ClientPatcher) rewriteJAR(reader *zip.ReadCloser, writer *zip.Writer, rules []*compiledRule, onEntry func(done, total int)) ([]int, error) {
+	counts := make([]int, len(rules))
+	total := len(reader.File)
+	lastPercent := -1
+
+	for n, file := range reader.File {
+		if percent := n * 100 / total; percent != lastPercent {
+			lastPercent = percent
+			onEntry(n, total)
+		}
+
+		// Only read entries matched by a rule
+		var entryRules []int
+		for i, rule := range rules {
+			if rule.rule.matchesEntry(file.Name) {
+				entryRules = append(entryRules, i)
+			}
+		}
+
+		var data []byte
+		patched := false
+		if len(entryRules) > 0 {
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JAR entry %s: %w", file.Name, err)
+			}
+			data, err = io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read entry data %s: %w", file.Name, err)
+			}
+
+			// Only patch if a search pattern is present (optimization)
+			for _, i := range entryRules {
+				if rules[i].contains(data) {
+					count := rules[i].apply(data, nil)
+					counts[i] += count
+					patched = patched || count > 0
+				}
+			}
+		}
+
+		if !patched {
+			if err := writer.Copy(file); err != nil {
+				return nil, fmt.Errorf("failed to copy entry %s: %w", file.Name, err)
+			}
+			continue
+		}
+
+		header := &zip.FileHeader{
+			Name:     file.Name,
+			Comment:  file.Comment,
+			Method:   file.Method,
+			Modified: file.Modified,
+		}
+		header.SetMode(file.Mode())
+
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create entry %s: %w", file.Name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write entry %s: %w", file.Name, err)
+		}
+	}
+
+	if total > 0 {
+		onEntry(total, total)
+	}
+	return counts, nil
 }
 
 // EnsurePatched ensures both client and server are patched before launching
@@ -509,6 +699,21 @@ func (p *ClientPatcher) EnsurePatched(gameDir string, progressCallback func(msg
 	result := PatchResult{Success: true}
 	totalPatches := 0
 
+	// A game patched for another domain no longer contains the original domain,
+	// so it has to be restored before it can be patched for the new one
+	if domain := PatchedDomain(gameDir); domain != "" && domain != p.targetDomain {
+		fmt.Printf("Game is patched for %s, restoring originals before patching for %s\n", domain, p.targetDomain)
+		progressCallback("Auth domain changed, restoring original binaries...", 5)
+		restoreResult := p.RestorePatched(gameDir, nil)
+		if !restoreResult.Success {
+			return PatchResult{Success: false, Error: fmt.Sprintf("Failed to restore binaries patched for %s: %s", domain, restoreResult.Error)}
+		}
+		if remaining := PatchedDomain(gameDir); remaining != "" && remaining != p.targetDomain {
+			return PatchResult{Success: false, Error: fmt.Sprintf("Game is still patched for %s and could not be restored (missing backup?)", remaining)}
+		}
+		result.Repatched = true
+	}
+
 	// Patch client
 	clientPath := p.FindClientPath(gameDir)
 	if clientPath != "" {
@@ -521,6 +726,7 @@ func (p *ClientPatcher) EnsurePatched(gameDir string, progressCallback func(msg
 		}
 		totalPatches += clientResult.PatchCount
 		result.AlreadyPatched = clientResult.AlreadyPatched
+		result.Files = append(result.Files, clientResult.Files...)
 	} else {
 		fmt.Println("Warning: Could not find HytaleClient binary")
 	}
@@ -538,11 +744,18 @@ func (p *ClientPatcher) EnsurePatched(gameDir string, progressCallback func(msg
 		} else {
 			totalPatches += serverResult.PatchCount
 			result.AlreadyPatched = result.AlreadyPatched && serverResult.AlreadyPatched
+			result.Files = append(result.Files, serverResult.Files...)
 		}
 	} else {
 		fmt.Println("Warning: Could not find HytaleServer.jar (this is OK for client-only)")
 	}
 
+	if len(result.Files) > 0 {
+		if err := p.recordPatchedFiles(gameDir, result.Files); err != nil {
+			fmt.Printf("Warning: Failed to write patch manifest: %v\n", err)
+		}
+	}
+
 	result.PatchCount = totalPatches
 	progressCallback("Patching complete", 100)
 	return result
@@ -556,11 +769,20 @@ func (p *ClientPatcher) RestorePatched(gameDir string, progressCallback func(msg
 
 	result := PatchResult{Success: true}
 
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil {
+		fmt.Printf("Warning: Could not read patch manifest, restoring without verification: %v\n", err)
+	}
+
 	// Restore client
 	clientPath := p.FindClientPath(gameDir)
 	if clientPath != "" {
 		progressCallback("Restoring client binary...", 25)
-		if err := p.RestoreBinary(clientPath); err != nil {
+		if err := p.restoreVerified(gameDir, clientPath, manifest); err != nil {
+			if errors.Is(err, errBackupMismatch) {
+				result.Success = false
+				result.Error = err.Error()
+			}
 			fmt.Printf("Warning: Could not restore client: %v\n", err)
 		} else {
 			// Re-sign on macOS after restore
@@ -579,11 +801,21 @@ func (p *ClientPatcher) RestorePatched(gameDir string, progressCallback func(msg
 	serverPath := p.FindServerPath(gameDir)
 	if serverPath != "" {
 		progressCallback("Restoring server JAR...", 75)
-		if err := p.RestoreBinary(serverPath); err != nil {
+		if err := p.restoreVerified(gameDir, serverPath, manifest); err != nil {
+			if errors.Is(err, errBackupMismatch) {
+				result.Success = false
+				result.Error = err.Error()
+			}
 			fmt.Printf("Warning: Could not restore server: %v\n", err)
 		}
 	}
 
+	if manifest != nil {
+		if err := savePatchManifest(gameDir, manifest); err != nil {
+			fmt.Printf("Warning: Failed to update patch manifest: %v\n", err)
+		}
+	}
+
 	progressCallback("Restore complete", 100)
 	return result
 }
@@ -0,0 +1,143 @@
+package patcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newGameDir creates a game directory with a client that references the original domain
+func newGameDir(t *testing.T) (string, string, []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+
+	gameDir := filepath.Join(dir, "release-v1")
+	clientPath := filepath.Join(gameDir, "Client", "HytaleClient")
+	if err := os.MkdirAll(filepath.Dir(clientPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := append([]byte("header "), stringToUTF16LE("https://"+OriginalDomain+"/auth")...)
+	if err := os.WriteFile(clientPath, original, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return gameDir, clientPath, original
+}
+
+func TestBackupsLiveOutsideTheClientFolder(t *testing.T) {
+	gameDir, clientPath, original := newGameDir(t)
+
+	if result := NewClientPatcher("").EnsurePatched(gameDir, nil); !result.Success || result.PatchCount == 0 {
+		t.Fatalf("EnsurePatched = %+v", result)
+	}
+	if _, err := os.Stat(clientPath + ".original"); !os.IsNotExist(err) {
+		t.Errorf("backup was written next to the client: %v", err)
+	}
+	backup, err := os.ReadFile(filepath.Join(gameDir, backupDirName, "Client", "HytaleClient.original"))
+	if err != nil {
+		t.Fatalf("backup not in %s: %v", backupDirName, err)
+	}
+	if !bytes.Equal(backup, original) {
+		t.Error("backup doesn't hold the original client")
+	}
+	if v, err := VerifyPatch(gameDir); err != nil || v.Status != "ok" {
+		t.Errorf("VerifyPatch = %+v, %v", v, err)
+	}
+
+	// Switching domains restores from the backup before patching again
+	other := "example.io"
+	if result := NewClientPatcher(other).EnsurePatched(gameDir, nil); !result.Success || !result.Repatched {
+		t.Fatalf("re-patching for %s = %+v", other, result)
+	}
+	if got := PatchedDomain(gameDir); got != other {
+		t.Errorf("PatchedDomain = %q, want %q", got, other)
+	}
+
+	if result := NewClientPatcher(other).RestorePatched(gameDir, nil); !result.Success {
+		t.Fatalf("RestorePatched = %+v", result)
+	}
+	restored, err := os.ReadFile(clientPath)
+	if err != nil || !bytes.Equal(restored, original) {
+		t.Errorf("client not restored to the original: %v", err)
+	}
+}
+
+func TestLegacyBackupIsMoved(t *testing.T) {
+	gameDir, clientPath, original := newGameDir(t)
+
+	// Older launchers kept the backup next to the binary
+	p := NewClientPatcher("")
+	if result := p.EnsurePatched(gameDir, nil); !result.Success {
+		t.Fatalf("EnsurePatched = %+v", result)
+	}
+	backupPath := backupPathFor(clientPath)
+	if err := os.Rename(backupPath, clientPath+".original"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.RestoreBinary(clientPath); err != nil {
+		t.Fatalf("RestoreBinary: %v", err)
+	}
+	restored, err := os.ReadFile(clientPath)
+	if err != nil || !bytes.Equal(restored, original) {
+		t.Errorf("client not restored from the legacy backup: %v", err)
+	}
+	if _, err := os.Stat(backupPath); err != nil {
+		t.Errorf("legacy backup wasn't moved to %s: %v", backupPath, err)
+	}
+}
+
+// resign stands in for codesign, which rewrites the signature inside the binary
+func resign(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("signature"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignedClientVerifies(t *testing.T) {
+	gameDir, clientPath, _ := newGameDir(t)
+	if result := NewClientPatcher("").EnsurePatched(gameDir, nil); !result.Success {
+		t.Fatalf("EnsurePatched = %+v", result)
+	}
+
+	recordSigned := TrackSigning(gameDir)
+	resign(t, clientPath)
+	recordSigned()
+	if v, err := VerifyPatch(gameDir); err != nil || v.Status != "ok" {
+		t.Errorf("VerifyPatch after signing = %+v, %v", v, err)
+	}
+
+	// A client changed before signing stays modified
+	if err := os.WriteFile(clientPath, []byte("tampered"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	recordSigned = TrackSigning(gameDir)
+	resign(t, clientPath)
+	recordSigned()
+	if v, err := VerifyPatch(gameDir); err != nil || v.Status != "modified" {
+		t.Errorf("VerifyPatch of a tampered client = %+v, %v", v, err)
+	}
+}
+
+func TestBrokenRulesRefuseToPatch(t *testing.T) {
+	gameDir, clientPath, original := newGameDir(t)
+	defer func(data []byte) { defaultRulesJSON = data }(defaultRulesJSON)
+	defaultRulesJSON = []byte(`{"version": 0}`)
+
+	if result := NewClientPatcher("").EnsurePatched(gameDir, nil); result.Success {
+		t.Fatalf("EnsurePatched with broken rules = %+v, want a failure", result)
+	}
+	data, err := os.ReadFile(clientPath)
+	if err != nil || !bytes.Equal(data, original) {
+		t.Errorf("client was changed: %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+package patcher
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"HyVanila/internal/env"
+)
+
+// Patch targets
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+)
+
+// Supported rule encodings
+const (
+	// EncodingUTF8 matches the search string as raw UTF-8 bytes (Java class files, resources)
+	EncodingUTF8 = "utf8"
+	// EncodingUTF16LE matches the search string as UTF-16LE (.NET string literals)
+	EncodingUTF16LE = "utf16le"
+	// EncodingUTF16LESmart matches all but the last char as UTF-16LE and only the first
+	// byte of the last char, since .NET AOT may store a length/format byte right after it
+	EncodingUTF16LESmart = "utf16le-smart"
+)
+
+// rulesFileName is the name of the rule set override file in the patches directory
+const rulesFileName = "rules.json"
+
+//go:embed rules/default.json
+var defaultRulesJSON []byte
+
+// RuleSet is a versioned collection of patch rules
+type RuleSet struct {
+	Version int    `json:"version"`
+	Rules   []Rule `json:"rules"`
+	// Source is where the rule set was loaded from ("embedded" or a file path)
+	Source string `json:"-"`
+}
+
+// Rule describes a single byte substitution applied to the client binary or server JAR.
+// Search and Replace may use the {{original}} and {{target}} placeholders for the
+// original and configured auth domains.
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+	// Target is either "client" or "server"
+	Target string `json:"target"`
+	// Entries are glob patterns for server JAR entries. Patterns without a slash
+	// match the entry's base name, otherwise the full entry path.
+	Entries  []string `json:"entries,omitempty"`
+	Encoding string   `json:"encoding"`
+	Search   string   `json:"search,omitempty"`
+	Replace  string   `json:"replace,omitempty"`
+	// SearchHex/ReplaceHex allow raw byte patterns instead of encoded strings
+	SearchHex  string `json:"searchHex,omitempty"`
+	ReplaceHex string `json:"replaceHex,omitempty"`
+	// MinMatches/MaxMatches bound the expected number of matches (0 = no bound)
+	MinMatches int `json:"minMatches,omitempty"`
+	MaxMatches int `json:"maxMatches,omitempty"`
+	// Branches and MinVersion/MaxVersion restrict which game versions the rule applies to
+	Branches   []string `json:"branches,omitempty"`
+	MinVersion int      `json:"minVersion,omitempty"`
+	MaxVersion int      `json:"maxVersion,omitempty"`
+}
+
+// compiledRule is a rule with its search/replace patterns resolved to bytes
+type compiledRule struct {
+	rule    Rule
+	search  []byte
+	replace []byte
+	// smart matching: search/replace hold all but the last char, last* the final byte
+	smart       bool
+	lastSearch  byte
+	lastReplace byte
+}
+
+// GetPatchesDir returns the directory holding user-provided patch rule overrides
+func GetPatchesDir() string {
+	return filepath.Join(env.GetDefaultAppDir(), "patches")
+}
+
+// LoadRuleSet returns the active rule set. A rules.json in the patches directory
+// overrides the embedded rules if it is valid and not older than them.
+func LoadRuleSet() (*RuleSet, error) {
+	embedded, err := parseRuleSet(defaultRulesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("embedded patch rules are invalid: %w", err)
+	}
+	embedded.Source = "embedded"
+
+	overridePath := filepath.Join(GetPatchesDir(), rulesFileName)
+	data, err := os.ReadFile(overridePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Printf("Warning: Could not read patch rules override: %v\n", err)
+		}
+		return embedded, nil
+	}
+
+	override, err := parseRuleSet(data)
+	if err != nil {
+		fmt.Printf("Warning: Ignoring invalid patch rules override %s: %v\n", overridePath, err)
+		return embedded, nil
+	}
+	if override.Version < embedded.Version {
+		fmt.Printf("Warning: Ignoring patch rules override v%d (embedded rules are v%d)\n", override.Version, embedded.Version)
+		return embedded, nil
+	}
+
+	override.Source = overridePath
+	fmt.Printf("Using patch rules v%d from %s\n", override.Version, overridePath)
+	return override, nil
+}
+
+// parseRuleSet decodes and validates a rule set
+func parseRuleSet(data []byte) (*RuleSet, error) {
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	if set.Version <= 0 {
+		return nil, fmt.Errorf("rule set version must be positive")
+	}
+	seen := make(map[string]bool)
+	for i, rule := range set.Rules {
+		if rule.ID == "" {
+			return nil, fmt.Errorf("rule %d has no id", i)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.ID, err)
+		}
+	}
+	return &set, nil
+}
+
+// validate checks a rule for structural errors that don't depend on the target domain
+func (r Rule) validate() error {
+	switch r.Target {
+	case TargetClient, TargetServer:
+	default:
+		return fmt.Errorf("unknown target %q", r.Target)
+	}
+	switch r.Encoding {
+	case EncodingUTF8, EncodingUTF16LE, EncodingUTF16LESmart:
+	default:
+		if r.SearchHex == "" {
+			return fmt.Errorf("unknown encoding %q", r.Encoding)
+		}
+	}
+	if r.Search == "" && r.SearchHex == "" {
+		return fmt.Errorf("search pattern is empty")
+	}
+	if r.Target == TargetServer && len(r.Entries) == 0 {
+		return fmt.Errorf("server rules need at least one entry pattern")
+	}
+	for _, pattern := range r.Entries {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad entry pattern %q: %w", pattern, err)
+		}
+	}
+	if r.MaxMatches > 0 && r.MinMatches > r.MaxMatches {
+		return fmt.Errorf("minMatches %d exceeds maxMatches %d", r.MinMatches, r.MaxMatches)
+	}
+	return nil
+}
+
+// appliesTo reports whether the rule applies to the given branch and build number.
+// A build of 0 (unknown) only matches rules without version bounds.
+func (r Rule) appliesTo(branch string, build int) bool {
+	if len(r.Branches) > 0 && branch != "" {
+		found := false
+		for _, b := range r.Branches {
+			if b == branch {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.MinVersion > 0 && (build == 0 || build < r.MinVersion) {
+		return false
+	}
+	if r.MaxVersion > 0 && (build == 0 || build > r.MaxVersion) {
+		return false
+	}
+	return true
+}
+
+// matchesEntry reports whether a JAR entry name matches one of the rule's entry patterns
+func (r Rule) matchesEntry(name string) bool {
+	for _, pattern := range r.Entries {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCount verifies a match count against the rule's expected bounds
+func (r Rule) checkCount(count int) error {
+	if count < r.MinMatches {
+		return fmt.Errorf("rule %q matched %d times, expected at least %d", r.ID, count, r.MinMatches)
+	}
+	if r.MaxMatches > 0 && count > r.MaxMatches {
+		return fmt.Errorf("rule %q matched %d times, expected at most %d", r.ID, count, r.MaxMatches)
+	}
+	return nil
+}
+
+// compile resolves the rule's placeholders and encoding into byte patterns
+func (r Rule) compile(original, target string) (*compiledRule, error) {
+	cr := &compiledRule{rule: r}
+
+	if r.SearchHex != "" {
+		search, err := hex.DecodeString(r.SearchHex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: bad searchHex: %w", r.ID, err)
+		}
+		replace, err := hex.DecodeString(r.ReplaceHex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: bad replaceHex: %w", r.ID, err)
+		}
+		cr.search, cr.replace = search, replace
+	} else {
+		expand := strings.NewReplacer("{{original}}", original, "{{target}}", target)
+		search := expand.Replace(r.Search)
+		replace := expand.Replace(r.Replace)
+		if len(search) != len(replace) {
+			return nil, fmt.Errorf("rule %q: search %q and replace %q must have the same length", r.ID, search, replace)
+		}
+
+		switch r.Encoding {
+		case EncodingUTF8:
+			cr.search, cr.replace = stringToUTF8(search), stringToUTF8(replace)
+		case EncodingUTF16LE:
+			cr.search, cr.replace = stringToUTF16LE(search), stringToUTF16LE(replace)
+		case EncodingUTF16LESmart:
+			if len(search) < 2 {
+				return nil, fmt.Errorf("rule %q: smart UTF-16 search must be at least 2 chars", r.ID)
+			}
+			cr.smart = true
+			cr.search = stringToUTF16LE(search[:len(search)-1])
+			cr.replace = stringToUTF16LE(replace[:len(replace)-1])
+			cr.lastSearch = search[len(search)-1]
+			cr.lastReplace = replace[len(replace)-1]
+		}
+	}
+
+	if len(cr.search) == 0 {
+		return nil, fmt.Errorf("rule %q: search pattern is empty", r.ID)
+	}
+	if len(cr.search) != len(cr.replace) {
+		return nil, fmt.Errorf("rule %q: search and replace must have the same byte length", r.ID)
+	}
+	return cr, nil
+}
+
+// apply replaces all matches of the rule in data in-place and returns the match count.
+// onMatch, if set, is called with each match offset before the bytes are replaced.
+func (cr *compiledRule) apply(data []byte, onMatch func(offset int)) int {
+	count := 0
+	pos := 0
+	for pos < len(data) {
+		idx := bytes.Index(data[pos:], cr.search)
+		if idx == -1 {
+			break
+		}
+		idx += pos
+
+		if cr.smart {
+			lastCharPos := idx + len(cr.search)
+			if lastCharPos+1 > len(data) || data[lastCharPos] != cr.lastSearch {
+				pos = idx + 1
+				continue
+			}
+			if onMatch != nil {
+				onMatch(idx)
+			}
+			copy(data[idx:], cr.replace)
+			data[lastCharPos] = cr.lastReplace
+			count++
+			pos = idx + 1
+			continue
+		}
+
+		if onMatch != nil {
+			onMatch(idx)
+		}
+		copy(data[idx:], cr.replace)
+		count++
+		pos = idx + len(cr.search)
+	}
+	return count
+}
+
+// contains reports whether data holds at least one match of the rule
+func (cr *compiledRule) contains(data []byte) bool {
+	if !cr.smart {
+		return bytes.Contains(data, cr.search)
+	}
+	pos := 0
+	for pos < len(data) {
+		idx := bytes.Index(data[pos:], cr.search)
+		if idx == -1 {
+			return false
+		}
+		idx += pos
+		lastCharPos := idx + len(cr.search)
+		if lastCharPos < len(data) && data[lastCharPos] == cr.lastSearch {
+			return true
+		}
+		pos = idx + 1
+	}
+	return false
+}
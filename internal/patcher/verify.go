@@ -0,0 +1,586 @@
+package patcher
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"HyVanila/internal/util"
+)
+
+// manifestFileName is the patch manifest stored in each instance's game directory
+const manifestFileName = ".patch_manifest.json"
+
+// backupDirName is the folder next to the patch manifest that holds the originals
+// of patched files. Backups inside Hytale.app would break codesign --deep.
+const backupDirName = ".patch_backups"
+
+// maxDryRunMatches caps the number of matches listed per file in a dry-run report
+const maxDryRunMatches = 500
+
+// dryRunContext is the number of bytes shown before and after each dry-run match
+const dryRunContext = 24
+
+// errBackupMismatch is returned when a backup no longer matches the recorded hash
+var errBackupMismatch = errors.New("backup does not match the patch manifest")
+
+// PatchManifest records what was patched in an instance and the hashes involved
+type PatchManifest struct {
+	TargetDomain   string        `json:"targetDomain"`
+	RuleSetVersion int           `json:"ruleSetVersion"`
+	GameBuild      int           `json:"gameBuild"`
+	UpdatedAt      string        `json:"updatedAt"`
+	Files          []PatchedFile `json:"files"`
+}
+
+// PatchedFile records a single patched file. Path is relative to the game directory.
+type PatchedFile struct {
+	Path           string `json:"path"`
+	Target         string `json:"target"`
+	OriginalSHA256 string `json:"originalSha256"`
+	PatchedSHA256  string `json:"patchedSha256"`
+	BackupSHA256   string `json:"backupSha256"`
+	// SignedSHA256 is the patched file after the launch re-signed it (macOS)
+	SignedSHA256 string         `json:"signedSha256,omitempty"`
+	PatchCount   int            `json:"patchCount"`
+	RuleMatches  map[string]int `json:"ruleMatches,omitempty"`
+	PatchedAt    string         `json:"patchedAt"`
+}
+
+// PatchVerification is the result of checking an instance against its patch manifest
+type PatchVerification struct {
+	// Status is one of: ok, not-patched, unverified, partial, modified, game-updated, backup-invalid
+	Status       string             `json:"status"`
+	Patched      bool               `json:"patched"`
+	TargetDomain string             `json:"targetDomain"`
+	Files        []FileVerification `json:"files"`
+	Issues       []string           `json:"issues,omitempty"`
+}
+
+// FileVerification is the verification result for a single patched file
+type FileVerification struct {
+	Path string `json:"path"`
+	// Status is one of: ok, unpatched, modified, missing
+	Status         string `json:"status"`
+	CurrentSHA256  string `json:"currentSha256"`
+	ExpectedSHA256 string `json:"expectedSha256"`
+	// BackupStatus is one of: ok, missing, mismatch
+	BackupStatus string `json:"backupStatus"`
+}
+
+// DryRunReport lists what patching an instance would change without writing anything
+type DryRunReport struct {
+	TargetDomain   string       `json:"targetDomain"`
+	RuleSetVersion int          `json:"ruleSetVersion"`
+	RuleSetSource  string       `json:"ruleSetSource"`
+	TotalMatches   int          `json:"totalMatches"`
+	Files          []DryRunFile `json:"files"`
+}
+
+// DryRunFile lists the matches found in one file
+type DryRunFile struct {
+	Path           string       `json:"path"`
+	Target         string       `json:"target"`
+	AlreadyPatched bool         `json:"alreadyPatched"`
+	MatchCount     int          `json:"matchCount"`
+	Matches        []PatchMatch `json:"matches"`
+	Errors         []string     `json:"errors,omitempty"`
+}
+
+// PatchMatch describes a single match. Offset is within the file, or within the
+// uncompressed JAR entry when Entry is set.
+type PatchMatch struct {
+	RuleID  string `json:"ruleId"`
+	Entry   string `json:"entry,omitempty"`
+	Offset  int64  `json:"offset"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Context string `json:"context"`
+}
+
+// loadPatchManifest reads the patch manifest for a game directory (nil if there is none)
+func loadPatchManifest(gameDir string) (*PatchManifest, error) {
+	data, err := os.ReadFile(filepath.Join(gameDir, manifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var manifest PatchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// savePatchManifest writes the patch manifest, removing it once no files are left
+func savePatchManifest(gameDir string, manifest *PatchManifest) error {
+	path := filepath.Join(gameDir, manifestFileName)
+	if len(manifest.Files) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	manifest.UpdatedAt = time.Now().Format(time.RFC3339)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// backupPathFor returns where the original of a patched file is kept: in the
+// backup folder of its game directory, under the file's path in the game directory
+func backupPathFor(binaryPath string) string {
+	gameDir := gameDirOf(binaryPath)
+	rel, err := filepath.Rel(gameDir, binaryPath)
+	if err != nil {
+		return binaryPath + ".original"
+	}
+	return filepath.Join(gameDir, backupDirName, rel+".original")
+}
+
+// gameDirOf returns the game directory a client or server file belongs to
+func gameDirOf(path string) string {
+	for dir := filepath.Dir(path); filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+		if name := filepath.Base(dir); name == "Client" || name == "Server" {
+			return filepath.Dir(dir)
+		}
+	}
+	return filepath.Dir(path)
+}
+
+// existingBackup returns the backup of a patched file, or "" if there is none.
+// A backup older launchers left next to the file is moved into the backup folder.
+func existingBackup(binaryPath string) string {
+	backupPath := backupPathFor(binaryPath)
+	if _, err := os.Stat(backupPath); err == nil {
+		return backupPath
+	}
+	legacyPath := binaryPath + ".original"
+	if _, err := os.Stat(legacyPath); err != nil {
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Dir(backupPath), 0755); err == nil {
+		if err := os.Rename(legacyPath, backupPath); err == nil {
+			return backupPath
+		}
+	}
+	return legacyPath
+}
+
+// find returns the manifest entry for a path relative to the game directory
+func (m *PatchManifest) find(relPath string) *PatchedFile {
+	for i := range m.Files {
+		if m.Files[i].Path == relPath {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
+
+// Matches reports whether hash is the patched file, before or after the launch re-signed it
+func (f PatchedFile) Matches(hash string) bool {
+	return hash == f.PatchedSHA256 || f.SignedSHA256 != "" && hash == f.SignedSHA256
+}
+
+// remove drops the manifest entry for a relative path
+func (m *PatchManifest) remove(relPath string) {
+	files := m.Files[:0]
+	for _, f := range m.Files {
+		if f.Path != relPath {
+			files = append(files, f)
+		}
+	}
+	m.Files = files
+}
+
+// newPatchedFile builds a manifest entry for a freshly patched file
+func (p *ClientPatcher) newPatchedFile(path, target, originalHash, patchedHash, backupHash string, count int, ruleMatches map[string]int) PatchedFile {
+	return PatchedFile{
+		Path:           path,
+		Target:         target,
+		OriginalSHA256: originalHash,
+		PatchedSHA256:  patchedHash,
+		BackupSHA256:   backupHash,
+		PatchCount:     count,
+		RuleMatches:    ruleMatches,
+		PatchedAt:      time.Now().Format(time.RFC3339),
+	}
+}
+
+// recordPatchedFiles merges freshly patched files into the instance's patch manifest
+func (p *ClientPatcher) recordPatchedFiles(gameDir string, files []PatchedFile) error {
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil || manifest == nil || manifest.TargetDomain != p.targetDomain {
+		manifest = &PatchManifest{}
+	}
+	manifest.TargetDomain = p.targetDomain
+	manifest.RuleSetVersion = p.rules.Version
+	manifest.GameBuild = p.build
+
+	for _, f := range files {
+		if rel, err := filepath.Rel(gameDir, f.Path); err == nil {
+			f.Path = filepath.ToSlash(rel)
+		}
+		manifest.remove(f.Path)
+		manifest.Files = append(manifest.Files, f)
+	}
+	return savePatchManifest(gameDir, manifest)
+}
+
+// restoreVerified restores a binary from its backup after checking the backup
+// against the patch manifest, then drops it from the manifest
+func (p *ClientPatcher) restoreVerified(gameDir, binaryPath string, manifest *PatchManifest) error {
+	var entry *PatchedFile
+	relPath := ""
+	if manifest != nil {
+		if rel, err := filepath.Rel(gameDir, binaryPath); err == nil {
+			relPath = filepath.ToSlash(rel)
+			entry = manifest.find(relPath)
+		}
+	}
+
+	if backupPath := existingBackup(binaryPath); entry != nil && backupPath != "" {
+		backupHash, err := util.FileSHA256(backupPath)
+		if err != nil {
+			return err
+		}
+		if backupHash != entry.BackupSHA256 || (entry.OriginalSHA256 != "" && backupHash != entry.OriginalSHA256) {
+			return fmt.Errorf("%w: %s (expected %s, got %s)", errBackupMismatch, filepath.Base(backupPath), entry.BackupSHA256, backupHash)
+		}
+	}
+
+	if err := p.RestoreBinary(binaryPath); err != nil {
+		return err
+	}
+	if manifest != nil && relPath != "" {
+		manifest.remove(relPath)
+	}
+	return nil
+}
+
+// VerifyPatch checks the patched files of a game directory against its patch manifest.
+// It detects tampering, partial patches and game updates that overwrote patched files.
+func VerifyPatch(gameDir string) (*PatchVerification, error) {
+	result := &PatchVerification{Files: []FileVerification{}}
+
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch manifest: %w", err)
+	}
+
+	locator := &ClientPatcher{patchedFlag: ".patched_custom"}
+	if manifest == nil {
+		// Patched before manifests existed, or never patched at all
+		for _, path := range []string{locator.FindClientPath(gameDir), locator.FindServerPath(gameDir)} {
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path + locator.patchedFlag); err == nil {
+				result.Status = "unverified"
+				result.Issues = append(result.Issues, fmt.Sprintf("%s is flagged as patched but has no manifest entry", filepath.Base(path)))
+			}
+		}
+		if result.Status == "" {
+			result.Status = "not-patched"
+		}
+		return result, nil
+	}
+
+	result.TargetDomain = manifest.TargetDomain
+	okCount, unpatchedCount, modifiedCount, backupIssues := 0, 0, 0, 0
+
+	for _, entry := range manifest.Files {
+		fullPath := filepath.Join(gameDir, filepath.FromSlash(entry.Path))
+		fv := FileVerification{Path: entry.Path, ExpectedSHA256: entry.PatchedSHA256}
+
+		currentHash, err := util.FileSHA256(fullPath)
+		switch {
+		case err != nil:
+			fv.Status = "missing"
+			modifiedCount++
+			result.Issues = append(result.Issues, fmt.Sprintf("%s is missing", entry.Path))
+		case entry.Matches(currentHash):
+			fv.Status = "ok"
+			okCount++
+		case currentHash == entry.OriginalSHA256:
+			fv.Status = "unpatched"
+			unpatchedCount++
+			result.Issues = append(result.Issues, fmt.Sprintf("%s is back to the original (unpatched) file", entry.Path))
+		default:
+			fv.Status = "modified"
+			modifiedCount++
+			result.Issues = append(result.Issues, fmt.Sprintf("%s matches neither the patched nor the original file", entry.Path))
+		}
+		fv.CurrentSHA256 = currentHash
+
+		backupHash, err := util.FileSHA256(existingBackup(fullPath))
+		switch {
+		case err != nil:
+			fv.BackupStatus = "missing"
+			backupIssues++
+			result.Issues = append(result.Issues, fmt.Sprintf("backup of %s is missing", entry.Path))
+		case backupHash != entry.BackupSHA256:
+			fv.BackupStatus = "mismatch"
+			backupIssues++
+			result.Issues = append(result.Issues, fmt.Sprintf("backup of %s does not match its recorded hash", entry.Path))
+		default:
+			fv.BackupStatus = "ok"
+		}
+
+		result.Files = append(result.Files, fv)
+	}
+
+	switch {
+	case modifiedCount > 0 && manifest.GameBuild > 0 && readGameBuild(gameDir) != manifest.GameBuild:
+		result.Status = "game-updated"
+	case modifiedCount > 0:
+		result.Status = "modified"
+	case okCount > 0 && unpatchedCount > 0:
+		result.Status = "partial"
+	case okCount == 0:
+		result.Status = "not-patched"
+	case backupIssues > 0:
+		result.Status = "backup-invalid"
+	default:
+		result.Status = "ok"
+	}
+	result.Patched = okCount == len(manifest.Files) && okCount > 0
+
+	return result, nil
+}
+
+// TrackSigning notes which patched files of a game directory still match the
+// manifest before the launch re-signs them. The returned func records their
+// signed hashes afterwards; files that didn't match are left alone, so signing
+// can't turn a modified file into a verified one.
+func TrackSigning(gameDir string) func() {
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil || manifest == nil {
+		return func() {}
+	}
+	verified := make(map[string]bool)
+	for _, entry := range manifest.Files {
+		hash, err := util.FileSHA256(filepath.Join(gameDir, filepath.FromSlash(entry.Path)))
+		if err == nil && entry.Matches(hash) {
+			verified[entry.Path] = true
+		}
+	}
+
+	return func() {
+		for i := range manifest.Files {
+			entry := &manifest.Files[i]
+			if !verified[entry.Path] {
+				continue
+			}
+			if hash, err := util.FileSHA256(filepath.Join(gameDir, filepath.FromSlash(entry.Path))); err == nil {
+				entry.SignedSHA256 = hash
+			}
+		}
+		if err := savePatchManifest(gameDir, manifest); err != nil {
+			fmt.Printf("Warning: Failed to record signed hashes: %v\n", err)
+		}
+	}
+}
+
+// PatchedFiles returns the files the patch manifest of a game directory lists as patched
+func PatchedFiles(gameDir string) ([]PatchedFile, error) {
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil || manifest == nil {
+		return nil, err
+	}
+	return manifest.Files, nil
+}
+
+// ForgetPatchedFile drops the patch state of a file that was replaced by an
+// unpatched copy (e.g. by a repair), so the next launch patches it again
+func ForgetPatchedFile(gameDir, relPath string) error {
+	os.Remove(filepath.Join(gameDir, filepath.FromSlash(relPath)) + ".patched_custom")
+
+	manifest, err := loadPatchManifest(gameDir)
+	if err != nil || manifest == nil {
+		return err
+	}
+	manifest.remove(relPath)
+	return savePatchManifest(gameDir, manifest)
+}
+
+// readGameBuild reads the build number from the instance's version.txt (0 if unknown)
+func readGameBuild(gameDir string) int {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(gameDir), "version.txt"))
+	if err != nil {
+		return 0
+	}
+	build, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return build
+}
+
+// DryRun reports every match the active rules would patch in a game directory
+// without modifying any file
+func (p *ClientPatcher) DryRun(gameDir string) (*DryRunReport, error) {
+	report := &DryRunReport{
+		TargetDomain:   p.targetDomain,
+		RuleSetVersion: p.rules.Version,
+		RuleSetSource:  p.rules.Source,
+		Files:          []DryRunFile{},
+	}
+
+	if clientPath := p.FindClientPath(gameDir); clientPath != "" {
+		file, err := p.dryRunClient(clientPath)
+		if err != nil {
+			return nil, err
+		}
+		file.Path = relativeTo(gameDir, clientPath)
+		report.TotalMatches += file.MatchCount
+		report.Files = append(report.Files, *file)
+	}
+
+	if serverPath := p.FindServerPath(gameDir); serverPath != "" {
+		file, err := p.dryRunServer(serverPath)
+		if err != nil {
+			return nil, err
+		}
+		file.Path = relativeTo(gameDir, serverPath)
+		report.TotalMatches += file.MatchCount
+		report.Files = append(report.Files, *file)
+	}
+
+	return report, nil
+}
+
+// dryRunClient collects the matches in the client binary
+func (p *ClientPatcher) dryRunClient(clientPath string) (*DryRunFile, error) {
+	result := &DryRunFile{Target: TargetClient, AlreadyPatched: p.isPatchedAlready(clientPath), Matches: []PatchMatch{}}
+
+	rules, err := p.rulesFor(TargetClient)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(clientPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client: %w", err)
+	}
+
+	for _, rule := range rules {
+		count := p.collectMatches(result, rule, "", data)
+		if err := rule.rule.checkCount(count); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	return result, nil
+}
+
+// dryRunServer collects the matches in server JAR entries
+func (p *ClientPatcher) dryRunServer(serverPath string) (*DryRunFile, error) {
+	result := &DryRunFile{Target: TargetServer, AlreadyPatched: p.isPatchedAlready(serverPath), Matches: []PatchMatch{}}
+
+	rules, err := p.rulesFor(TargetServer)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.OpenReader(serverPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JAR: %w", err)
+	}
+	defer reader.Close()
+
+	counts := make([]int, len(rules))
+	for _, file := range reader.File {
+		var data []byte
+		for i, rule := range rules {
+			if !rule.rule.matchesEntry(file.Name) {
+				continue
+			}
+			if data == nil {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read JAR entry %s: %w", file.Name, err)
+				}
+				data, err = io.ReadAll(rc)
+				rc.Close()
+				if err != nil {
+					return nil, fmt.Errorf("failed to read JAR entry %s: %w", file.Name, err)
+				}
+			}
+			if rule.contains(data) {
+				counts[i] += p.collectMatches(result, rule, file.Name, data)
+			}
+		}
+	}
+
+	for i, rule := range rules {
+		if err := rule.rule.checkCount(counts[i]); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	return result, nil
+}
+
+// collectMatches applies a rule to data (a private copy) and records each match with context
+func (p *ClientPatcher) collectMatches(result *DryRunFile, rule *compiledRule, entry string, data []byte) int {
+	matchLen := len(rule.search)
+	if rule.smart {
+		matchLen++
+	}
+
+	return rule.apply(data, func(offset int) {
+		result.MatchCount++
+		if len(result.Matches) >= maxDryRunMatches {
+			return
+		}
+		start := offset - dryRunContext
+		if start < 0 {
+			start = 0
+		}
+		end := offset + matchLen + dryRunContext
+		if end > len(data) {
+			end = len(data)
+		}
+		result.Matches = append(result.Matches, PatchMatch{
+			RuleID:  rule.rule.ID,
+			Entry:   entry,
+			Offset:  int64(offset),
+			Before:  printableBytes(data[offset : offset+matchLen]),
+			After:   printableBytes(append(append([]byte{}, rule.replace...), rule.lastReplace)[:matchLen]),
+			Context: printableBytes(data[start:end]),
+		})
+	})
+}
+
+// printableBytes renders binary data as text, dropping NUL bytes (UTF-16) and
+// replacing other non-printable bytes with dots
+func printableBytes(data []byte) string {
+	var sb strings.Builder
+	for _, b := range data {
+		switch {
+		case b == 0:
+		case b >= 32 && b < 127:
+			sb.WriteByte(b)
+		default:
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+// relativeTo returns path relative to base using forward slashes, or path unchanged
+func relativeTo(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,349 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/pwr"
+	"HyVanila/internal/storage"
+	"HyVanila/internal/util"
+	"HyVanila/internal/util/download"
+)
+
+// findLatest returns the newest build of a branch
+var findLatest = pwr.FindLatestVersion
+
+// tickInterval is how often the scheduler re-evaluates windows and the busy state
+const tickInterval = 30 * time.Second
+
+// Download states
+const (
+	StateIdle        = "idle"
+	StateDownloading = "downloading"
+	StatePaused      = "paused"
+	StateReady       = "ready"
+	StateError       = "error"
+)
+
+// Settings control when and how fast updates are pre-downloaded
+type Settings struct {
+	Enabled bool `json:"enabled"`
+	// IntervalMinutes is how often the version manifest is checked
+	IntervalMinutes int `json:"intervalMinutes"`
+	// Windows limit downloads to times of day, as "HH:MM-HH:MM" (empty = any time).
+	// A window may wrap past midnight, e.g. "22:00-06:00".
+	Windows []string `json:"windows"`
+	// MaxKBps caps the download bandwidth in KB/s (0 = unlimited)
+	MaxKBps int `json:"maxKBps"`
+}
+
+// BranchStatus is the pre-download state of one branch's "-latest" instance
+type BranchStatus struct {
+	Branch         string `json:"branch"`
+	InstalledBuild int    `json:"installedBuild"`
+	LatestBuild    int    `json:"latestBuild"`
+	State          string `json:"state"`
+	Downloaded     int64  `json:"downloaded"`
+	Total          int64  `json:"total"`
+	Error          string `json:"error,omitempty"`
+	LastChecked    string `json:"lastChecked,omitempty"` // ISO 8601 format
+}
+
+// Scheduler periodically checks for new builds and downloads their patches into
+// the cache, so the update at next launch doesn't have to wait for the download
+type Scheduler struct {
+	mu        sync.Mutex
+	settings  Settings
+	status    map[string]*BranchStatus
+	nextCheck time.Time
+	cancel    context.CancelFunc // Cancels the active download
+	active    string             // Branch being downloaded
+	releases  map[string]func()  // Cache marks of ready patches
+
+	// IsBusy reports whether downloads should pause (e.g. a game is running)
+	IsBusy func() bool
+	// OnReady is called when a branch's patch is fully downloaded
+	OnReady func(branch string, build int)
+	// OnStatus is called whenever a branch's status changes
+	OnStatus func(status BranchStatus)
+
+	wake chan struct{}
+}
+
+// NewScheduler creates a scheduler with the given settings
+func NewScheduler(settings Settings) *Scheduler {
+	return &Scheduler{
+		settings: settings,
+		status:   make(map[string]*BranchStatus),
+		releases: make(map[string]func()),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Run runs the scheduler until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	defer s.pause()
+
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// SetSettings replaces the settings and re-evaluates them right away
+func (s *Scheduler) SetSettings(settings Settings) {
+	s.mu.Lock()
+	s.settings = settings
+	s.mu.Unlock()
+	s.Wake()
+}
+
+// CheckNow checks for new builds on the next tick instead of waiting for the interval
+func (s *Scheduler) CheckNow() {
+	s.mu.Lock()
+	s.nextCheck = time.Time{}
+	s.mu.Unlock()
+	s.Wake()
+}
+
+// Wake makes the scheduler re-evaluate its state, e.g. after a game exits
+func (s *Scheduler) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns the pre-download state of every branch with a "-latest" instance
+func (s *Scheduler) Status() []BranchStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := []BranchStatus{}
+	for _, branch := range env.Branches {
+		if st, ok := s.status[branch]; ok {
+			result = append(result, *st)
+		}
+	}
+	return result
+}
+
+// ReadyBuild returns the build whose patch is downloaded for a branch (0 if none)
+func (s *Scheduler) ReadyBuild(branch string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.status[branch]; ok && st.State == StateReady {
+		return st.LatestBuild
+	}
+	return 0
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	s.mu.Lock()
+	settings := s.settings
+	downloading := s.cancel != nil
+	due := time.Now().After(s.nextCheck)
+	s.mu.Unlock()
+
+	s.releaseApplied()
+
+	allowed := settings.Enabled && inWindows(settings.Windows, time.Now()) && (s.IsBusy == nil || !s.IsBusy())
+	if !allowed {
+		s.pause()
+		return
+	}
+	if downloading {
+		return
+	}
+
+	if due {
+		s.check()
+		interval := time.Duration(settings.IntervalMinutes) * time.Minute
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		s.mu.Lock()
+		s.nextCheck = time.Now().Add(interval)
+		s.mu.Unlock()
+	}
+
+	// Start (or resume) the first branch that still needs its patch
+	for _, branch := range env.Branches {
+		s.mu.Lock()
+		st, ok := s.status[branch]
+		pending := ok && st.LatestBuild > st.InstalledBuild && (st.State == StateIdle || st.State == StatePaused)
+		s.mu.Unlock()
+		if pending {
+			s.start(ctx, branch, settings.MaxKBps)
+			return
+		}
+	}
+}
+
+// check compares each installed "-latest" instance with the newest build
+func (s *Scheduler) check() {
+	for _, branch := range env.Branches {
+		if _, err := os.Stat(env.GetInstanceGameDir(branch, 0)); err != nil {
+			continue
+		}
+		installed := env.GetInstanceBuild(branch, 0)
+		latest := findLatest(branch)
+
+		s.mu.Lock()
+		st, ok := s.status[branch]
+		if !ok {
+			st = &BranchStatus{Branch: branch, State: StateIdle}
+			s.status[branch] = st
+		}
+		st.InstalledBuild = installed
+		st.LastChecked = time.Now().Format(time.RFC3339)
+		if latest > 0 && latest != st.LatestBuild {
+			// A newer build supersedes whatever was downloaded or failed before
+			st.LatestBuild = latest
+			st.State = StateIdle
+			st.Downloaded, st.Total, st.Error = 0, 0, ""
+		} else if st.State == StateError {
+			st.State = StateIdle
+			st.Error = ""
+		}
+		snapshot := *st
+		s.mu.Unlock()
+		s.notify(snapshot)
+	}
+}
+
+// start downloads a branch's patch in the background
+func (s *Scheduler) start(ctx context.Context, branch string, maxKBps int) {
+	dlCtx, cancel := context.WithCancel(download.Background(ctx))
+	s.mu.Lock()
+	st := s.status[branch]
+	build := st.LatestBuild
+	st.State = StateDownloading
+	s.cancel = cancel
+	s.active = branch
+	snapshot := *st
+	s.mu.Unlock()
+	s.notify(snapshot)
+
+	fmt.Printf("Pre-downloading %s build %d in the background\n", branch, build)
+	go func() {
+		pwrPath := pwr.CachePath(branch, 0, build)
+		releasePatch := storage.MarkInUse(pwrPath)
+		releasePartial := storage.MarkInUse(pwrPath + ".partial")
+		releaseSegments := storage.MarkInUse(pwrPath + ".segments")
+		release := func() {
+			releasePatch()
+			releasePartial()
+			releaseSegments()
+		}
+		lastNotify := time.Time{}
+		_, err := pwr.DownloadPWRLimited(dlCtx, branch, 0, build, int64(maxKBps)*1024,
+			func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64) {
+				s.mu.Lock()
+				st.Downloaded, st.Total = downloaded, total
+				snapshot := *st
+				s.mu.Unlock()
+				if time.Since(lastNotify) > time.Second {
+					s.notify(snapshot)
+					lastNotify = time.Now()
+				}
+			})
+
+		s.mu.Lock()
+		s.cancel = nil
+		s.active = ""
+		switch {
+		case err == nil:
+			st.State = StateReady
+			if old, ok := s.releases[branch]; ok {
+				old()
+			}
+			// Keep the patch out of cache trimming until it has been applied
+			s.releases[branch] = release
+		case errors.Is(err, context.Canceled):
+			st.State = StatePaused
+			release()
+		default:
+			st.State = StateError
+			st.Error = err.Error()
+			release()
+		}
+		snapshot := *st
+		s.mu.Unlock()
+		cancel()
+		s.notify(snapshot)
+
+		if err == nil {
+			fmt.Printf("Pre-downloaded %s build %d\n", branch, build)
+			if s.OnReady != nil {
+				s.OnReady(branch, build)
+			}
+		} else if !errors.Is(err, context.Canceled) {
+			fmt.Printf("Warning: Pre-download of %s build %d failed: %v\n", branch, build, err)
+		}
+	}()
+}
+
+// pause stops the active download; it resumes from the partial file later
+func (s *Scheduler) pause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		fmt.Printf("Pausing pre-download of %s\n", s.active)
+		s.cancel()
+	}
+}
+
+// releaseApplied drops the cache marks of patches whose build is now installed
+func (s *Scheduler) releaseApplied() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for branch, release := range s.releases {
+		st := s.status[branch]
+		installed := env.GetInstanceBuild(branch, 0)
+		if st == nil || installed >= st.LatestBuild {
+			release()
+			delete(s.releases, branch)
+			if st != nil {
+				st.InstalledBuild = installed
+				st.State = StateIdle
+			}
+		}
+	}
+}
+
+func (s *Scheduler) notify(status BranchStatus) {
+	if s.OnStatus != nil {
+		s.OnStatus(status)
+	}
+}
+
+// ValidateWindow checks a "HH:MM-HH:MM" schedule window
+func ValidateWindow(window string) error {
+	_, _, err := util.ParseTimeWindow(window)
+	return err
+}
+
+// inWindows reports whether t falls into any window (always true without windows)
+func inWindows(windows []string, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, window := range windows {
+		if util.InTimeWindow(window, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package prefetch
+
+import (
+	"os"
+	"testing"
+
+	"HyVanila/internal/env"
+)
+
+func TestCheckFindsPreReleaseInstance(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	if err := env.CreateFolders(); err != nil {
+		t.Fatal(err)
+	}
+	defer func(f func(string) int) { findLatest = f }(findLatest)
+	findLatest = func(branch string) int { return 8 }
+
+	if err := os.MkdirAll(env.GetInstanceGameDir("pre-release", 0), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScheduler(Settings{})
+	s.check()
+	status := s.Status()
+	if len(status) != 1 || status[0].Branch != "pre-release" || status[0].LatestBuild != 8 {
+		t.Fatalf("Status = %+v, want the pre-release instance at build 8", status)
+	}
+
+	s.mu.Lock()
+	s.status["pre-release"].State = StateReady
+	s.mu.Unlock()
+	if got := s.ReadyBuild("pre-release"); got != 8 {
+		t.Errorf("ReadyBuild(pre-release) = %d, want 8", got)
+	}
+}
@@ -7,84 +7,163 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync"
 
 	"HyVanila/internal/env"
+	"HyVanila/internal/storage"
+	"HyVanila/internal/tools"
 	"HyVanila/internal/util"
 	"HyVanila/internal/util/download"
 )
 
-const (
-	butlerVersion = "15.21.0"
-	brothURL      = "https://broth.itch.zone/butler/%s-%s/LATEST/archive/default"
-)
+// toolName is butler's entry in the tools manifest and in tools.json
+const toolName = "butler"
+
+// installMu keeps two installs from writing the butler folder at once
+var installMu sync.Mutex
+
+// platform returns the broth OS and arch of the butler build for this system
+func platform() (string, string) {
+	arch := runtime.GOARCH
+	// Butler only provides darwin-amd64 (no arm64), so on macOS we always use amd64
+	// which runs through Rosetta 2 on Apple Silicon. There is no linux-arm64 build
+	// at all, so tools.json has no pin for it and installing fails there.
+	if runtime.GOOS == "darwin" {
+		arch = "amd64"
+	}
+	return runtime.GOOS, arch
+}
 
-// InstallButler installs the Butler tool
+func binaryName() string {
+	if runtime.GOOS == "windows" {
+		return "butler.exe"
+	}
+	return "butler"
+}
+
+// InstallButler makes sure butler is installed and, at most once per check
+// interval, on the pinned version. A new version is installed next to the
+// current one, which keeps being used if the update fails.
 func InstallButler(ctx context.Context, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
-	butlerDir := env.GetButlerDir()
-	butlerPath, err := GetButlerPath()
-	if err == nil {
-		if _, statErr := os.Stat(butlerPath); statErr == nil {
-			fmt.Println("Butler already installed")
-			if progressCallback != nil {
-				progressCallback("butler", 100, "Butler ready", "", "", 0, 0)
-			}
-			return butlerPath, nil
+	installMu.Lock()
+	defer installMu.Unlock()
+
+	current, _ := GetButlerPath()
+	_, statErr := os.Stat(current)
+	installed := statErr == nil
+	tool, recorded := tools.Get(toolName)
+
+	ready := func() {
+		fmt.Println("Butler already installed")
+		if progressCallback != nil {
+			progressCallback("butler", 100, "Butler ready", "", "", 0, 0)
 		}
 	}
-
-	if err := os.MkdirAll(butlerDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create butler directory: %w", err)
+	if installed && recorded && !tools.CheckDue(toolName) {
+		ready()
+		return current, nil
 	}
 
-	if progressCallback != nil {
-		progressCallback("butler", 0, "Downloading Butler...", "", "", 0, 0)
+	osName, arch := platform()
+	pin, err := tools.PinFor(toolName, osName, arch)
+	if err != nil {
+		if installed {
+			fmt.Printf("Warning: Could not check the pinned Butler version, keeping the installed one: %v\n", err)
+			ready()
+			return current, nil
+		}
+		return "", err
+	}
+	if installed && recorded && tool.Version == pin.Version {
+		tools.Record(toolName, tool)
+		ready()
+		return current, nil
 	}
 
-	osName := runtime.GOOS
-	arch := runtime.GOARCH
+	butlerPath, err := install(ctx, pin, progressCallback)
+	if err != nil {
+		if installed {
+			fmt.Printf("Warning: Could not update Butler to %s, keeping the installed one: %v\n", pin.Version, err)
+			ready()
+			return current, nil
+		}
+		return "", err
+	}
+	prune(pin.Version)
+	return butlerPath, nil
+}
 
-	// Butler only provides darwin-amd64 (no arm64), so on macOS we always use amd64
-	// which runs through Rosetta 2 on Apple Silicon
-	if osName == "darwin" {
-		arch = "amd64"
+// install downloads a butler version into its own folder and records it in the
+// tools manifest once it has been verified
+func install(ctx context.Context, pin *tools.Pin, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
+	if err := os.MkdirAll(env.GetButlerDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create butler directory: %w", err)
 	}
 
-	url := fmt.Sprintf(brothURL, osName, arch)
-	fmt.Printf("Butler download URL: %s\n", url)
-	archivePath := filepath.Join(env.GetCacheDir(), "butler.zip")
+	if progressCallback != nil {
+		progressCallback("butler", 0, fmt.Sprintf("Downloading Butler %s...", pin.Version), "", "", 0, 0)
+	}
 
-	if err := download.DownloadWithProgress(archivePath, url, "butler", 0.8, progressCallback); err != nil {
+	fmt.Printf("Butler download URL: %s\n", pin.URL)
+	archivePath := filepath.Join(env.GetCacheDir(), "butler-"+pin.Version+".zip")
+
+	// The pinned archive checksum is verified before the download is moved into place
+	err := download.Fetch(ctx, download.Request{
+		URL:      pin.URL,
+		Dest:     archivePath,
+		SHA256:   pin.SHA256,
+		Stage:    "butler",
+		Weight:   0.8,
+		Progress: progressCallback,
+	})
+	if err != nil {
 		return "", fmt.Errorf("failed to download butler: %w", err)
 	}
+	defer os.Remove(archivePath)
 
 	if progressCallback != nil {
 		progressCallback("butler", 90, "Extracting Butler...", "", "", 0, 0)
 	}
 
-	if err := util.ExtractZip(archivePath, butlerDir); err != nil {
+	versionDir := filepath.Join(env.GetButlerDir(), pin.Version)
+	staging := versionDir + ".tmp"
+	os.RemoveAll(staging)
+	if err := util.ExtractZip(archivePath, staging); err != nil {
+		os.RemoveAll(staging)
 		return "", fmt.Errorf("failed to extract butler: %w", err)
 	}
 
-	os.Remove(archivePath)
-
-	butlerPath, err = GetButlerPath()
-	if err != nil {
-		return "", err
-	}
-
+	stagedPath := filepath.Join(staging, binaryName())
 	// Make executable on Unix
 	if runtime.GOOS != "windows" {
-		os.Chmod(butlerPath, 0755)
+		os.Chmod(stagedPath, 0755)
 	}
 
 	// Verify butler works
-	cmd := exec.Command(butlerPath, "version")
+	cmd := exec.Command(stagedPath, "version")
 	util.HideConsoleWindow(cmd)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		os.RemoveAll(staging)
 		return "", fmt.Errorf("butler verification failed: %w\nOutput: %s", err, string(output))
 	}
 
+	os.RemoveAll(versionDir)
+	if err := os.Rename(staging, versionDir); err != nil {
+		os.RemoveAll(staging)
+		return "", fmt.Errorf("failed to install butler: %w", err)
+	}
+
+	butlerPath := filepath.Join(versionDir, binaryName())
+	if err := tools.Record(toolName, tools.Tool{
+		Version: pin.Version,
+		Path:    butlerPath,
+		SHA256:  pin.SHA256,
+		Source:  pin.URL,
+	}); err != nil {
+		return "", fmt.Errorf("failed to record butler install: %w", err)
+	}
+
 	fmt.Printf("Butler installed: %s\n", string(output))
 
 	if progressCallback != nil {
@@ -94,15 +173,41 @@ func InstallButler(ctx context.Context, progressCallback func(stage string, prog
 	return butlerPath, nil
 }
 
+// prune removes butler versions other than keep, and the files older launchers
+// kept directly in the butler folder, unless a running patch is using them
+func prune(keep string) {
+	butlerDir := env.GetButlerDir()
+	entries, err := os.ReadDir(butlerDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.Name() == keep {
+			continue
+		}
+		path := filepath.Join(butlerDir, entry.Name())
+		if storage.IsInUse(path) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Printf("Warning: Failed to remove old butler files %s: %v\n", path, err)
+		}
+	}
+}
+
 // GetButlerPath returns the path to the Butler executable
 func GetButlerPath() (string, error) {
-	butlerDir := env.GetButlerDir()
-	
-	name := "butler"
-	if runtime.GOOS == "windows" {
-		name = "butler.exe"
+	if tool, ok := tools.Get(toolName); ok {
+		if _, err := os.Stat(tool.Path); err == nil {
+			return tool.Path, nil
+		}
 	}
+	// Launchers before versioned installs kept butler directly in its folder
+	return filepath.Join(env.GetButlerDir(), binaryName()), nil
+}
 
-	path := filepath.Join(butlerDir, name)
-	return path, nil
+// InstalledVersion returns the butler version in use, or "" if it isn't known
+func InstalledVersion() string {
+	tool, _ := tools.Get(toolName)
+	return tool.Version
 }
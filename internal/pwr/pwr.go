@@ -3,6 +3,7 @@ package pwr
 import (
 	"HyVanila/internal/util"
 	"HyVanila/internal/pwr/butler"
+	"HyVanila/internal/storage"
 	"context"
 	"fmt"
 	"os"
@@ -10,7 +11,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 )
 
 // cleanStagingDirectory removes staging directory and any leftover temp files
@@ -60,6 +60,8 @@ func ApplyPWRToDir(ctx context.Context, pwrFile string, targetDir string, progre
 	if err != nil {
 		return fmt.Errorf("butler not found: %w", err)
 	}
+	// Keep this butler version from being pruned by an update while it patches
+	defer storage.MarkInUse(butlerPath)()
 	
 	// Clean staging directory
 	if progressCallback != nil {
@@ -101,11 +103,7 @@ func ApplyPWRToDir(ctx context.Context, pwrFile string, targetDir string, progre
 	// Clean up staging directory
 	cleanStagingDirectory(targetDir)
 
-	// Clean up patch file
-	go func() {
-		time.Sleep(2 * time.Second)
-		os.Remove(pwrFile)
-	}()
+	// The patch file stays in the cache; the cache policy decides whether it is kept for reinstalls
 
 	if progressCallback != nil {
 		progressCallback("install", 100, "Installation complete", "", "", 0, 0)
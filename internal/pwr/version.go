@@ -3,7 +3,6 @@ package pwr
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -133,9 +132,15 @@ func performVersionCheck(versionType string) VersionCheckResult {
 	return result
 }
 
+// localVersionFile returns the version file of the release-latest instance,
+// which replaced the global version.txt of the legacy layout
+func localVersionFile() string {
+	return filepath.Join(env.GetInstanceDir("release", 0), "version.txt")
+}
+
 // GetLocalVersion returns the currently installed version
 func GetLocalVersion() string {
-	versionFile := filepath.Join(env.GetDefaultAppDir(), "version.txt")
+	versionFile := localVersionFile()
 	data, err := os.ReadFile(versionFile)
 	if err != nil {
 		return ""
@@ -145,7 +150,7 @@ func GetLocalVersion() string {
 
 // GetLocalVersionFull returns a formatted version string with date
 func GetLocalVersionFull() string {
-	versionFile := filepath.Join(env.GetDefaultAppDir(), "version.txt")
+	versionFile := localVersionFile()
 	data, err := os.ReadFile(versionFile)
 	if err != nil {
 		return "Not installed"
@@ -168,12 +173,17 @@ func GetLocalVersionFull() string {
 
 // SaveLocalVersion saves the version number
 func SaveLocalVersion(version int) error {
-	versionFile := filepath.Join(env.GetDefaultAppDir(), "version.txt")
+	versionFile := localVersionFile()
 	return os.WriteFile(versionFile, []byte(strconv.Itoa(version)), 0644)
 }
 
 // DownloadPWR downloads a PWR patch file - matches Hytale-F2P implementation
 func DownloadPWR(ctx context.Context, versionType string, fromVer, toVer int, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
+	return DownloadPWRLimited(ctx, versionType, fromVer, toVer, 0, progressCallback)
+}
+
+// DownloadPWRLimited downloads a PWR patch file at no more than bytesPerSec (0 = unlimited)
+func DownloadPWRLimited(ctx context.Context, versionType string, fromVer, toVer int, bytesPerSec int64, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
 	osName := getOS()
 	arch := getArch()
 	apiVersionType := normalizeVersionType(versionType)
@@ -199,7 +209,7 @@ func DownloadPWR(ctx context.Context, versionType string, fromVer, toVer int, pr
 			osName, arch, apiVersionType, fromVer, toVer)
 		
 		// Quick check if incremental patch exists
-		client := &http.Client{Timeout: 10 * time.Second}
+		client := download.NewClient(10 * time.Second)
 		resp, err := client.Head(url)
 		if err != nil || resp.StatusCode != http.StatusOK {
 			// Incremental patch not available, use full install from 0
@@ -226,200 +236,66 @@ func DownloadPWR(ctx context.Context, versionType string, fromVer, toVer int, pr
 		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
 	
-	pwrPath := filepath.Join(cacheDir, fmt.Sprintf("%d.pwr", toVer))
+	cacheFrom := fromVer
+	if useFromZero {
+		cacheFrom = 0
+	}
+	pwrPath := CachePath(versionType, cacheFrom, toVer)
 
 	// First do a HEAD request to get expected file size
+	var expectedSize int64
 	headReq, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create HEAD request: %w", err)
 	}
-	headReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	
-	headClient := &http.Client{Timeout: 30 * time.Second}
-	headResp, err := headClient.Do(headReq)
-	var expectedSize int64
-	if err == nil && headResp.StatusCode == http.StatusOK {
-		expectedSize = headResp.ContentLength
+	headReq.Header.Set("User-Agent", download.UserAgent)
+	if headResp, err := download.GetSharedClient().Do(headReq); err == nil {
+		if headResp.StatusCode == http.StatusOK {
+			expectedSize = headResp.ContentLength
+			fmt.Printf("Expected PWR file size: %d bytes\n", expectedSize)
+		}
 		headResp.Body.Close()
-		fmt.Printf("Expected PWR file size: %d bytes\n", expectedSize)
 	}
 
-	// Check if already cached AND complete
+	// A finished download is only moved into place once complete, but patches
+	// written by older versions may still be partial
 	if info, err := os.Stat(pwrPath); err == nil && info.Size() > 0 {
-		// Verify file is complete (matches expected size or at least > 1GB for a full game patch)
-		if expectedSize > 0 && info.Size() == expectedSize {
-			fmt.Printf("PWR file found in cache (verified): %s (%d bytes)\n", pwrPath, info.Size())
-			return pwrPath, nil
-		} else if expectedSize > 0 && info.Size() < expectedSize {
-			fmt.Printf("PWR file in cache is incomplete (%d of %d bytes), re-downloading...\n", info.Size(), expectedSize)
-			os.Remove(pwrPath)
-		} else if expectedSize == 0 && info.Size() > 1024*1024*1024 {
-			// If we couldn't get expected size, assume files > 1GB are complete
+		if expectedSize <= 0 || info.Size() == expectedSize {
 			fmt.Printf("PWR file found in cache: %s (%d bytes)\n", pwrPath, info.Size())
 			return pwrPath, nil
-		} else {
-			fmt.Printf("PWR file in cache may be incomplete (%d bytes), re-downloading...\n", info.Size())
-			os.Remove(pwrPath)
 		}
+		fmt.Printf("PWR file in cache is incomplete (%d of %d bytes), re-downloading...\n", info.Size(), expectedSize)
+		os.Remove(pwrPath)
 	}
 
 	if progressCallback != nil {
 		progressCallback("download", 0, "Downloading Hytale...", filepath.Base(pwrPath), "", 0, 0)
 	}
 
-	// Download with retries and resume capability
-	maxRetries := 5
-	var lastErr error
-	
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if attempt > 1 {
-			fmt.Printf("Retry attempt %d/%d for PWR download...\n", attempt, maxRetries)
-			if progressCallback != nil {
-				progressCallback("download", 0, fmt.Sprintf("Retrying download (attempt %d/%d)...", attempt, maxRetries), filepath.Base(pwrPath), "", 0, 0)
-			}
-			time.Sleep(2 * time.Second)
-		}
-		
-		err := downloadPWRFile(ctx, url, pwrPath, expectedSize, progressCallback)
-		if err == nil {
-			return pwrPath, nil
-		}
-		
-		lastErr = err
-		fmt.Printf("Download attempt %d failed: %v\n", attempt, err)
-	}
-	
-	return "", fmt.Errorf("failed to download after %d attempts: %w", maxRetries, lastErr)
-}
-
-func downloadPWRFile(ctx context.Context, url, pwrPath string, expectedSize int64, progressCallback func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) error {
-	// Check if partial file exists
-	var resumeFrom int64 = 0
-	if stat, err := os.Stat(pwrPath); err == nil {
-		resumeFrom = stat.Size()
-		fmt.Printf("Resuming download from %d bytes\n", resumeFrom)
-	}
-
-	// Create HTTP request with proper headers (like Hytale-F2P)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	req.Header.Set("Referer", "https://launcher.hytale.com/")
-	
-	if resumeFrom > 0 {
-		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
-	}
-
-	client := &http.Client{
-		Timeout: 60 * time.Minute, // Increased timeout for large files
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to download patch: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-		return fmt.Errorf("patch not available: HTTP %d from %s", resp.StatusCode, url)
-	}
-
-	total := resp.ContentLength
-	if resp.StatusCode == http.StatusPartialContent {
-		total += resumeFrom
-	}
-	
-	if resumeFrom == 0 {
-		fmt.Printf("PWR file size: %d bytes (%.2f GB)\n", total, float64(total)/(1024*1024*1024))
-	}
-
-	// Open file for writing (append if resuming)
-	var file *os.File
-	if resp.StatusCode == http.StatusPartialContent && resumeFrom > 0 {
-		file, err = os.OpenFile(pwrPath, os.O_APPEND|os.O_WRONLY, 0644)
-	} else {
-		file, err = os.Create(pwrPath)
-		resumeFrom = 0
-	}
+	err = download.Fetch(ctx, download.Request{
+		URL:          url,
+		Dest:         pwrPath,
+		ExpectedSize: expectedSize,
+		Stage:        "download",
+		Message:      "Downloading game patch...",
+		Progress:     progressCallback,
+		BytesPerSec:  bytesPerSec,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create patch file: %w", err)
-	}
-	defer file.Close()
-
-	buf := make([]byte, 32*1024)
-	downloaded := resumeFrom
-	lastUpdate := time.Now()
-	lastDownloaded := downloaded
-
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			if _, writeErr := file.Write(buf[:n]); writeErr != nil {
-				return writeErr
-			}
-			downloaded += int64(n)
-
-			// Update progress every 100ms
-			if time.Since(lastUpdate) >= 100*time.Millisecond {
-				speed := float64(downloaded-lastDownloaded) / time.Since(lastUpdate).Seconds()
-				speedStr := formatSpeed(speed)
-				progress := float64(downloaded) / float64(total) * 100
-
-				if progressCallback != nil {
-					progressCallback("download", progress, "Downloading game patch...", filepath.Base(pwrPath), speedStr, downloaded, total)
-				}
-
-				lastUpdate = time.Now()
-				lastDownloaded = downloaded
-			}
+		if ctx.Err() != nil {
+			// Cancelled or paused; the partial file is resumed next time
+			return "", ctx.Err()
 		}
-		if err != nil {
-			if err != io.EOF {
-				return fmt.Errorf("read error: %w", err)
-			}
-			break
-		}
-	}
-
-	fmt.Printf("Download complete: %d bytes\n", downloaded)
-
-	// Verify download is complete
-	if total > 0 && downloaded < total {
-		return fmt.Errorf("download incomplete: got %d of %d bytes (%.1f%%), will retry", 
-			downloaded, total, float64(downloaded)/float64(total)*100)
-	}
-
-	// Final size verification
-	info, err := os.Stat(pwrPath)
-	if err != nil {
-		return fmt.Errorf("failed to verify downloaded file: %w", err)
+		return "", err
 	}
-	if total > 0 && info.Size() != total {
-		return fmt.Errorf("downloaded file size mismatch: expected %d, got %d bytes", total, info.Size())
-	}
-
-	fmt.Printf("Download verified: %d bytes\n", info.Size())
-	
-	if progressCallback != nil {
-		progressCallback("download", 100, "Download complete", "", "", downloaded, total)
-	}
-	
-	return nil
+	return pwrPath, nil
 }
 
-func formatSpeed(bytesPerSec float64) string {
-	if bytesPerSec < 1024 {
-		return fmt.Sprintf("%.0f B/s", bytesPerSec)
-	} else if bytesPerSec < 1024*1024 {
-		return fmt.Sprintf("%.1f KB/s", bytesPerSec/1024)
-	} else {
-		return fmt.Sprintf("%.1f MB/s", bytesPerSec/(1024*1024))
-	}
+// CachePath returns where the patch from one build of a branch to another is
+// cached. Branches number their builds independently, so the key includes the
+// branch as well as both builds.
+func CachePath(versionType string, fromVer, toVer int) string {
+	return filepath.Join(env.GetCacheDir(), fmt.Sprintf("%s-%d-%d.pwr", normalizeVersionType(versionType), fromVer, toVer))
 }
 
 // InstalledVersion represents an installed game version
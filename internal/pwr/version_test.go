@@ -0,0 +1,25 @@
+package pwr
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCachePath(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	release := CachePath("release", 0, 5)
+	if got := filepath.Base(release); got != "release-0-5.pwr" {
+		t.Errorf("CachePath(release, 0, 5) = %s", got)
+	}
+	// Builds are numbered per branch, so the same build of another branch is another file
+	if CachePath("pre-release", 0, 5) == release {
+		t.Error("release and pre-release share a cache file")
+	}
+	if CachePath("release", 4, 5) == release {
+		t.Error("an incremental patch shares the full patch's cache file")
+	}
+	if CachePath("prerelease", 0, 5) != CachePath("pre-release", 0, 5) {
+		t.Error("branch spellings map to different cache files")
+	}
+}
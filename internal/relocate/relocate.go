@@ -0,0 +1,452 @@
+package relocate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/util"
+)
+
+// Methods used to relocate instances
+const (
+	MethodRename = "rename"
+	MethodCopy   = "copy"
+)
+
+// Job is a relocation of all instances from one instances directory to another.
+// It is persisted so an interrupted relocation (e.g. an unplugged drive) can be resumed.
+type Job struct {
+	Source    string `json:"source"`
+	Dest      string `json:"dest"`
+	Method    string `json:"method"`
+	StartedAt string `json:"startedAt"` // ISO 8601 format
+	// Instances are the instance folder names being moved
+	Instances []string `json:"instances"`
+	// Moved are the instances that are complete and verified at the destination
+	Moved []string `json:"moved"`
+	// Switched is set once the instances directory points at the destination;
+	// only deleting the sources remains after that
+	Switched  bool  `json:"switched"`
+	TotalSize int64 `json:"totalSize"`
+	// SharedSize is the part of TotalSize stored once in the game file store.
+	// Copying to another drive gives every instance its own copy of it.
+	SharedSize int64 `json:"sharedSize"`
+}
+
+// ProgressFunc reports relocation progress in the launcher's progress format
+type ProgressFunc func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)
+
+func getJobPath() string {
+	return filepath.Join(env.GetDefaultAppDir(), "relocation.json")
+}
+
+// Pending returns the unfinished relocation job, or nil if there is none
+func Pending() *Job {
+	data, err := os.ReadFile(getJobPath())
+	if err != nil {
+		return nil
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		fmt.Printf("Warning: Ignoring unreadable relocation state: %v\n", err)
+		return nil
+	}
+	return &job
+}
+
+func (j *Job) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := getJobPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, getJobPath())
+}
+
+func clearJob() {
+	os.Remove(getJobPath())
+}
+
+// Plan prepares a relocation from source to dest: it lists the instances,
+// rejects name clashes at the destination and checks there is enough free space.
+// The job is saved so it can be resumed.
+func Plan(source, dest string) (*Job, error) {
+	if job := Pending(); job != nil {
+		return nil, fmt.Errorf("a relocation to %s is already in progress", job.Dest)
+	}
+
+	source = filepath.Clean(source)
+	dest = filepath.Clean(dest)
+	if samePath(source, dest) {
+		return nil, fmt.Errorf("source and destination are the same directory")
+	}
+	if isWithin(dest, source) || isWithin(source, dest) {
+		return nil, fmt.Errorf("the new instances directory can't be inside the current one or contain it")
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination: %w", err)
+	}
+
+	entries, err := os.ReadDir(source)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	job := &Job{
+		Source:    source,
+		Dest:      dest,
+		Method:    MethodCopy,
+		StartedAt: time.Now().Format(time.RFC3339),
+		Instances: []string{},
+		Moved:     []string{},
+	}
+	var clashes []string
+	for _, entry := range entries {
+		// Only instance folders move; anything else sharing the directory stays put
+		if !entry.IsDir() {
+			continue
+		}
+		if _, _, ok := env.ParseInstanceName(entry.Name()); !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dest, entry.Name())); err == nil {
+			clashes = append(clashes, entry.Name())
+			continue
+		}
+		size, err := dirSize(filepath.Join(source, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		job.Instances = append(job.Instances, entry.Name())
+		job.TotalSize += size
+	}
+	if len(clashes) > 0 {
+		return nil, fmt.Errorf("the destination already contains: %s", strings.Join(clashes, ", "))
+	}
+
+	if util.SameFileSystem(source, dest) {
+		job.Method = MethodRename
+	} else if job.TotalSize > 0 {
+		for _, name := range job.Instances {
+			shared, err := sharedSize(filepath.Join(source, name))
+			if err != nil {
+				return nil, err
+			}
+			job.SharedSize += shared
+		}
+		free, err := util.DiskFree(dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check free space: %w", err)
+		}
+		if uint64(job.TotalSize) > free {
+			return nil, fmt.Errorf("not enough free space: %s needed, %s available",
+				util.FormatBytes(job.TotalSize), util.FormatBytes(int64(free)))
+		}
+	}
+
+	if len(job.Instances) > 0 {
+		if err := job.save(); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// Run moves the instances, calls switchTo once every instance is verified at
+// the destination and then deletes the sources. Calling Run again on a job
+// loaded with Pending resumes it, skipping files that were already copied.
+func (j *Job) Run(switchTo func(dest string) error, progress ProgressFunc) error {
+	if !j.Switched {
+		if _, err := os.Stat(j.Dest); err != nil {
+			return fmt.Errorf("destination is not available (is the drive connected?): %w", err)
+		}
+
+		var done int64
+		for _, name := range j.Instances {
+			if contains(j.Moved, name) {
+				size, _ := dirSize(filepath.Join(j.Dest, name))
+				done += size
+				continue
+			}
+			src := filepath.Join(j.Source, name)
+			dst := filepath.Join(j.Dest, name)
+
+			var err error
+			if j.Method == MethodRename {
+				progress("relocate", j.percent(done), fmt.Sprintf("Moving %s...", name), name, "", done, j.TotalSize)
+				err = os.Rename(src, dst)
+			} else {
+				err = j.copyInstance(src, dst, &done, progress)
+				if err == nil {
+					progress("relocate", j.percent(done), fmt.Sprintf("Verifying %s...", name), name, "", done, j.TotalSize)
+					err = verifyTree(src, dst)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to move %s: %w", name, err)
+			}
+			if j.Method == MethodRename {
+				size, _ := dirSize(dst)
+				done += size
+			}
+
+			j.Moved = append(j.Moved, name)
+			if err := j.save(); err != nil {
+				return err
+			}
+		}
+
+		if err := switchTo(j.Dest); err != nil {
+			return fmt.Errorf("failed to switch instances directory: %w", err)
+		}
+		j.Switched = true
+		if err := j.save(); err != nil {
+			return err
+		}
+	}
+
+	if j.Method == MethodCopy {
+		progress("relocate", 100, "Removing old instance files...", "", "", j.TotalSize, j.TotalSize)
+		for _, name := range j.Instances {
+			if err := os.RemoveAll(filepath.Join(j.Source, name)); err != nil {
+				return fmt.Errorf("instances were moved, but %s could not be removed from the old location: %w", name, err)
+			}
+		}
+	}
+
+	clearJob()
+	message := "Instances moved"
+	if j.Method == MethodCopy && j.SharedSize > 0 {
+		// Hard links can't span drives, so the copies no longer share game files
+		message = fmt.Sprintf("Instances moved. Game files can't be shared across drives, so they now use %s more disk space",
+			util.FormatBytes(j.SharedSize))
+		fmt.Printf("Warning: %s of shared game files were copied per instance to %s\n", util.FormatBytes(j.SharedSize), j.Dest)
+	}
+	progress("complete", 100, message, "", "", j.TotalSize, j.TotalSize)
+	fmt.Printf("Relocated %d instance(s) from %s to %s\n", len(j.Instances), j.Source, j.Dest)
+	return nil
+}
+
+// Cancel abandons an unfinished job and undoes its partial work: renamed
+// instances are moved back and copies at the destination are deleted
+func (j *Job) Cancel() error {
+	if j.Switched {
+		return fmt.Errorf("the instances already use the new directory; resume to finish removing the old files")
+	}
+	for _, name := range j.Instances {
+		dst := filepath.Join(j.Dest, name)
+		if j.Method == MethodRename {
+			if contains(j.Moved, name) {
+				if err := os.Rename(dst, filepath.Join(j.Source, name)); err != nil {
+					return fmt.Errorf("failed to move %s back: %w", name, err)
+				}
+			}
+			continue
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return fmt.Errorf("failed to remove partial copy of %s: %w", name, err)
+		}
+	}
+	clearJob()
+	return nil
+}
+
+func (j *Job) percent(done int64) float64 {
+	if j.TotalSize == 0 {
+		return 100
+	}
+	return float64(done) / float64(j.TotalSize) * 100
+}
+
+// copyInstance copies an instance tree. Files already present at the
+// destination with the same size and modification time are kept, so a copy
+// interrupted midway resumes where it stopped.
+func (j *Job) copyInstance(src, dst string, done *int64, progress ProgressFunc) error {
+	lastReport := time.Time{}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			os.Remove(target)
+			return os.Symlink(link, target)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if existing, err := os.Stat(target); err == nil &&
+			existing.Size() == info.Size() && existing.ModTime().Equal(info.ModTime()) {
+			*done += info.Size()
+			return nil
+		}
+
+		if time.Since(lastReport) > 200*time.Millisecond {
+			progress("relocate", j.percent(*done), fmt.Sprintf("Copying %s...", filepath.Base(src)), rel, "", *done, j.TotalSize)
+			lastReport = time.Now()
+		}
+		if err := copyFile(path, target, info); err != nil {
+			return err
+		}
+		*done += info.Size()
+		return nil
+	})
+}
+
+// copyFile copies a regular file through a temp file so a partial copy never
+// looks complete, then carries over the mode and modification time
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmpPath := dst + ".relocating"
+	out, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dst)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	os.Chmod(dst, info.Mode().Perm())
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// verifyTree checks that every regular file in src exists in dst with the same content
+func verifyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		srcHash, err := util.FileSHA256(path)
+		if err != nil {
+			return err
+		}
+		dstHash, err := util.FileSHA256(filepath.Join(dst, rel))
+		if err != nil {
+			return fmt.Errorf("verification failed for %s: %w", rel, err)
+		}
+		if srcHash != dstHash {
+			// Drop the bad copy so a resumed run copies it again
+			os.Remove(filepath.Join(dst, rel))
+			return fmt.Errorf("verification failed for %s: content differs", rel)
+		}
+		return nil
+	})
+}
+
+// dirSize returns the total size of the regular files in a directory tree
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// sharedSize returns the total size of the files in a directory tree that are
+// hard-linked elsewhere, i.e. shared through the game file store
+func sharedSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if links, err := util.LinkCount(path); err != nil || links <= 1 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// isWithin reports whether path is below dir
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) && !filepath.IsAbs(rel)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package relocate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+)
+
+func TestPlanOnlyMovesInstances(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	if err := env.CreateFolders(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An instances directory pointed at a shared folder like ~/Games
+	source := filepath.Join(dir, "Games")
+	for _, name := range []string{"release-v4", "pre-release-latest", "Minecraft", "release-notes", "release-vx"} {
+		if err := os.MkdirAll(filepath.Join(source, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job, err := Plan(source, filepath.Join(dir, "Instances"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clearJob()
+
+	want := map[string]bool{"release-v4": true, "pre-release-latest": true}
+	if len(job.Instances) != len(want) {
+		t.Fatalf("Instances = %v, want %v", job.Instances, want)
+	}
+	for _, name := range job.Instances {
+		if !want[name] {
+			t.Errorf("Plan would move %s, which is not an instance", name)
+		}
+	}
+}
+
+func TestSharedSize(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "release-v1", "shared.dat")
+	if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shared, make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(shared, filepath.Join(dir, "object")); err != nil {
+		t.Skipf("hard links unsupported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "release-v1", "own.dat"), make([]byte, 50), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if size, err := sharedSize(filepath.Join(dir, "release-v1")); err != nil || size != 100 {
+		t.Errorf("sharedSize = %d, %v; want 100", size, err)
+	}
+}
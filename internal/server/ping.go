@@ -0,0 +1,130 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// pingTimeout bounds each reachability probe
+const pingTimeout = 3 * time.Second
+
+// PingResult is the outcome of a reachability probe
+type PingResult struct {
+	Address string `json:"address"`
+	// Status is one of: online, refused, no-response, unresolved, invalid
+	Status    string `json:"status"`
+	Reachable bool   `json:"reachable"`
+	// Protocol is the protocol that answered ("tcp" or "udp")
+	Protocol  string `json:"protocol,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NormalizeAddress validates a host[:port] address and adds the default port if missing
+func NormalizeAddress(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("address is empty")
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port given
+		host, port = address, strconv.Itoa(DefaultPort)
+	}
+	if host == "" {
+		return "", fmt.Errorf("address %q has no host", address)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return "", fmt.Errorf("address %q has an invalid port", address)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Ping checks whether a server is reachable. Hytale servers speak QUIC over UDP,
+// so a TCP connect is tried first (proxies, query ports) and a UDP probe second.
+// A UDP probe without any answer is reported as "no-response" since silence
+// doesn't prove the server is down.
+func Ping(address string) PingResult {
+	result := PingResult{Address: address}
+
+	normalized, err := NormalizeAddress(address)
+	if err != nil {
+		result.Status = "invalid"
+		result.Error = err.Error()
+		return result
+	}
+	result.Address = normalized
+
+	host, _, _ := net.SplitHostPort(normalized)
+	if net.ParseIP(host) == nil {
+		if _, err := net.LookupHost(host); err != nil {
+			result.Status = "unresolved"
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", normalized, pingTimeout)
+	if err == nil {
+		conn.Close()
+		result.Status = "online"
+		result.Reachable = true
+		result.Protocol = "tcp"
+		result.LatencyMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	latency, err := pingUDP(normalized)
+	switch {
+	case err == nil:
+		result.Status = "online"
+		result.Reachable = true
+		result.Protocol = "udp"
+		result.LatencyMs = latency.Milliseconds()
+	case isTimeout(err):
+		result.Status = "no-response"
+		result.Error = "no answer to UDP probe"
+	default:
+		result.Status = "refused"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// pingUDP sends a small datagram and waits for any answer. A closed port usually
+// yields an ICMP "port unreachable", which surfaces as a read error.
+func pingUDP(address string) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", address, pingTimeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// A QUIC long-header packet with an unsupported version makes QUIC servers
+	// answer with a version negotiation packet
+	probe := make([]byte, 1200)
+	probe[0] = 0xC0
+	copy(probe[1:5], []byte{0x0a, 0x0a, 0x0a, 0x0a})
+	probe[5] = 8
+
+	start := time.Now()
+	if _, err := conn.Write(probe); err != nil {
+		return 0, err
+	}
+	conn.SetReadDeadline(time.Now().Add(pingTimeout))
+
+	buf := make([]byte, 1500)
+	if _, err := conn.Read(buf); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// isTimeout reports whether err is a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
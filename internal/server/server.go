@@ -0,0 +1,443 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/java"
+	"HyVanila/internal/patcher"
+	"HyVanila/internal/util"
+)
+
+// DefaultPort is the default port the Hytale server listens on
+const DefaultPort = 5520
+
+// consoleHistory is the number of console lines kept per server
+const consoleHistory = 2000
+
+// stopTimeout is how long a server gets to shut down after "stop" before it is killed
+const stopTimeout = 30 * time.Second
+
+// readyMarkers are console fragments that indicate the server accepts connections
+var readyMarkers = []string{"Server started", "server started", "Booted", "Listening on"}
+
+// Options configures a server start
+type Options struct {
+	Branch   string
+	Version  int
+	JavaPath string // Custom Java path (empty for the managed JRE)
+	// MaxMemory and MinMemory are the JVM heap limits in MB (0 for JVM defaults)
+	MaxMemory int
+	MinMemory int
+	Port      int
+	// BindAddress is the address to bind to (empty for all interfaces)
+	BindAddress string
+	// AuthMode is passed to the server as --auth-mode (empty to use the server default)
+	AuthMode string
+	// ExtraArgs are appended to the server arguments
+	ExtraArgs []string
+	// Callbacks
+	OnOutput func(line ConsoleLine)
+	OnExit   func(status Status)
+}
+
+// ConsoleLine is a single line of server console output or input
+type ConsoleLine struct {
+	Branch  string `json:"branch"`
+	Version int    `json:"version"`
+	// Stream is "stdout", "stderr", "stdin" or "launcher"
+	Stream string `json:"stream"`
+	Text   string `json:"text"`
+	Time   string `json:"time"`
+}
+
+// Status describes a server's state
+type Status struct {
+	Branch    string `json:"branch"`
+	Version   int    `json:"version"`
+	Running   bool   `json:"running"`
+	Ready     bool   `json:"ready"`
+	PID       int    `json:"pid"`
+	Port      int    `json:"port"`
+	Address   string `json:"address"`
+	DataDir   string `json:"dataDir"`
+	StartedAt string `json:"startedAt"`
+	ExitCode  int    `json:"exitCode"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Server is a running (or exited) dedicated server process
+type Server struct {
+	mu      sync.Mutex
+	opts    Options
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	console []ConsoleLine
+	status  Status
+	ready   chan struct{}
+	done    chan struct{}
+}
+
+// Manager tracks the dedicated servers started by the launcher, one per instance
+type Manager struct {
+	mu      sync.Mutex
+	servers map[string]*Server
+}
+
+// NewManager creates a new server manager
+func NewManager() *Manager {
+	return &Manager{servers: make(map[string]*Server)}
+}
+
+// GetDataDir returns the data directory of an instance's server. The server runs
+// with this as its working directory, so worlds, configs and logs end up here.
+func GetDataDir(branch string, version int) string {
+	return filepath.Join(env.GetInstanceDir(branch, version), "server")
+}
+
+// key identifies the server of an instance
+func key(branch string, version int) string {
+	return fmt.Sprintf("%s-%d", branch, version)
+}
+
+// Start starts the dedicated server of an instance
+func (m *Manager) Start(opts Options) (*Server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The default has to be in place before ports are compared
+	if opts.Port <= 0 {
+		opts.Port = DefaultPort
+	}
+
+	k := key(opts.Branch, opts.Version)
+	if existing, ok := m.servers[k]; ok && existing.IsRunning() {
+		return nil, fmt.Errorf("server for %s v%d is already running", opts.Branch, opts.Version)
+	}
+	for _, s := range m.servers {
+		if s.IsRunning() && s.opts.Port == opts.Port {
+			return nil, fmt.Errorf("port %d is already used by the server for %s v%d", opts.Port, s.opts.Branch, s.opts.Version)
+		}
+	}
+
+	s, err := start(opts)
+	if err != nil {
+		return nil, err
+	}
+	m.servers[k] = s
+	return s, nil
+}
+
+// Get returns the server of an instance (nil if it was never started)
+func (m *Manager) Get(branch string, version int) *Server {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.servers[key(branch, version)]
+}
+
+// List returns the status of all known servers
+func (m *Manager) List() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := []Status{}
+	for _, s := range m.servers {
+		statuses = append(statuses, s.Status())
+	}
+	return statuses
+}
+
+// StopAll gracefully stops all running servers (used on launcher shutdown)
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	servers := make([]*Server, 0, len(m.servers))
+	for _, s := range m.servers {
+		servers = append(servers, s)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range servers {
+		if !s.IsRunning() {
+			continue
+		}
+		wg.Add(1)
+		go func(s *Server) {
+			defer wg.Done()
+			if err := s.Stop(); err != nil {
+				fmt.Printf("Warning: Failed to stop server: %v\n", err)
+			}
+		}(s)
+	}
+	wg.Wait()
+}
+
+// start launches the server process
+func start(opts Options) (*Server, error) {
+	if opts.Port <= 0 {
+		opts.Port = DefaultPort
+	}
+
+	gameDir := env.GetInstanceGameDir(opts.Branch, opts.Version)
+	locator := patcher.NewClientPatcher("")
+	jarPath := locator.FindServerPath(gameDir)
+	if jarPath == "" {
+		return nil, fmt.Errorf("server JAR not found in %s (instance %s v%d not installed?)", gameDir, opts.Branch, opts.Version)
+	}
+
+	javaPath := opts.JavaPath
+	if javaPath == "" {
+		var err error
+		javaPath, err = java.GetJavaExec()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dataDir := GetDataDir(opts.Branch, opts.Version)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create server data directory: %w", err)
+	}
+
+	args := buildArgs(opts, jarPath, gameDir)
+
+	fmt.Printf("=== START SERVER ===\n")
+	fmt.Printf("Branch: %s, Version: %d\n", opts.Branch, opts.Version)
+	fmt.Printf("JAR: %s\n", jarPath)
+	fmt.Printf("Data dir: %s\n", dataDir)
+	fmt.Printf("Command: %s %s\n", javaPath, strings.Join(args, " "))
+	fmt.Printf("====================\n")
+
+	cmd := exec.Command(javaPath, args...)
+	cmd.Dir = dataDir
+	util.HideConsoleWindow(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	s := &Server{
+		opts:  opts,
+		cmd:   cmd,
+		stdin: stdin,
+		ready: make(chan struct{}),
+		done:  make(chan struct{}),
+		status: Status{
+			Branch:    opts.Branch,
+			Version:   opts.Version,
+			Running:   true,
+			PID:       cmd.Process.Pid,
+			Port:      opts.Port,
+			Address:   fmt.Sprintf("127.0.0.1:%d", opts.Port),
+			DataDir:   dataDir,
+			StartedAt: time.Now().Format(time.RFC3339),
+		},
+	}
+
+	var pipes sync.WaitGroup
+	pipes.Add(2)
+	go s.readPipe(stdout, "stdout", &pipes)
+	go s.readPipe(stderr, "stderr", &pipes)
+
+	go func() {
+		pipes.Wait()
+		err := cmd.Wait()
+
+		s.mu.Lock()
+		s.status.Running = false
+		s.status.Ready = false
+		s.status.ExitCode = cmd.ProcessState.ExitCode()
+		if err != nil {
+			s.status.Error = err.Error()
+		}
+		status := s.status
+		s.mu.Unlock()
+
+		s.addLine("launcher", fmt.Sprintf("Server exited with code %d", status.ExitCode))
+		close(s.done)
+		if opts.OnExit != nil {
+			opts.OnExit(status)
+		}
+	}()
+
+	return s, nil
+}
+
+// buildArgs builds the JVM and server arguments
+func buildArgs(opts Options, jarPath, gameDir string) []string {
+	var args []string
+	if opts.MaxMemory > 0 {
+		args = append(args, fmt.Sprintf("-Xmx%dM", opts.MaxMemory))
+	}
+	if opts.MinMemory > 0 {
+		args = append(args, fmt.Sprintf("-Xms%dM", opts.MinMemory))
+	}
+	args = append(args, "-jar", jarPath)
+
+	assetsPath := filepath.Join(gameDir, "Assets.zip")
+	if _, err := os.Stat(assetsPath); err == nil {
+		args = append(args, "--assets", assetsPath)
+	}
+
+	bind := opts.BindAddress
+	if bind == "" {
+		bind = "0.0.0.0"
+	}
+	args = append(args, "--bind", bind+":"+strconv.Itoa(opts.Port))
+
+	if opts.AuthMode != "" {
+		args = append(args, "--auth-mode", opts.AuthMode)
+	}
+
+	return append(args, opts.ExtraArgs...)
+}
+
+// readPipe forwards a process pipe line by line to the console
+func (s *Server) readPipe(r io.Reader, stream string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		s.addLine(stream, scanner.Text())
+	}
+}
+
+// addLine records a console line, marks the server ready on a ready marker and
+// forwards the line to the output callback
+func (s *Server) addLine(stream, text string) {
+	line := ConsoleLine{
+		Branch:  s.opts.Branch,
+		Version: s.opts.Version,
+		Stream:  stream,
+		Text:    text,
+		Time:    time.Now().Format(time.RFC3339),
+	}
+
+	s.mu.Lock()
+	s.console = append(s.console, line)
+	if len(s.console) > consoleHistory {
+		s.console = s.console[len(s.console)-consoleHistory:]
+	}
+	if !s.status.Ready && stream != "stdin" && stream != "launcher" {
+		for _, marker := range readyMarkers {
+			if strings.Contains(text, marker) {
+				s.status.Ready = true
+				close(s.ready)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if s.opts.OnOutput != nil {
+		s.opts.OnOutput(line)
+	}
+}
+
+// SendCommand writes a console command to the server's stdin
+func (s *Server) SendCommand(command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+	if !s.IsRunning() {
+		return fmt.Errorf("server is not running")
+	}
+
+	s.addLine("stdin", command)
+	if _, err := io.WriteString(s.stdin, command+"\n"); err != nil {
+		return fmt.Errorf("failed to send command: %w", err)
+	}
+	return nil
+}
+
+// Stop asks the server to shut down with the "stop" command and kills it if it
+// hasn't exited within the stop timeout
+func (s *Server) Stop() error {
+	if !s.IsRunning() {
+		return nil
+	}
+
+	fmt.Printf("Stopping server for %s v%d...\n", s.opts.Branch, s.opts.Version)
+	if err := s.SendCommand("stop"); err != nil {
+		fmt.Printf("Warning: %v, killing server\n", err)
+		return s.Kill()
+	}
+
+	select {
+	case <-s.done:
+		fmt.Println("Server stopped")
+		return nil
+	case <-time.After(stopTimeout):
+		fmt.Println("Server did not stop in time, killing it")
+		return s.Kill()
+	}
+}
+
+// Kill terminates the server process immediately
+func (s *Server) Kill() error {
+	if !s.IsRunning() {
+		return nil
+	}
+	if err := s.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill server: %w", err)
+	}
+	<-s.done
+	return nil
+}
+
+// WaitReady waits until the server reports it is ready. It returns false if the
+// server exited or the timeout elapsed first.
+func (s *Server) WaitReady(timeout time.Duration) bool {
+	select {
+	case <-s.ready:
+		return true
+	case <-s.done:
+		return false
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// IsRunning reports whether the server process is still running
+func (s *Server) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status.Running
+}
+
+// Status returns the server's current status
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Console returns the recent console history
+func (s *Server) Console() []ConsoleLine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ConsoleLine{}, s.console...)
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStartRejectsDefaultPortInUse(t *testing.T) {
+	m := NewManager()
+	// A server started without a port listens on the default one
+	m.servers[key("release", 1)] = &Server{
+		opts:   Options{Branch: "release", Version: 1, Port: DefaultPort},
+		status: Status{Running: true},
+	}
+
+	_, err := m.Start(Options{Branch: "release", Version: 2})
+	if err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("Start = %v, want a port conflict", err)
+	}
+}
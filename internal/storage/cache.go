@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+	"HyVanila/internal/util"
+)
+
+// CachePolicy controls what the cache directory keeps
+type CachePolicy struct {
+	// MaxSizeMB caps the cache size; oldest files are removed first (0 = no cap)
+	MaxSizeMB int `json:"maxSizeMB"`
+	// KeepPatches keeps the N most recently downloaded game patches so an
+	// instance can be reinstalled without downloading again
+	KeepPatches int `json:"keepPatches"`
+}
+
+var (
+	policyMu sync.RWMutex
+	policy   = CachePolicy{MaxSizeMB: 4096, KeepPatches: 1}
+)
+
+// SetCachePolicy replaces the active cache policy
+func SetCachePolicy(p CachePolicy) {
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	policy = p
+}
+
+// GetCachePolicy returns the active cache policy
+func GetCachePolicy() CachePolicy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return policy
+}
+
+// Kinds of cache files
+const (
+	kindPatch   = "patch"
+	kindArchive = "archive"
+	kindTemp    = "temp"
+	kindOther   = "other"
+)
+
+// tempSuffixes mark files left behind by interrupted downloads and writes
+var tempSuffixes = []string{".tmp", ".partial", ".segments", ".downloading", ".relocating", ".linking", ".detaching", ".patching"}
+
+type cacheFile struct {
+	path    string
+	kind    string
+	size    int64
+	modTime time.Time
+}
+
+// listCache returns the files in the cache directory, newest first
+func listCache() []cacheFile {
+	entries, err := os.ReadDir(env.GetCacheDir())
+	if err != nil {
+		return nil
+	}
+	var files []cacheFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{
+			path:    filepath.Join(env.GetCacheDir(), entry.Name()),
+			kind:    classify(entry.Name()),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	return files
+}
+
+func classify(name string) string {
+	for _, suffix := range tempSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return kindTemp
+		}
+	}
+	switch {
+	case strings.HasSuffix(name, ".pwr"):
+		return kindPatch
+	case strings.HasSuffix(name, ".zip"), strings.HasSuffix(name, ".tar.gz"):
+		return kindArchive
+	}
+	return kindOther
+}
+
+// CleanupOptions selects what Cleanup removes
+type CleanupOptions struct {
+	// Cache empties the cache, except patches kept by the cache policy
+	Cache bool `json:"cache"`
+	// AllPatches also removes the patches the cache policy would keep
+	AllPatches bool `json:"allPatches"`
+	// TempFiles removes leftovers of interrupted downloads and installs
+	TempFiles bool `json:"tempFiles"`
+	// LogsOlderThanDays removes launcher logs and crash reports older than
+	// this many days (0 = keep logs)
+	LogsOlderThanDays int `json:"logsOlderThanDays"`
+}
+
+// CleanupResult summarizes a Cleanup run
+type CleanupResult struct {
+	FreedBytes   int64 `json:"freedBytes"`
+	RemovedFiles int   `json:"removedFiles"`
+	// Skipped lists paths left alone because an install or download is using them
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+func (r *CleanupResult) remove(path string, size int64) {
+	if IsInUse(path) {
+		r.Skipped = append(r.Skipped, path)
+		return
+	}
+	if err := os.Remove(path); err == nil {
+		r.FreedBytes += size
+		r.RemovedFiles++
+	}
+}
+
+// Cleanup frees disk space. Files that a running install or download marked
+// in use are skipped.
+func Cleanup(opts CleanupOptions) *CleanupResult {
+	result := &CleanupResult{}
+	keepPatches := GetCachePolicy().KeepPatches
+	if opts.AllPatches {
+		keepPatches = 0
+	}
+
+	patches := 0
+	for _, file := range listCache() {
+		switch {
+		case file.kind == kindTemp && (opts.TempFiles || opts.Cache):
+			result.remove(file.path, file.size)
+		case file.kind == kindPatch && opts.Cache:
+			// Newest first, so the first ones are the ones to keep
+			if patches++; patches > keepPatches {
+				result.remove(file.path, file.size)
+			}
+		case file.kind != kindTemp && file.kind != kindPatch && opts.Cache:
+			result.remove(file.path, file.size)
+		}
+	}
+
+	if opts.TempFiles {
+		cleanInstanceLeftovers(result)
+	}
+
+	if opts.LogsOlderThanDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -opts.LogsOlderThanDays)
+		appDir := env.GetDefaultAppDir()
+		for _, dir := range []string{filepath.Join(appDir, "logs"), filepath.Join(appDir, "crashes")} {
+			entries, _ := os.ReadDir(dir)
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+					continue
+				}
+				result.remove(filepath.Join(dir, entry.Name()), info.Size())
+			}
+		}
+	}
+
+	fmt.Printf("Storage cleanup: removed %d files, freed %s, skipped %d in use\n",
+		result.RemovedFiles, util.FormatBytes(result.FreedBytes), len(result.Skipped))
+	return result
+}
+
+// cleanInstanceLeftovers removes butler staging folders and unfinished
+// import/copy/repair folders from instances that aren't being installed
+func cleanInstanceLeftovers(result *CleanupResult) {
+	names, err := env.ListInstances()
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		instanceDir := filepath.Join(env.GetInstancesDir(), name)
+		leftovers := []string{filepath.Join(instanceDir, "game", "staging-temp")}
+		entries, _ := os.ReadDir(instanceDir)
+		for _, entry := range entries {
+			if entry.IsDir() && (strings.HasPrefix(entry.Name(), ".importing-") || strings.HasPrefix(entry.Name(), ".copying-") || strings.HasPrefix(entry.Name(), ".repair-")) {
+				leftovers = append(leftovers, filepath.Join(instanceDir, entry.Name()))
+			}
+		}
+
+		for _, path := range leftovers {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if IsInUse(path) {
+				result.Skipped = append(result.Skipped, path)
+				continue
+			}
+			size, files, err := removeTree(path)
+			if err == nil {
+				result.FreedBytes += size
+				result.RemovedFiles += files
+			}
+		}
+	}
+}
+
+// ApplyCachePolicy trims the cache to the active policy: patches beyond the
+// kept ones are removed, then the oldest files until the size cap is met.
+// Files in use are never removed.
+func ApplyCachePolicy() {
+	p := GetCachePolicy()
+	result := &CleanupResult{}
+
+	var kept []cacheFile
+	patches := 0
+	for _, file := range listCache() {
+		if file.kind == kindPatch {
+			if patches++; patches > p.KeepPatches {
+				result.remove(file.path, file.size)
+				continue
+			}
+		}
+		kept = append(kept, file)
+	}
+
+	if p.MaxSizeMB > 0 {
+		limit := int64(p.MaxSizeMB) * 1024 * 1024
+		var total int64
+		for _, file := range kept {
+			total += file.size
+		}
+		// kept is newest first, so trim from the end
+		for i := len(kept) - 1; i >= 0 && total > limit; i-- {
+			if IsInUse(kept[i].path) {
+				continue
+			}
+			result.remove(kept[i].path, kept[i].size)
+			total -= kept[i].size
+		}
+	}
+
+	if result.RemovedFiles > 0 {
+		fmt.Printf("Cache policy: removed %d files, freed %s\n", result.RemovedFiles, util.FormatBytes(result.FreedBytes))
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	inUseMu sync.Mutex
+	inUse   = make(map[string]int)
+)
+
+// MarkInUse protects a file or directory tree from cleanup until the returned
+// release function is called. Marks are counted, so nested users are fine.
+func MarkInUse(path string) func() {
+	path = filepath.Clean(path)
+	inUseMu.Lock()
+	inUse[path]++
+	inUseMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			inUseMu.Lock()
+			defer inUseMu.Unlock()
+			if inUse[path]--; inUse[path] <= 0 {
+				delete(inUse, path)
+			}
+		})
+	}
+}
+
+// IsInUse reports whether path, a directory containing it or a path below it is marked in use
+func IsInUse(path string) bool {
+	path = filepath.Clean(path)
+	inUseMu.Lock()
+	defer inUseMu.Unlock()
+	for marked := range inUse {
+		if marked == path || isBelow(path, marked) || isBelow(marked, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBelow reports whether path is inside dir
+func isBelow(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
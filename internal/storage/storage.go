@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"HyVanila/internal/backup"
+	"HyVanila/internal/env"
+	"HyVanila/internal/gamestore"
+)
+
+// Category is the disk usage of one kind of launcher data
+type Category struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Files int    `json:"files"`
+}
+
+// Report is the disk usage of the launcher by category and by instance
+type Report struct {
+	Categories []Category `json:"categories"`
+	// Instances have apparent and exclusive sizes; shared game files are
+	// counted once in the "gameStore" category
+	Instances []gamestore.InstanceUsage `json:"instances"`
+	Total     int64                     `json:"total"`
+	// Cache breaks the cache category down further
+	Cache CacheUsage `json:"cache"`
+}
+
+// CacheUsage breaks down the cache directory
+type CacheUsage struct {
+	PatchFiles int   `json:"patchFiles"`
+	PatchSize  int64 `json:"patchSize"`
+	Archives   int64 `json:"archives"` // JRE and butler downloads
+	TempFiles  int64 `json:"tempFiles"`
+	Other      int64 `json:"other"`
+}
+
+// GetReport measures how much disk space instances, backups, caches and logs use
+func GetReport() (*Report, error) {
+	instances, err := gamestore.GetStorageReport()
+	if err != nil {
+		return nil, err
+	}
+
+	appDir := env.GetDefaultAppDir()
+	var instancesSize int64
+	instanceFiles := 0
+	for _, usage := range instances.Instances {
+		instancesSize += usage.ExclusiveSize
+		instanceFiles += usage.Files - usage.SharedFiles
+	}
+
+	report := &Report{
+		Categories: []Category{
+			{Name: "instances", Path: env.GetInstancesDir(), Size: instancesSize, Files: instanceFiles},
+			{Name: "gameStore", Path: gamestore.GetStoreDir(), Size: instances.StoreSize, Files: instances.StoreObjects},
+			{Name: "backups", Path: env.GetBackupsDir(), Size: backup.GetStoreSize()},
+			measure("cache", env.GetCacheDir()),
+			measure("jre", env.GetJREDir()),
+			measure("butler", env.GetButlerDir()),
+			measure("logs", filepath.Join(appDir, "logs")),
+			measure("crashes", filepath.Join(appDir, "crashes")),
+		},
+		Instances: instances.Instances,
+	}
+	_, backupFiles := dirUsage(env.GetBackupsDir())
+	report.Categories[2].Files = backupFiles
+
+	for _, category := range report.Categories {
+		report.Total += category.Size
+	}
+
+	for _, file := range listCache() {
+		switch file.kind {
+		case kindPatch:
+			report.Cache.PatchFiles++
+			report.Cache.PatchSize += file.size
+		case kindArchive:
+			report.Cache.Archives += file.size
+		case kindTemp:
+			report.Cache.TempFiles += file.size
+		default:
+			report.Cache.Other += file.size
+		}
+	}
+	return report, nil
+}
+
+func measure(name, path string) Category {
+	size, files := dirUsage(path)
+	return Category{Name: name, Path: path, Size: size, Files: files}
+}
+
+// dirUsage returns the total size and number of regular files in a directory tree
+func dirUsage(dir string) (int64, int) {
+	var size int64
+	files := 0
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	return size, files
+}
+
+// removeTree deletes a directory tree and returns the bytes and files it held
+func removeTree(path string) (int64, int, error) {
+	size, files := dirUsage(path)
+	if err := os.RemoveAll(path); err != nil {
+		return 0, 0, err
+	}
+	return size, files, nil
+}
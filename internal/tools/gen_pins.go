@@ -0,0 +1,95 @@
+//go:build ignore
+
+// gen_pins downloads every archive listed in tools.json and fills in its
+// SHA-256. Run it with "go generate ./internal/tools" after changing a pinned
+// version or URL.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+)
+
+type pinPlatform struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+type pinsJSON map[string]struct {
+	Version     string                            `json:"version"`
+	DownloadURL map[string]map[string]pinPlatform `json:"download_url"`
+}
+
+func main() {
+	data, err := os.ReadFile("tools.json")
+	if err != nil {
+		fail(err)
+	}
+	var pins pinsJSON
+	if err := json.Unmarshal(data, &pins); err != nil {
+		fail(fmt.Errorf("failed to parse tools.json: %w", err))
+	}
+
+	for _, name := range sortedKeys(pins) {
+		tool := pins[name]
+		for _, osName := range sortedKeys(tool.DownloadURL) {
+			for _, arch := range sortedKeys(tool.DownloadURL[osName]) {
+				platform := tool.DownloadURL[osName][arch]
+				sum, err := archiveSHA256(platform.URL)
+				if err != nil {
+					fail(fmt.Errorf("%s %s/%s: %w", name, osName, arch, err))
+				}
+				if platform.SHA256 != "" && platform.SHA256 != sum {
+					fmt.Printf("%s %s/%s: checksum changed from %s\n", name, osName, arch, platform.SHA256)
+				}
+				platform.SHA256 = sum
+				tool.DownloadURL[osName][arch] = platform
+				fmt.Printf("%s %s/%s: %s\n", name, osName, arch, sum)
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile("tools.json", append(out, '\n'), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func archiveSHA256(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "gen_pins: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"HyVanila/internal/env"
+)
+
+// CheckInterval is how often an installed tool is compared against its pin
+const CheckInterval = 24 * time.Hour
+
+// pinsData lists the tool versions this launcher runs, with their checksums. It is
+// built in so the checksums don't come from the same place as the downloads.
+// gen_pins.go fills in the checksums.
+//
+//go:generate go run gen_pins.go
+//go:embed tools.json
+var pinsData []byte
+
+// Tool is the install record of a helper tool, such as butler or a Java runtime
+type Tool struct {
+	Version     string `json:"version"`
+	Path        string `json:"path"`        // Executable or folder of this version
+	SHA256      string `json:"sha256"`      // Checksum of the downloaded archive (empty if verified otherwise)
+	Source      string `json:"source"`      // Download URL
+	InstalledAt string `json:"installedAt"` // ISO 8601 format
+	CheckedAt   string `json:"checkedAt"`   // Last comparison against the pin
+}
+
+// Pin is the version of a tool to install on one platform
+type Pin struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+}
+
+// pinPlatform is a tool download for one platform in tools.json
+type pinPlatform struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// pinsJSON mirrors tools.json: per tool, a version and its downloads per OS and arch
+type pinsJSON map[string]struct {
+	Version     string                            `json:"version"`
+	DownloadURL map[string]map[string]pinPlatform `json:"download_url"`
+}
+
+var manifestMu sync.Mutex
+
+func getManifestPath() string {
+	return filepath.Join(env.GetDefaultAppDir(), "installed-tools.json")
+}
+
+func load() map[string]Tool {
+	manifest := make(map[string]Tool)
+	data, err := os.ReadFile(getManifestPath())
+	if err != nil {
+		return manifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		fmt.Printf("Warning: Ignoring unreadable tools manifest: %v\n", err)
+		return make(map[string]Tool)
+	}
+	return manifest
+}
+
+func save(manifest map[string]Tool) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := getManifestPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, getManifestPath())
+}
+
+// Get returns the install record of a tool
+func Get(name string) (Tool, bool) {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	tool, ok := load()[name]
+	return tool, ok
+}
+
+// List returns the install records of all tools
+func List() map[string]Tool {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	return load()
+}
+
+// Record stores the install record of a tool and marks it as just checked
+func Record(name string, tool Tool) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	now := time.Now().Format(time.RFC3339)
+	if tool.InstalledAt == "" {
+		tool.InstalledAt = now
+	}
+	tool.CheckedAt = now
+	manifest := load()
+	manifest[name] = tool
+	return save(manifest)
+}
+
+// Remove forgets a tool
+func Remove(name string) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	manifest := load()
+	if _, ok := manifest[name]; !ok {
+		return nil
+	}
+	delete(manifest, name)
+	return save(manifest)
+}
+
+// CheckDue reports whether a tool is unknown or was last compared against its
+// pin more than CheckInterval ago
+func CheckDue(name string) bool {
+	tool, ok := Get(name)
+	if !ok {
+		return true
+	}
+	checked, err := time.Parse(time.RFC3339, tool.CheckedAt)
+	return err != nil || time.Since(checked) > CheckInterval
+}
+
+// PinFor returns the pinned download of a tool for an OS and arch, as named in
+// tools.json. A pin without a valid checksum is an error: nothing is installed
+// unverified.
+func PinFor(name, osName, arch string) (*Pin, error) {
+	var pins pinsJSON
+	if err := json.Unmarshal(pinsData, &pins); err != nil {
+		return nil, fmt.Errorf("failed to parse tool pins: %w", err)
+	}
+	tool, ok := pins[name]
+	if !ok || tool.Version == "" {
+		return nil, fmt.Errorf("tools.json has no pin for %s", name)
+	}
+	platform, ok := tool.DownloadURL[osName][arch]
+	if !ok || platform.URL == "" {
+		return nil, fmt.Errorf("tools.json has no %s build for %s/%s", name, osName, arch)
+	}
+	if sum, err := hex.DecodeString(platform.SHA256); err != nil || len(sum) != 32 {
+		return nil, fmt.Errorf("tools.json has no valid checksum for %s %s on %s/%s", name, tool.Version, osName, arch)
+	}
+	return &Pin{Version: tool.Version, URL: platform.URL, SHA256: platform.SHA256}, nil
+}
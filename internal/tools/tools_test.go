@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPinFor(t *testing.T) {
+	defer func(data []byte) { pinsData = data }(pinsData)
+	pinsData = []byte(`{
+  "butler": {
+    "version": "15.21.0",
+    "download_url": {
+      "linux": {
+        "amd64": {"url": "https://example.com/linux", "sha256": "` + strings.Repeat("ab", 32) + `"},
+        "arm64": {"url": "https://example.com/linux-arm", "sha256": ""}
+      },
+      "windows": {
+        "amd64": {"url": "https://example.com/windows", "sha256": "not-a-checksum"}
+      }
+    }
+  }
+}`)
+
+	tests := []struct {
+		name, tool, os, arch string
+		wantErr              bool
+	}{
+		{"pinned", "butler", "linux", "amd64", false},
+		{"empty checksum", "butler", "linux", "arm64", true},
+		{"malformed checksum", "butler", "windows", "amd64", true},
+		{"no build for platform", "butler", "darwin", "amd64", true},
+		{"unknown tool", "temurin-25", "linux", "amd64", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pin, err := PinFor(tt.tool, tt.os, tt.arch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PinFor = %+v, %v; want error %v", pin, err, tt.wantErr)
+			}
+			if err == nil && (pin.Version != "15.21.0" || pin.URL != "https://example.com/linux") {
+				t.Errorf("PinFor = %+v", pin)
+			}
+		})
+	}
+}
+
+func TestEmbeddedPins(t *testing.T) {
+	var pins pinsJSON
+	if err := json.Unmarshal(pinsData, &pins); err != nil {
+		t.Fatalf("tools.json: %v", err)
+	}
+
+	// Butler has no arm64 builds: macOS runs the amd64 one through Rosetta 2
+	// and linux/arm64 is unsupported
+	required := map[string][]string{
+		"butler":     {"windows/amd64", "linux/amd64", "darwin/amd64"},
+		"temurin-25": {"windows/amd64", "linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64"},
+	}
+	for name, platforms := range required {
+		tool, ok := pins[name]
+		if !ok {
+			t.Errorf("tools.json has no pin for %s", name)
+			continue
+		}
+		for _, platform := range platforms {
+			osName, arch, _ := strings.Cut(platform, "/")
+			if _, ok := tool.DownloadURL[osName][arch]; !ok {
+				t.Errorf("tools.json has no %s build for %s", name, platform)
+			}
+		}
+	}
+
+	// Every listed download must be installable; run "go generate" to fill in checksums
+	for name, tool := range pins {
+		for osName, archs := range tool.DownloadURL {
+			for arch := range archs {
+				pin, err := PinFor(name, osName, arch)
+				if err != nil {
+					t.Errorf("PinFor(%s, %s, %s): %v", name, osName, arch, err)
+					continue
+				}
+				if !strings.HasPrefix(pin.URL, "https://") {
+					t.Errorf("%s %s/%s: URL %q is not https", name, osName, arch, pin.URL)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package download
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Proxy modes
+const (
+	ProxySystem = "system" // HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment
+	ProxyNone   = "none"
+	ProxyManual = "manual"
+)
+
+// NetworkSettings configure how the launcher reaches the internet
+type NetworkSettings struct {
+	ProxyMode string `json:"proxyMode"`
+	// ProxyURL is the manual proxy, e.g. "http://proxy:3128" or "socks5://proxy:1080"
+	ProxyURL      string `json:"proxyURL"`
+	ProxyUsername string `json:"proxyUsername"`
+	ProxyPassword string `json:"proxyPassword"`
+	// NoProxy lists hosts reached directly: "example.com" (and its subdomains),
+	// ".example.com", IP addresses and CIDR ranges like "10.0.0.0/8"
+	NoProxy []string `json:"noProxy"`
+	// CABundles are PEM files with certificates trusted in addition to the
+	// system ones, e.g. the CA of a TLS-inspecting corporate proxy
+	CABundles []string `json:"caBundles"`
+}
+
+var (
+	transportMu sync.RWMutex
+	transport   = newTransport(http.ProxyFromEnvironment, nil)
+	settings    = NetworkSettings{ProxyMode: ProxySystem}
+)
+
+func newTransport(proxy func(*http.Request) (*url.URL, error), roots *x509.CertPool) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    roots,
+		},
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		DisableCompression:    true,
+	}
+}
+
+// Configure applies network settings to every client made by NewClient,
+// including ones created before the call
+func Configure(s NetworkSettings) error {
+	proxy, err := proxyFunc(s)
+	if err != nil {
+		return err
+	}
+	roots, err := loadCABundles(s.CABundles)
+	if err != nil {
+		return err
+	}
+
+	transportMu.Lock()
+	old := transport
+	transport = newTransport(proxy, roots)
+	settings = s
+	transportMu.Unlock()
+
+	old.CloseIdleConnections()
+	return nil
+}
+
+// GetNetworkSettings returns the active network settings
+func GetNetworkSettings() NetworkSettings {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return settings
+}
+
+// NewClient returns an HTTP client using the configured proxy and CA bundles.
+// A timeout of 0 means none.
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: sharedTransport{},
+		Timeout:   timeout,
+	}
+}
+
+// sharedTransport sends requests through the currently configured transport,
+// so connections are reused and settings changes reach existing clients
+type sharedTransport struct{}
+
+func (sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transportMu.RLock()
+	t := transport
+	transportMu.RUnlock()
+	resp, err := t.RoundTrip(req)
+	if err != nil && isCertError(err) {
+		return nil, fmt.Errorf("%w (if a proxy or firewall inspects TLS traffic, add its CA certificate to the trusted CA bundles in network settings)", err)
+	}
+	return resp, err
+}
+
+// isCertError checks if an error is a TLS certificate verification failure
+func isCertError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var verification *tls.CertificateVerificationError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) || errors.As(err, &verification)
+}
+
+func proxyFunc(s NetworkSettings) (func(*http.Request) (*url.URL, error), error) {
+	switch s.ProxyMode {
+	case "", ProxySystem:
+		return http.ProxyFromEnvironment, nil
+	case ProxyNone:
+		return nil, nil
+	case ProxyManual:
+	default:
+		return nil, fmt.Errorf("unknown proxy mode %q", s.ProxyMode)
+	}
+
+	proxyURL, err := url.Parse(strings.TrimSpace(s.ProxyURL))
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q (expected e.g. http://proxy:3128)", s.ProxyURL)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+	}
+	if s.ProxyUsername != "" {
+		proxyURL.User = url.UserPassword(s.ProxyUsername, s.ProxyPassword)
+	}
+
+	noProxy := make([]string, 0, len(s.NoProxy))
+	for _, entry := range s.NoProxy {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" {
+			noProxy = append(noProxy, entry)
+		}
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// bypassProxy reports whether host matches the no-proxy list
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+	for _, entry := range noProxy {
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadCABundles returns the system roots plus the certificates in the given
+// PEM files, or nil (system roots only) without bundles
+func loadCABundles(paths []string) (*x509.CertPool, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", path)
+		}
+	}
+	return pool, nil
+}
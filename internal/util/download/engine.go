@@ -0,0 +1,413 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"HyVanila/internal/util"
+)
+
+const (
+	defaultAttempts = 5
+	maxBackoff      = 30 * time.Second
+	// stallTimeout aborts an attempt that received no data for this long
+	stallTimeout = 60 * time.Second
+	// progressInterval throttles progress callbacks
+	progressInterval = 200 * time.Millisecond
+	// maxPerHost limits concurrent downloads from one host
+	maxPerHost = 4
+	// partialSuffix marks a download in progress; it is resumed on the next attempt
+	partialSuffix = ".partial"
+	// bufferSize is the read buffer of each connection
+	bufferSize = 256 * 1024
+)
+
+// ProgressFunc reports download progress, matching the launcher's progress callbacks
+type ProgressFunc func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)
+
+// Request describes a single file download
+type Request struct {
+	URL  string
+	Dest string
+	// ExpectedSize rejects a download of any other size (0 = not checked)
+	ExpectedSize int64
+	// SHA256 is the expected hex checksum of the file (empty = not checked)
+	SHA256  string
+	Headers map[string]string
+
+	// Stage and Message label progress updates; Weight scales the reported
+	// percentage so a download can be one part of a larger step (0 = 1.0)
+	Stage    string
+	Message  string
+	Weight   float64
+	Progress ProgressFunc
+
+	// BytesPerSec caps the transfer rate of this download (0 = unlimited), on
+	// top of the global limits set with SetLimits
+	BytesPerSec int64
+	// Segments is the number of parallel connections for large files when the
+	// server supports byte ranges (0 = 4, 1 = always a single stream)
+	Segments int
+	// Attempts is the number of tries before giving up (0 = 5)
+	Attempts int
+}
+
+// permanentError is a failure that retrying won't fix, like a 404
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+var (
+	hostMu    sync.Mutex
+	hostSlots = make(map[string]chan struct{})
+)
+
+// acquireHost waits for a free download slot for the URL's host
+func acquireHost(ctx context.Context, rawURL string) (func(), error) {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+	hostMu.Lock()
+	slots, ok := hostSlots[host]
+	if !ok {
+		slots = make(chan struct{}, maxPerHost)
+		hostSlots[host] = slots
+	}
+	hostMu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Fetch downloads req.URL to req.Dest. The data is written to a ".partial" file
+// next to the destination, which later attempts (and later calls) resume from,
+// and is only moved into place once the size and checksum check out. Large
+// files are fetched in parallel byte ranges when the server supports them. Failed
+// attempts are retried with exponential backoff; cancelling ctx stops at once
+// and keeps the partial file.
+func Fetch(ctx context.Context, req Request) error {
+	if err := os.MkdirAll(filepath.Dir(req.Dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	release, err := acquireHost(ctx, req.URL)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attempts := req.Attempts
+	if attempts <= 0 {
+		attempts = defaultAttempts
+	}
+
+	client := sharedStreamClient
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := time.Second << (attempt - 2)
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+			fmt.Printf("Retrying download of %s in %s (attempt %d/%d)\n", filepath.Base(req.Dest), delay, attempt, attempts)
+			req.report(0, fmt.Sprintf("Retrying download (attempt %d/%d)...", attempt, attempts), "", 0, 0)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := fetchAttempt(ctx, client, req)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lastErr = err
+		fmt.Printf("Download attempt %d failed: %v\n", attempt, err)
+
+		// An untrusted certificate won't become trusted by retrying
+		var permanent *permanentError
+		if errors.As(err, &permanent) || isCertError(err) {
+			return fmt.Errorf("download failed: %w", err)
+		}
+
+		// Ranges don't work for this file after all. The segmented partial file
+		// has gaps, so the single stream starts over.
+		if errors.Is(err, errNoRanges) {
+			req.Segments = 1
+			os.Remove(req.Dest + segmentsSuffix)
+			os.Remove(req.Dest + partialSuffix)
+		}
+	}
+
+	return fmt.Errorf("download failed after %d attempts: %w", attempts, lastErr)
+}
+
+// fetchAttempt makes one attempt, in parallel segments when the file is large and
+// the server supports byte ranges, otherwise as a single stream
+func fetchAttempt(ctx context.Context, client *http.Client, req Request) error {
+	if req.Segments == 1 {
+		return fetchOnce(ctx, client, req)
+	}
+	// A partial file without segment state came from a single stream
+	_, statErr := os.Stat(req.Dest + segmentsSuffix)
+	if _, err := os.Stat(req.Dest + partialSuffix); err == nil && statErr != nil {
+		return fetchOnce(ctx, client, req)
+	}
+
+	probe, err := probeRanges(ctx, client, req)
+	if err != nil || !probe.acceptRanges || probe.size < segmentThreshold {
+		os.Remove(req.Dest + segmentsSuffix)
+		return fetchOnce(ctx, client, req)
+	}
+	return fetchSegmented(ctx, client, req, probe)
+}
+
+// fetchOnce makes one single-stream attempt, resuming the partial file if there is one
+func fetchOnce(ctx context.Context, client *http.Client, req Request) error {
+	partial := req.Dest + partialSuffix
+
+	var resumeFrom int64
+	if info, err := os.Stat(partial); err == nil {
+		resumeFrom = info.Size()
+		if req.ExpectedSize > 0 && resumeFrom > req.ExpectedSize {
+			os.Remove(partial)
+			resumeFrom = 0
+		}
+	}
+
+	// Abort the attempt if the connection stalls, without a cap on total time
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(stallTimeout, cancel)
+	defer stall.Stop()
+
+	httpReq, err := http.NewRequestWithContext(attemptCtx, "GET", req.URL, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	httpReq.Header.Set("Accept", "*/*")
+	httpReq.Header.Set("Accept-Encoding", "identity")
+	httpReq.Header.Set("User-Agent", UserAgent)
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	if resumeFrom > 0 {
+		httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		if attemptCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("connection stalled")
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file doesn't match what the server has; start over
+		os.Remove(partial)
+		return fmt.Errorf("server rejected resume from %d bytes", resumeFrom)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout:
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &permanentError{fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL)}
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent:
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL)
+	}
+
+	var file *os.File
+	if resp.StatusCode == http.StatusPartialContent && resumeFrom > 0 {
+		fmt.Printf("Resuming %s from %d bytes\n", filepath.Base(req.Dest), resumeFrom)
+		file, err = os.OpenFile(partial, os.O_APPEND|os.O_WRONLY, 0644)
+	} else {
+		// The server ignored the range request, so the body is the whole file
+		file, err = os.Create(partial)
+		resumeFrom = 0
+	}
+	if err != nil {
+		return &permanentError{err}
+	}
+	defer file.Close()
+
+	total := req.ExpectedSize
+	if total == 0 && resp.ContentLength > 0 {
+		total = resp.ContentLength + resumeFrom
+	}
+
+	downloaded := resumeFrom
+	meter := newMeter(downloaded)
+	started := time.Now()
+	buf := make([]byte, bufferSize)
+
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			stall.Reset(stallTimeout)
+			if _, err := file.Write(buf[:n]); err != nil {
+				return &permanentError{fmt.Errorf("failed to write %s: %w", partial, err)}
+			}
+			downloaded += int64(n)
+
+			// The global limit is shared with every other download of this class
+			stall.Stop()
+			if err := throttle(ctx, n); err != nil {
+				return err
+			}
+			stall.Reset(stallTimeout)
+
+			if req.BytesPerSec > 0 {
+				// Sleep until the average rate of this attempt is back under the cap
+				ahead := time.Duration(float64(downloaded-resumeFrom)/float64(req.BytesPerSec)*float64(time.Second)) - time.Since(started)
+				if ahead > 0 {
+					stall.Reset(stallTimeout + ahead)
+					select {
+					case <-time.After(ahead):
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+
+			if speed, eta, ok := meter.update(downloaded, total); ok {
+				req.report(percent(downloaded, total), req.message(eta), speed, downloaded, total)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if attemptCtx.Err() != nil && ctx.Err() == nil {
+				return fmt.Errorf("connection stalled after %d bytes", downloaded)
+			}
+			return fmt.Errorf("read error: %w", readErr)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if total > 0 && downloaded < total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", downloaded, total)
+	}
+	return finish(req, partial, downloaded)
+}
+
+// finish checks the size and checksum of a completed partial file and moves it
+// into place
+func finish(req Request, partial string, downloaded int64) error {
+	if req.ExpectedSize > 0 && downloaded != req.ExpectedSize {
+		os.Remove(partial)
+		return fmt.Errorf("size mismatch: expected %d bytes, got %d", req.ExpectedSize, downloaded)
+	}
+	if req.SHA256 != "" {
+		req.report(100, "Verifying checksum...", "", downloaded, downloaded)
+		if err := util.VerifySHA256(partial, req.SHA256); err != nil {
+			// Corrupt data can't be resumed; the next attempt starts over
+			os.Remove(partial)
+			return err
+		}
+	}
+
+	if err := os.Rename(partial, req.Dest); err != nil {
+		return &permanentError{err}
+	}
+	req.report(100, "Download complete", "", downloaded, downloaded)
+	return nil
+}
+
+func (req *Request) weight() float64 {
+	if req.Weight <= 0 {
+		return 1
+	}
+	return req.Weight
+}
+
+func (req *Request) message(eta time.Duration) string {
+	message := req.Message
+	if message == "" {
+		message = "Downloading..."
+	}
+	if eta > 0 {
+		message = fmt.Sprintf("%s (%s left)", message, formatETA(eta))
+	}
+	return message
+}
+
+func (req *Request) report(progress float64, message, speed string, downloaded, total int64) {
+	if req.Progress == nil {
+		return
+	}
+	req.Progress(req.Stage, progress*req.weight(), message, filepath.Base(req.Dest), speed, downloaded, total)
+}
+
+func percent(downloaded, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(downloaded) / float64(total) * 100
+}
+
+// meter throttles progress updates and tracks a smoothed speed for the ETA
+type meter struct {
+	last      time.Time
+	lastBytes int64
+	rate      float64 // Bytes per second, exponentially smoothed
+}
+
+func newMeter(start int64) *meter {
+	return &meter{last: time.Now(), lastBytes: start}
+}
+
+// update returns the speed and remaining time once per progress interval
+func (m *meter) update(downloaded, total int64) (string, time.Duration, bool) {
+	elapsed := time.Since(m.last)
+	if elapsed < progressInterval {
+		return "", 0, false
+	}
+	current := float64(downloaded-m.lastBytes) / elapsed.Seconds()
+	if m.rate == 0 {
+		m.rate = current
+	} else {
+		m.rate = 0.7*m.rate + 0.3*current
+	}
+	m.last = time.Now()
+	m.lastBytes = downloaded
+
+	var eta time.Duration
+	if total > downloaded && m.rate > 0 {
+		eta = time.Duration(float64(total-downloaded) / m.rate * float64(time.Second))
+	}
+	return formatSpeed(m.rate), eta, true
+}
+
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
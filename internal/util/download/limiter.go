@@ -0,0 +1,150 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"HyVanila/internal/util"
+)
+
+// Limits cap the bandwidth of all launcher downloads. Foreground downloads are
+// the ones the user is waiting for; background ones (like update pre-downloads)
+// run on their own, lower limit. 0 means unlimited.
+type Limits struct {
+	ForegroundKBps int `json:"foregroundKBps"`
+	BackgroundKBps int `json:"backgroundKBps"`
+	// Rules replace the limits above during their time-of-day window; the first
+	// matching rule wins
+	Rules []LimitRule `json:"rules"`
+}
+
+// LimitRule sets different limits during a "HH:MM-HH:MM" window
+type LimitRule struct {
+	Window         string `json:"window"`
+	ForegroundKBps int    `json:"foregroundKBps"`
+	BackgroundKBps int    `json:"backgroundKBps"`
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   Limits
+)
+
+// SetLimits replaces the bandwidth limits. Running downloads pick up the new
+// limits right away.
+func SetLimits(l Limits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	limits = l
+}
+
+// GetLimits returns the active bandwidth limits
+func GetLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+// currentRate returns the limit in bytes per second for a traffic class at t
+func currentRate(background bool, t time.Time) float64 {
+	l := GetLimits()
+	fg, bg := l.ForegroundKBps, l.BackgroundKBps
+	for _, rule := range l.Rules {
+		if util.InTimeWindow(rule.Window, t) {
+			fg, bg = rule.ForegroundKBps, rule.BackgroundKBps
+			break
+		}
+	}
+	if background {
+		return float64(bg) * 1024
+	}
+	return float64(fg) * 1024
+}
+
+type backgroundKey struct{}
+
+// Background marks downloads made with the returned context as background
+// traffic, which is limited separately from what the user is waiting for
+func Background(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundKey{}, true)
+}
+
+// IsBackground reports whether ctx was marked with Background
+func IsBackground(ctx context.Context) bool {
+	background, _ := ctx.Value(backgroundKey{}).(bool)
+	return background
+}
+
+// maxWaitStep bounds each sleep so limit changes apply to running downloads quickly
+const maxWaitStep = 250 * time.Millisecond
+
+// bucket is a token bucket shared by all downloads of one traffic class
+type bucket struct {
+	mu         sync.Mutex
+	background bool
+	tokens     float64
+	last       time.Time
+}
+
+var (
+	foregroundBucket = &bucket{}
+	backgroundBucket = &bucket{background: true}
+)
+
+// refill adds the tokens earned since the last call and returns the current rate
+func (b *bucket) refill(now time.Time) float64 {
+	rate := currentRate(b.background, now)
+	if rate <= 0 {
+		// Unlimited; don't let debt from an earlier limit carry over
+		b.tokens = 0
+		b.last = now
+		return 0
+	}
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * rate
+	}
+	b.last = now
+	// Allow bursts of half a second's worth so reads don't stutter
+	if burst := rate / 2; b.tokens > burst {
+		b.tokens = burst
+	}
+	return rate
+}
+
+// wait takes n bytes from the bucket, sleeping until the class is back under its limit
+func (b *bucket) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	if b.refill(time.Now()) > 0 {
+		b.tokens -= float64(n)
+	}
+	b.mu.Unlock()
+
+	for {
+		b.mu.Lock()
+		rate := b.refill(time.Now())
+		if rate <= 0 || b.tokens >= 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration(-b.tokens / rate * float64(time.Second))
+		b.mu.Unlock()
+
+		if delay > maxWaitStep {
+			delay = maxWaitStep
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// throttle applies the global limit of the context's traffic class to n bytes
+func throttle(ctx context.Context, n int) error {
+	if IsBackground(ctx) {
+		return backgroundBucket.wait(ctx, n)
+	}
+	return foregroundBucket.wait(ctx, n)
+}
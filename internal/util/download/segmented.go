@@ -0,0 +1,328 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// segmentThreshold is the smallest file downloaded in parallel segments
+	segmentThreshold = 64 * 1024 * 1024
+	defaultSegments  = 4
+	// segmentsSuffix marks the saved progress of a segmented download
+	segmentsSuffix = ".segments"
+	// saveInterval is how often segment progress is written to disk
+	saveInterval = 2 * time.Second
+)
+
+// errNoRanges is returned when a server that advertised byte ranges answers a
+// range request with the whole file
+var errNoRanges = errors.New("server ignored the range request")
+
+// rangeProbe is what the server advertises about a file
+type rangeProbe struct {
+	size         int64
+	acceptRanges bool
+	etag         string
+}
+
+// probeRanges asks the server for the file size and byte range support
+func probeRanges(ctx context.Context, client *http.Client, req Request) (*rangeProbe, error) {
+	headReq, err := http.NewRequestWithContext(ctx, "HEAD", req.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	headReq.Header.Set("User-Agent", UserAgent)
+	for key, value := range req.Headers {
+		headReq.Header.Set(key, value)
+	}
+	resp, err := client.Do(headReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL)
+	}
+
+	probe := &rangeProbe{
+		size:         resp.ContentLength,
+		acceptRanges: resp.Header.Get("Accept-Ranges") == "bytes",
+		etag:         resp.Header.Get("ETag"),
+	}
+	if req.ExpectedSize > 0 && probe.size != req.ExpectedSize {
+		// Let the single stream report the mismatch
+		probe.acceptRanges = false
+	}
+	return probe, nil
+}
+
+// segment is one byte range of a segmented download; End is exclusive
+type segment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+// segmentState is the saved progress of a segmented download, kept next to the
+// partial file so an interrupted download resumes each segment
+type segmentState struct {
+	URL      string     `json:"url"`
+	Size     int64      `json:"size"`
+	ETag     string     `json:"etag,omitempty"`
+	Segments []*segment `json:"segments"`
+}
+
+func (st *segmentState) downloaded() int64 {
+	var total int64
+	for _, seg := range st.Segments {
+		total += seg.Done
+	}
+	return total
+}
+
+func loadSegmentState(path string) *segmentState {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var st segmentState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil
+	}
+	return &st
+}
+
+func (st *segmentState) save(path string) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// newSegmentState splits a file into count equal byte ranges
+func newSegmentState(req Request, probe *rangeProbe, count int) *segmentState {
+	st := &segmentState{URL: req.URL, Size: probe.size, ETag: probe.etag}
+	part := probe.size / int64(count)
+	for i := 0; i < count; i++ {
+		seg := &segment{Start: int64(i) * part, End: int64(i+1) * part}
+		if i == count-1 {
+			seg.End = probe.size
+		}
+		st.Segments = append(st.Segments, seg)
+	}
+	return st
+}
+
+// fetchSegmented downloads byte ranges of the file in parallel into a
+// preallocated partial file. Progress of each segment is saved, so later
+// attempts only fetch what is missing.
+func fetchSegmented(ctx context.Context, client *http.Client, req Request, probe *rangeProbe) error {
+	partial := req.Dest + partialSuffix
+	statePath := req.Dest + segmentsSuffix
+
+	count := req.Segments
+	if count <= 0 {
+		count = defaultSegments
+	}
+
+	st := loadSegmentState(statePath)
+	if st != nil && (st.URL != req.URL || st.Size != probe.size || st.ETag != probe.etag) {
+		fmt.Printf("Remote file changed, restarting download of %s\n", filepath.Base(req.Dest))
+		st = nil
+	}
+	if info, err := os.Stat(partial); st != nil && (err != nil || info.Size() != st.Size) {
+		st = nil
+	}
+
+	var file *os.File
+	var err error
+	if st == nil {
+		st = newSegmentState(req, probe, count)
+		file, err = os.Create(partial)
+		if err == nil {
+			err = file.Truncate(st.Size)
+		}
+		if err == nil {
+			err = st.save(statePath)
+		}
+	} else {
+		fmt.Printf("Resuming %s from %d bytes in %d segments\n", filepath.Base(req.Dest), st.downloaded(), len(st.Segments))
+		file, err = os.OpenFile(partial, os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return &permanentError{fmt.Errorf("failed to prepare %s: %w", partial, err)}
+	}
+	defer file.Close()
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		firstErr   error
+		started    = time.Now()
+		startBytes = st.downloaded()
+		meter      = newMeter(startBytes)
+		lastSave   = time.Now()
+	)
+
+	// progress records n written bytes of a segment; it returns how long the
+	// segment should sleep to keep the combined rate under the cap
+	progress := func(seg *segment, n int64) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		seg.Done += n
+		downloaded := st.downloaded()
+		if time.Since(lastSave) >= saveInterval {
+			st.save(statePath)
+			lastSave = time.Now()
+		}
+		if speed, eta, ok := meter.update(downloaded, st.Size); ok {
+			req.report(percent(downloaded, st.Size), req.message(eta), speed, downloaded, st.Size)
+		}
+		if req.BytesPerSec > 0 {
+			return time.Duration(float64(downloaded-startBytes)/float64(req.BytesPerSec)*float64(time.Second)) - time.Since(started)
+		}
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for _, seg := range st.Segments {
+		if seg.Start+seg.Done >= seg.End {
+			continue
+		}
+		wg.Add(1)
+		go func(seg *segment) {
+			defer wg.Done()
+			if err := fetchSegment(attemptCtx, client, req, file, seg, progress); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				// One failed segment fails the attempt; the others stop and
+				// everything resumes from the saved state on the next attempt
+				cancel()
+			}
+		}(seg)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	st.save(statePath)
+	mu.Unlock()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(statePath)
+	return finish(req, partial, st.Size)
+}
+
+// fetchSegment downloads the missing part of one segment with a range request
+func fetchSegment(ctx context.Context, client *http.Client, req Request, file *os.File, seg *segment, progress func(*segment, int64) time.Duration) error {
+	segCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := time.AfterFunc(stallTimeout, cancel)
+	defer stall.Stop()
+
+	offset := seg.Start + seg.Done
+	httpReq, err := http.NewRequestWithContext(segCtx, "GET", req.URL, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	httpReq.Header.Set("Accept", "*/*")
+	httpReq.Header.Set("Accept-Encoding", "identity")
+	httpReq.Header.Set("User-Agent", UserAgent)
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	httpReq.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, seg.End-1))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		if segCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("connection stalled")
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		// Without a range response the data can't go into this segment; Fetch
+		// makes the remaining attempts single streams
+		return fmt.Errorf("%w: got HTTP %d for a segment", errNoRanges, resp.StatusCode)
+	}
+	var rangeStart int64
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &rangeStart); err != nil || rangeStart != offset {
+		return fmt.Errorf("server returned range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+	}
+
+	buf := make([]byte, bufferSize)
+	for offset < seg.End {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			stall.Reset(stallTimeout)
+			if int64(n) > seg.End-offset {
+				n = int(seg.End - offset)
+			}
+			if _, err := file.WriteAt(buf[:n], offset); err != nil {
+				return &permanentError{fmt.Errorf("failed to write segment: %w", err)}
+			}
+			offset += int64(n)
+
+			stall.Stop()
+			if err := throttle(ctx, n); err != nil {
+				return err
+			}
+			stall.Reset(stallTimeout)
+
+			if ahead := progress(seg, int64(n)); ahead > 0 {
+				stall.Reset(stallTimeout + ahead)
+				select {
+				case <-time.After(ahead):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			if segCtx.Err() != nil && ctx.Err() == nil {
+				return fmt.Errorf("connection stalled in segment at %d bytes", offset)
+			}
+			return fmt.Errorf("read error: %w", readErr)
+		}
+	}
+
+	if offset < seg.End {
+		return fmt.Errorf("segment incomplete: got %d of %d bytes", offset-seg.Start, seg.End-seg.Start)
+	}
+	return nil
+}
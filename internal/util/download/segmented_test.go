@@ -0,0 +1,164 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// zeros is an endless stream of zero bytes
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestFetchFallsBackWhenRangesAreIgnored(t *testing.T) {
+	const size = segmentThreshold + 1
+	var fullResponses atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Advertises ranges but always sends the whole file
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		if r.Method == http.MethodHead {
+			return
+		}
+		fullResponses.Add(1)
+		io.CopyN(w, zeros{}, size)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	err := Fetch(context.Background(), Request{URL: server.URL, Dest: dest, ExpectedSize: size, Attempts: 3})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if info, err := os.Stat(dest); err != nil || info.Size() != size {
+		t.Fatalf("downloaded file: %v, %v", info, err)
+	}
+	for _, suffix := range []string{partialSuffix, segmentsSuffix} {
+		if _, err := os.Stat(dest + suffix); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", suffix)
+		}
+	}
+	// One round of segment requests, then a single stream
+	if n := fullResponses.Load(); n > defaultSegments+1 {
+		t.Errorf("server sent the whole file %d times", n)
+	}
+}
+
+// cutWriter passes on the first n bytes of a response and then fails, like a
+// connection that drops midway
+type cutWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *cutWriter) Write(p []byte) (int, error) {
+	if w.n <= 0 {
+		return 0, errors.New("connection dropped")
+	}
+	if int64(len(p)) > w.n {
+		p = p[:w.n]
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.n -= int64(n)
+	return n, err
+}
+
+func TestFetchResumesSegments(t *testing.T) {
+	const size = segmentThreshold + 4093
+	content := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	var (
+		mu     sync.Mutex
+		cut    int64 // Bytes sent per response before the connection drops (0 = all)
+		ranges []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		limit := cut
+		// Requests of the interrupted call may still arrive, so only resumed ones count
+		if r.Method == http.MethodGet && r.Header.Get("X-Resumed") != "" {
+			ranges = append(ranges, r.Header.Get("Range"))
+		}
+		mu.Unlock()
+		if limit > 0 && r.Method == http.MethodGet {
+			w = &cutWriter{ResponseWriter: w, n: limit}
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	req := Request{URL: server.URL, Dest: dest, ExpectedSize: size, Attempts: 1}
+
+	// The first call is interrupted in every segment
+	mu.Lock()
+	cut = 1 << 20
+	mu.Unlock()
+	if err := Fetch(context.Background(), req); err == nil {
+		t.Fatal("interrupted Fetch succeeded")
+	}
+	st := loadSegmentState(dest + segmentsSuffix)
+	if st == nil || len(st.Segments) != defaultSegments {
+		t.Fatalf("segment state after the interruption = %+v", st)
+	}
+	if st.downloaded() == 0 {
+		t.Fatal("nothing was downloaded before the interruption")
+	}
+	want := map[string]bool{}
+	var missing int64
+	for _, seg := range st.Segments {
+		if seg.Start+seg.Done < seg.End {
+			want[fmt.Sprintf("bytes=%d-%d", seg.Start+seg.Done, seg.End-1)] = true
+			missing += seg.End - seg.Start - seg.Done
+		}
+	}
+
+	// The second call only asks for what is missing
+	mu.Lock()
+	cut = 0
+	mu.Unlock()
+	req.Headers = map[string]string{"X-Resumed": "1"}
+	if err := Fetch(context.Background(), req); err != nil {
+		t.Fatalf("resumed Fetch: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ranges) != len(want) {
+		t.Errorf("resumed Fetch requested %v, want %d ranges", ranges, len(want))
+	}
+	for _, r := range ranges {
+		if !want[r] {
+			t.Errorf("resumed Fetch requested %q, which is not a missing range (%d bytes were missing)", r, missing)
+		}
+	}
+
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("downloaded file differs from the served content")
+	}
+	for _, suffix := range []string{partialSuffix, segmentsSuffix} {
+		if _, err := os.Stat(dest + suffix); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", suffix)
+		}
+	}
+}
@@ -10,23 +10,34 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// VerifySHA256 verifies the SHA256 checksum of a file
-func VerifySHA256(filePath, expectedHash string) error {
+// FileSHA256 returns the hex-encoded SHA256 checksum of a file
+func FileSHA256(filePath string) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer file.Close()
 
 	hasher := sha256.New()
 	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// VerifySHA256 verifies the SHA256 checksum of a file
+func VerifySHA256(filePath, expectedHash string) error {
+	actualHash, err := FileSHA256(filePath)
+	if err != nil {
 		return err
 	}
 
-	actualHash := hex.EncodeToString(hasher.Sum(nil))
 	expectedHash = strings.ToLower(strings.TrimSpace(expectedHash))
 
 	if actualHash != expectedHash {
@@ -223,5 +234,67 @@ func CopyDir(src, dst string) error {
 	return nil
 }
 
+// FormatBytes formats a byte count for display, e.g. "1.5 GB"
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
 // HideConsoleWindow hides the console window on Windows
 // Implementation is in util_windows.go and util_unix.go
+
+// ParseTimeWindow parses a "HH:MM-HH:MM" time-of-day window and returns its
+// start and end in minutes since midnight
+func ParseTimeWindow(window string) (int, int, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(window), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid window %q (expected HH:MM-HH:MM)", window)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", window, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window %q: %w", window, err)
+	}
+	return start, end, nil
+}
+
+// InTimeWindow reports whether t falls into a "HH:MM-HH:MM" window. Windows may
+// wrap past midnight, e.g. "22:00-06:00"; invalid windows never match.
+func InTimeWindow(window string, t time.Time) bool {
+	start, end, err := ParseTimeWindow(window)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func parseClock(clock string) (int, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(clock), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	return h*60 + m, nil
+}
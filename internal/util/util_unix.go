@@ -2,9 +2,47 @@
 
 package util
 
-import "os/exec"
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
 
 // HideConsoleWindow is a no-op on non-Windows platforms
 func HideConsoleWindow(cmd *exec.Cmd) {
 	// No-op on Unix systems
 }
+
+// DiskFree returns the bytes available to the current user on the file system holding path
+func DiskFree(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
+
+// SameFileSystem reports whether two existing paths are on the same file system,
+// meaning a rename between them is possible
+func SameFileSystem(a, b string) bool {
+	var statA, statB syscall.Stat_t
+	if syscall.Stat(a, &statA) != nil || syscall.Stat(b, &statB) != nil {
+		return false
+	}
+	return statA.Dev == statB.Dev
+}
+
+// LinkCount returns the number of hard links to a file
+func LinkCount(path string) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Lstat(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Nlink), nil
+}
+
+// IsCrossDevice reports whether a link or rename failed because source and
+// target are on different file systems
+func IsCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
@@ -3,8 +3,12 @@
 package util
 
 import (
+	"errors"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"unsafe"
 )
 
 // HideConsoleWindow hides the console window for commands on Windows
@@ -14,3 +18,59 @@ func HideConsoleWindow(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+var procGetDiskFreeSpaceExW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// DiskFree returns the bytes available to the current user on the volume holding path
+func DiskFree(path string) (uint64, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	ret, _, callErr := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(pathPtr)), uintptr(unsafe.Pointer(&available)), 0, 0)
+	if ret == 0 {
+		return 0, callErr
+	}
+	return available, nil
+}
+
+// SameFileSystem reports whether two existing paths are on the same volume,
+// meaning a rename between them is possible
+func SameFileSystem(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(filepath.VolumeName(absA), filepath.VolumeName(absB))
+}
+
+// LinkCount returns the number of hard links to a file
+func LinkCount(path string) (uint64, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	handle, err := syscall.CreateFile(pathPtr, 0, syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer syscall.CloseHandle(handle)
+
+	var info syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(handle, &info); err != nil {
+		return 0, err
+	}
+	return uint64(info.NumberOfLinks), nil
+}
+
+// errorNotSameDevice is ERROR_NOT_SAME_DEVICE
+const errorNotSameDevice syscall.Errno = 17
+
+// IsCrossDevice reports whether a link or rename failed because source and
+// target are on different volumes
+func IsCrossDevice(err error) bool {
+	return errors.Is(err, errorNotSameDevice)
+}
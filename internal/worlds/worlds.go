@@ -0,0 +1,393 @@
+package worlds
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"HyVanila/internal/backup"
+	"HyVanila/internal/env"
+	"HyVanila/internal/storage"
+	"HyVanila/internal/util"
+)
+
+// Conflict modes for import and copy when the target world already exists
+const (
+	ConflictFail      = "fail"
+	ConflictRename    = "rename"
+	ConflictOverwrite = "overwrite"
+)
+
+// World is a world folder found in an instance
+type World struct {
+	Name    string `json:"name"`
+	Branch  string `json:"branch"`
+	Version int    `json:"version"`
+	// Root is the instance-relative folder the world lives in ("UserData/Saves" or "saves")
+	Root      string `json:"root"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	FileCount int    `json:"fileCount"`
+	// LastPlayed is the newest modification time of any file in the world
+	LastPlayed string `json:"lastPlayed"` // ISO 8601 format
+}
+
+// roots are the instance-relative folders that hold worlds. The first one is
+// where the game reads worlds from, so imports and copies go there.
+var roots = []string{
+	filepath.Join("UserData", "Saves"),
+	"saves",
+}
+
+// List returns the worlds of an instance, most recently played first
+func List(branch string, version int) ([]World, error) {
+	instanceDir := env.GetInstanceDir(branch, version)
+	worlds := []World{}
+
+	for _, root := range roots {
+		entries, err := os.ReadDir(filepath.Join(instanceDir, root))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			world, err := stat(branch, version, root, entry.Name())
+			if err != nil {
+				fmt.Printf("Warning: Could not read world %s: %v\n", entry.Name(), err)
+				continue
+			}
+			worlds = append(worlds, *world)
+		}
+	}
+
+	sort.Slice(worlds, func(i, j int) bool {
+		return worlds[i].LastPlayed > worlds[j].LastPlayed
+	})
+	return worlds, nil
+}
+
+// stat builds the World for a world folder
+func stat(branch string, version int, root, name string) (*World, error) {
+	path := filepath.Join(env.GetInstanceDir(branch, version), root, name)
+	world := &World{
+		Name:    name,
+		Branch:  branch,
+		Version: version,
+		Root:    filepath.ToSlash(root),
+		Path:    path,
+	}
+
+	var lastPlayed time.Time
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(lastPlayed) {
+			lastPlayed = info.ModTime()
+		}
+		if d.Type().IsRegular() {
+			world.Size += info.Size()
+			world.FileCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	world.LastPlayed = lastPlayed.Format(time.RFC3339)
+	return world, nil
+}
+
+// find locates a world by name in any of an instance's roots
+func find(branch string, version int, name string) (*World, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	for _, root := range roots {
+		path := filepath.Join(env.GetInstanceDir(branch, version), root, name)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return stat(branch, version, root, name)
+		}
+	}
+	return nil, fmt.Errorf("world %q not found in %s v%d", name, branch, version)
+}
+
+// validateName rejects world names that could escape the saves folder
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\:`) {
+		return fmt.Errorf("invalid world name %q", name)
+	}
+	return nil
+}
+
+// Export writes a world to a zip archive with the world folder at its top level
+func Export(branch string, version int, name string, destPath string) error {
+	world, err := find(branch, version, name)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := destPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(out)
+	err = filepath.WalkDir(world.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(world.Path, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(world.Name, rel))
+		if d.IsDir() {
+			header.Name += "/"
+			_, err := zw.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, src)
+		src.Close()
+		return err
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to export world: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	fmt.Printf("Exported world %s to %s\n", name, destPath)
+	return nil
+}
+
+// Import extracts a world zip into an instance. A zip with a single top-level
+// folder is imported under that folder's name, otherwise under the zip's name.
+func Import(zipPath string, branch string, version int, conflict string) (*World, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open world archive: %w", err)
+	}
+	defer reader.Close()
+
+	savesDir := filepath.Join(env.GetInstanceDir(branch, version), roots[0])
+	if err := os.MkdirAll(savesDir, 0755); err != nil {
+		return nil, err
+	}
+
+	name, prefix := archiveLayout(reader.File)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(zipPath), filepath.Ext(zipPath))
+	}
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	// Extract into a staging folder in the instance (outside the saves, so backups
+	// taken on conflict don't pick it up) so the world only appears once complete
+	stagingDir, err := os.MkdirTemp(env.GetInstanceDir(branch, version), ".importing-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+	defer storage.MarkInUse(stagingDir)()
+
+	for _, file := range reader.File {
+		rel := strings.TrimPrefix(strings.TrimPrefix(filepath.ToSlash(file.Name), "./"), prefix)
+		if rel == "" {
+			continue
+		}
+		if err := extractEntry(file, rel, stagingDir); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err := resolveConflict(branch, version, name, conflict)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(stagingDir, filepath.Join(savesDir, target)); err != nil {
+		return nil, fmt.Errorf("failed to move imported world into place: %w", err)
+	}
+
+	fmt.Printf("Imported world %s into %s v%d\n", target, branch, version)
+	return stat(branch, version, roots[0], target)
+}
+
+// archiveLayout returns the world name and entry prefix if all entries share a
+// single top-level folder, or empty strings if the archive is the world itself
+func archiveLayout(files []*zip.File) (string, string) {
+	top := ""
+	for _, file := range files {
+		name := strings.TrimPrefix(filepath.ToSlash(file.Name), "./")
+		first, _, nested := strings.Cut(name, "/")
+		if !nested && !file.FileInfo().IsDir() {
+			// A file at the top level: the archive root is the world
+			return "", ""
+		}
+		if top == "" {
+			top = first
+		} else if top != first {
+			return "", ""
+		}
+	}
+	if top == "" {
+		return "", ""
+	}
+	return top, top + "/"
+}
+
+// extractEntry writes a single zip entry below dest, rejecting paths that escape it
+func extractEntry(file *zip.File, rel string, dest string) error {
+	path := filepath.Join(dest, filepath.FromSlash(rel))
+
+	// Security check for path traversal
+	if !strings.HasPrefix(filepath.Clean(path), filepath.Clean(dest)+string(os.PathSeparator)) {
+		return fmt.Errorf("invalid file path: %s", file.Name)
+	}
+	if file.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("symbolic links are not allowed in world archives: %s", file.Name)
+	}
+
+	if file.FileInfo().IsDir() {
+		return os.MkdirAll(path, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	outFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	rc, err := file.Open()
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+	_, err = io.Copy(outFile, rc)
+	rc.Close()
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	os.Chtimes(path, file.Modified, file.Modified)
+	return nil
+}
+
+// Copy copies a world from one instance to another
+func Copy(srcBranch string, srcVersion int, name string, dstBranch string, dstVersion int, conflict string) (*World, error) {
+	src, err := find(srcBranch, srcVersion, name)
+	if err != nil {
+		return nil, err
+	}
+
+	savesDir := filepath.Join(env.GetInstanceDir(dstBranch, dstVersion), roots[0])
+	if err := os.MkdirAll(savesDir, 0755); err != nil {
+		return nil, err
+	}
+	if srcBranch == dstBranch && srcVersion == dstVersion && conflict != ConflictRename {
+		return nil, fmt.Errorf("world %q is already in this instance", name)
+	}
+
+	stagingDir, err := os.MkdirTemp(env.GetInstanceDir(dstBranch, dstVersion), ".copying-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+	defer storage.MarkInUse(stagingDir)()
+
+	if err := util.CopyDir(src.Path, stagingDir); err != nil {
+		return nil, fmt.Errorf("failed to copy world: %w", err)
+	}
+
+	target, err := resolveConflict(dstBranch, dstVersion, name, conflict)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(stagingDir, filepath.Join(savesDir, target)); err != nil {
+		return nil, fmt.Errorf("failed to move copied world into place: %w", err)
+	}
+
+	fmt.Printf("Copied world %s from %s v%d to %s v%d as %s\n", name, srcBranch, srcVersion, dstBranch, dstVersion, target)
+	return stat(dstBranch, dstVersion, roots[0], target)
+}
+
+// resolveConflict returns the folder name to use for a world in the target
+// instance's saves, applying the conflict mode if the name is taken
+func resolveConflict(branch string, version int, name string, conflict string) (string, error) {
+	savesDir := filepath.Join(env.GetInstanceDir(branch, version), roots[0])
+	if _, err := os.Stat(filepath.Join(savesDir, name)); os.IsNotExist(err) {
+		return name, nil
+	}
+
+	switch conflict {
+	case ConflictRename:
+		for i := 2; ; i++ {
+			candidate := fmt.Sprintf("%s (%d)", name, i)
+			if _, err := os.Stat(filepath.Join(savesDir, candidate)); os.IsNotExist(err) {
+				return candidate, nil
+			}
+		}
+	case ConflictOverwrite:
+		// Keep the replaced world recoverable
+		if _, err := backup.Create(branch, version, backup.ReasonPreOverwrite, fmt.Sprintf("Before replacing world %s", name)); err != nil {
+			return "", fmt.Errorf("failed to back up world before overwriting: %w", err)
+		}
+		if err := os.RemoveAll(filepath.Join(savesDir, name)); err != nil {
+			return "", err
+		}
+		return name, nil
+	default:
+		return "", fmt.Errorf("a world named %q already exists in %s v%d", name, branch, version)
+	}
+}
@@ -63,7 +63,7 @@ fi
 
 # Build webkit2_41 version for AppImage (modern systems)
 echo -e "${YELLOW}Building AppImage version (webkit2gtk-4.1)...${NC}"
-wails build -clean -tags webkit2_41 -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE'"
+wails build -clean -tags webkit2_41 -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE' -X 'HyVanila/updater.PublicKey=$UPDATE_PUBLIC_KEY'"
 
 if [ $? -ne 0 ]; then
     echo -e "${RED}Build failed!${NC}"
@@ -76,7 +76,7 @@ echo -e "${GREEN}✓ webkit41 build completed${NC}"
 
 # Build webkit2gtk-4.0 version for Flatpak (older distros)
 echo -e "${YELLOW}Building Flatpak version (webkit2gtk-4.0)...${NC}"
-wails build -clean -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE'"
+wails build -clean -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE' -X 'HyVanila/updater.PublicKey=$UPDATE_PUBLIC_KEY'"
 
 if [ $? -ne 0 ]; then
     echo -e "${RED}Build failed!${NC}"
@@ -40,7 +40,7 @@ fi
 
 # Build the application
 echo -e "${YELLOW}Building application...${NC}"
-wails build -clean -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE'"
+wails build -clean -ldflags "-X 'HyVanila/app.AppVersion=$VERSION' -X 'HyVanila/app.AppTitle=$APP_TITLE' -X 'HyVanila/updater.PublicKey=$UPDATE_PUBLIC_KEY'"
 
 if [ $? -ne 0 ]; then
     echo -e "${RED}Build failed!${NC}"
@@ -15,7 +15,7 @@ cd frontend && npm install && cd ..
 # Build for each platform
 for platform in "${PLATFORMS[@]}"; do
     echo "Building for ${platform}..."
-    wails build -platform "${platform}" -ldflags "-X 'HyVanila/app.AppVersion=${VERSION}'" -o "HyVanila-${platform//\//-}"
+    wails build -platform "${platform}" -ldflags "-X 'HyVanila/app.AppVersion=${VERSION}' -X 'HyVanila/updater.PublicKey=${UPDATE_PUBLIC_KEY}'" -o "HyVanila-${platform//\//-}"
 done
 
 echo "Build complete!"
@@ -106,6 +106,10 @@ RELEASE_NOTES=$(cat << EOF
 EOF
 )
 
+# Sign every release file; launchers refuse unsigned updates
+echo -e "${YELLOW}Signing release files...${NC}"
+"$(dirname "$0")/sign-release.sh" releases
+
 # Create the release
 echo -e "${YELLOW}Creating GitHub release...${NC}"
 gh release create "${TAG}" \
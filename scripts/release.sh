@@ -26,6 +26,13 @@ if ! git diff-index --quiet HEAD --; then
     exit 1
 fi
 
+# Tool pins need their checksums, or fresh installs can't download butler or Java
+if ! go test ./internal/tools >/dev/null; then
+    echo "Error: internal/tools/tools.json has missing or invalid checksums."
+    echo "Run 'go generate ./internal/tools' and commit the result."
+    exit 1
+fi
+
 echo "Creating release $TAG..."
 
 # Create and push tag (no need to update wails.json as it doesn't have a version field)
@@ -0,0 +1,35 @@
+#!/bin/bash
+set -e
+
+# Sign Release Files Script
+# Writes a detached ed25519 signature (<file>.sig, base64) next to every file in
+# a directory. The launcher refuses updates whose version.json or binary isn't
+# signed with the key embedded at build time.
+#
+# Usage: UPDATE_SIGNING_KEY=/path/to/key.pem ./scripts/sign-release.sh [dir]
+#
+# Create a key pair once with:
+#   openssl genpkey -algorithm ed25519 -out update-signing.pem
+# and embed the public key in builds through UPDATE_PUBLIC_KEY:
+#   export UPDATE_PUBLIC_KEY=$(openssl pkey -in update-signing.pem -pubout -outform DER | tail -c 32 | base64)
+
+# Colors
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+NC='\033[0m' # No Color
+
+DIR="${1:-releases}"
+
+if [ -z "$UPDATE_SIGNING_KEY" ] || [ ! -f "$UPDATE_SIGNING_KEY" ]; then
+    echo -e "${RED}Error: UPDATE_SIGNING_KEY must point to the ed25519 private key (PEM)${NC}"
+    exit 1
+fi
+
+for file in "$DIR"/*; do
+    case "$file" in
+        *.sig) continue ;;
+    esac
+    [ -f "$file" ] || continue
+    openssl pkeyutl -sign -inkey "$UPDATE_SIGNING_KEY" -rawin -in "$file" | base64 | tr -d '\n' > "$file.sig"
+    echo -e "${GREEN}✓ Signed $(basename "$file")${NC}"
+done
@@ -8,122 +8,145 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strings"
 )
 
-// Apply applies a launcher update on Unix systems and restarts the app
-func Apply(tmp string) error {
-	exe, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
-
-	// Create a shell script to replace the binary and restart
-	scriptPath := filepath.Join(os.TempDir(), "hyvanila-update.sh")
-	
-	var script string
+// installScript returns the shell commands that back up the current launcher
+// and install the downloaded update over it
+func installScript(tmp string, p *PendingUpdate) string {
 	if runtime.GOOS == "darwin" {
 		// For macOS - the update is a DMG file containing the .app bundle
-		// Navigate up from Contents/MacOS/executable to the .app bundle
-		appBundlePath := exe
-		for i := 0; i < 3; i++ {
-			appBundlePath = filepath.Dir(appBundlePath)
-		}
-		if !strings.HasSuffix(appBundlePath, ".app") {
-			return fmt.Errorf("not running from an app bundle: %s", appBundlePath)
-		}
-		
-		appName := filepath.Base(appBundlePath) // e.g., "HyVanila.app"
-		appDir := filepath.Dir(appBundlePath)   // e.g., "/Applications"
 		mountPoint := filepath.Join(os.TempDir(), "hyvanila-dmg-mount")
-		
-		script = fmt.Sprintf(`#!/bin/bash
-set -e
-
-echo "Starting HyVanila update..."
-
-# Wait for app to close
+		return fmt.Sprintf(`# Wait for app to close
 sleep 2
 
 # Create mount point
-mkdir -p "%s"
+mkdir -p "%[3]s"
 
 # Mount the DMG
 echo "Mounting update package..."
-hdiutil attach "%s" -mountpoint "%s" -nobrowse -quiet
+hdiutil attach "%[4]s" -mountpoint "%[3]s" -nobrowse -quiet
 
 # Find the .app in the mounted DMG
 APP_IN_DMG=""
-for item in "%s"/*.app; do
+for item in "%[3]s"/*.app; do
     if [ -d "$item" ]; then
         APP_IN_DMG="$item"
         break
     fi
 done
 
-if [ -z "$APP_IN_DMG" ]; then
-    echo "Error: No .app found in update package"
-    hdiutil detach "%s" -quiet 2>/dev/null || true
-    exit 1
-fi
+if [ -n "$APP_IN_DMG" ]; then
+    echo "Found app: $APP_IN_DMG"
 
-echo "Found app: $APP_IN_DMG"
+    # Keep the current version for rollbacks
+    echo "Backing up current version..."
+    rm -rf "%[2]s"
+    cp -R "%[1]s" "%[2]s"
 
-# Backup old app
-echo "Backing up current version..."
-rm -rf "%s.old" 2>/dev/null || true
-mv "%s" "%s.old" 2>/dev/null || true
+    echo "Installing new version..."
+    rm -rf "%[1]s.old" 2>/dev/null || true
+    mv "%[1]s" "%[1]s.old"
+    cp -R "$APP_IN_DMG" "%[1]s"
 
-# Copy new app
-echo "Installing new version..."
-cp -R "$APP_IN_DMG" "%s/"
+    # Remove quarantine attribute (important for macOS security)
+    echo "Removing quarantine..."
+    xattr -cr "%[1]s" 2>/dev/null || true
 
-# Remove quarantine attribute (important for macOS security)
-echo "Removing quarantine..."
-xattr -cr "%s/%s" 2>/dev/null || true
-
-# Set executable permissions
-chmod +x "%s/%s/Contents/MacOS/"* 2>/dev/null || true
+    # Set executable permissions
+    chmod +x "%[1]s/Contents/MacOS/"* 2>/dev/null || true
+    rm -rf "%[1]s.old" 2>/dev/null || true
+else
+    # The current version stays; it reports the failed update when it starts
+    echo "Error: No .app found in update package"
+fi
 
 # Unmount DMG
 echo "Cleaning up..."
-hdiutil detach "%s" -quiet 2>/dev/null || true
-rmdir "%s" 2>/dev/null || true
+hdiutil detach "%[3]s" -quiet 2>/dev/null || true
+rmdir "%[3]s" 2>/dev/null || true
+rm -f "%[4]s" 2>/dev/null || true
+`, p.Target, p.Backup, mountPoint, tmp)
+	}
+
+	// Linux
+	return fmt.Sprintf(`sleep 1
+# Keep the current version for rollbacks
+rm -f "%[2]s"
+cp -p "%[1]s" "%[2]s"
+mv "%[1]s" "%[1]s.old" 2>/dev/null
+cp "%[3]s" "%[1]s"
+chmod +x "%[1]s"
+rm -f "%[1]s.old"
+rm -f "%[3]s"
+`, p.Target, p.Backup, tmp)
+}
+
+// swapScript returns the shell commands that exchange the current launcher
+// with the kept one
+func swapScript(p *PendingUpdate) string {
+	return fmt.Sprintf(`sleep 2
+rm -rf "%[1]s.swap"
+mv "%[1]s" "%[1]s.swap"
+if mv "%[2]s" "%[1]s"; then
+    mv "%[1]s.swap" "%[2]s"
+else
+    mv "%[1]s.swap" "%[1]s"
+fi
+`, p.Target, p.Backup)
+}
 
-# Remove backup and temp files
-rm -rf "%s.old" 2>/dev/null || true
-rm -f "%s" 2>/dev/null || true
+// runHelper starts a script that runs the install commands, launches the result
+// and watches it: if the launcher exits or hangs before confirming its start,
+// the kept version is put back and started instead. Swaps that prepareRollback
+// didn't record as pending finish right after the launch.
+func runHelper(install string, p *PendingUpdate) error {
+	scriptPath := filepath.Join(os.TempDir(), "hyvanila-update.sh")
 
-echo "Update complete! Launching HyVanila..."
+	// macOS apps are started through "open", which -W keeps running with the app
+	launch := fmt.Sprintf(`"%s"`, p.Target)
+	stop := `kill "$PID" 2>/dev/null`
+	relaunch := fmt.Sprintf(`"%s" &`, p.Target)
+	if runtime.GOOS == "darwin" {
+		launch = fmt.Sprintf(`open -W "%s"`, p.Target)
+		stop = fmt.Sprintf(`pkill -f "%s/Contents/MacOS/" 2>/dev/null`, p.Target)
+		relaunch = fmt.Sprintf(`open "%s"`, p.Target)
+	}
 
-# Restart the application
-open "%s/%s"
+	script := fmt.Sprintf(`#!/bin/bash
+echo "Starting HyVanila update..."
+
+%[1]s
+echo "Launching HyVanila..."
+%[2]s &
+PID=$!
+
+# The launcher removes the marker once it has started
+for i in $(seq 1 %[3]d); do
+    if [ ! -f "%[4]s" ]; then
+        echo "Update complete!"
+        rm -f "%[9]s"
+        exit 0
+    fi
+    if ! kill -0 "$PID" 2>/dev/null; then
+        break
+    fi
+    sleep 1
+done
+
+if [ -f "%[4]s" ]; then
+    echo "New version failed to start, restoring the previous one..."
+    %[5]s
+    sleep 1
+    rm -rf "%[6]s"
+    mv "%[7]s" "%[6]s"
+    chmod +x "%[6]s" 2>/dev/null || true
+    mv "%[4]s" "%[8]s"
+    %[10]s
+fi
 
 # Clean up this script
-rm -f "%s"
-`, mountPoint, tmp, mountPoint, mountPoint, mountPoint,
-   appBundlePath, appBundlePath, appBundlePath,
-   appDir,
-   appDir, appName,
-   appDir, appName,
-   mountPoint, mountPoint,
-   appBundlePath, tmp,
-   appDir, appName,
-   scriptPath)
-	} else {
-		// Linux
-		script = fmt.Sprintf(`#!/bin/bash
-sleep 1
-mv "%s" "%s.old" 2>/dev/null
-cp "%s" "%s"
-chmod +x "%s"
-rm -f "%s.old"
-rm -f "%s"
-# Restart the application
-"%s" &
-rm -f "%s"
-`, exe, exe, tmp, exe, exe, exe, tmp, exe, scriptPath)
-	}
+rm -f "%[9]s"
+`, install, launch, healthTimeout, getPendingPath(), stop, p.Target, p.Backup, getRolledBackPath(), scriptPath, relaunch)
 
 	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
 		return fmt.Errorf("failed to create update script: %w", err)
@@ -136,7 +159,5 @@ rm -f "%s"
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start update script: %w", err)
 	}
-
-	os.Exit(0)
 	return nil
 }
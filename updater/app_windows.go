@@ -10,28 +10,69 @@ import (
 	"path/filepath"
 )
 
-// Apply applies a launcher update on Windows and restarts the app
-func Apply(tmp string) error {
-	exe, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
-	}
+// installScript returns the batch commands that back up the current launcher
+// and install the downloaded update over it
+func installScript(tmp string, p *PendingUpdate) string {
+	return fmt.Sprintf(`timeout /t 1 /nobreak >nul
+REM Keep the current version for rollbacks
+copy /y "%[1]s" "%[2]s" >nul
+del /f /q "%[1]s.old" 2>nul
+ren "%[1]s" "%[3]s.old" 2>nul
+copy /y "%[4]s" "%[1]s" >nul
+del /f /q "%[1]s.old" 2>nul
+del /f /q "%[4]s" 2>nul
+`, p.Target, p.Backup, filepath.Base(p.Target), tmp)
+}
+
+// swapScript returns the batch commands that exchange the current launcher
+// with the kept one
+func swapScript(p *PendingUpdate) string {
+	return fmt.Sprintf(`timeout /t 2 /nobreak >nul
+del /f /q "%[1]s.swap" 2>nul
+move /y "%[1]s" "%[1]s.swap" >nul
+move /y "%[2]s" "%[1]s" >nul
+if errorlevel 1 (
+    move /y "%[1]s.swap" "%[1]s" >nul
+) else (
+    move /y "%[1]s.swap" "%[2]s" >nul
+)
+`, p.Target, p.Backup)
+}
 
-	// Create a batch script to replace the executable and restart
+// runHelper starts a script that runs the install commands, launches the result
+// and watches it: if the launcher exits or hangs before confirming its start,
+// the kept version is put back and started instead. Swaps that prepareRollback
+// didn't record as pending finish right after the launch.
+func runHelper(install string, p *PendingUpdate) error {
 	scriptPath := filepath.Join(os.TempDir(), "hyvanila-update.bat")
 	script := fmt.Sprintf(`@echo off
-timeout /t 1 /nobreak >nul
-del /f /q "%s.old" 2>nul
-ren "%s" "%s.old" 2>nul
-copy /y "%s" "%s" >nul
-del /f /q "%s.old" 2>nul
-del /f /q "%s" 2>nul
+%[1]s
 REM Restart the application
-start "" "%s"
-del /f /q "%s" 2>nul
+start "" "%[2]s"
+
+REM The launcher removes the marker once it has started
+set /a WAITED=0
+:watch
+if not exist "%[3]s" goto done
+timeout /t 1 /nobreak >nul
+tasklist /FI "IMAGENAME eq %[4]s" 2>nul | find /I "%[4]s" >nul
+if errorlevel 1 goto rollback
+set /a WAITED+=1
+if %%WAITED%% LSS %[5]d goto watch
+
+:rollback
+if not exist "%[3]s" goto done
+taskkill /F /IM "%[4]s" >nul 2>&1
+timeout /t 1 /nobreak >nul
+del /f /q "%[2]s" 2>nul
+move /y "%[6]s" "%[2]s" >nul
+move /y "%[3]s" "%[7]s" >nul
+start "" "%[2]s"
+
+:done
+del /f /q "%[8]s" 2>nul
 exit
-`,
-		exe, exe, filepath.Base(exe), tmp, exe, exe, tmp, exe, scriptPath)
+`, install, p.Target, getPendingPath(), filepath.Base(p.Target), healthTimeout, p.Backup, getRolledBackPath(), scriptPath)
 
 	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
 		return fmt.Errorf("failed to create update script: %w", err)
@@ -43,7 +84,5 @@ exit
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start update script: %w", err)
 	}
-
-	os.Exit(0)
 	return nil
 }
@@ -6,16 +6,62 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"runtime"
 	"strings"
 )
 
 const versionJSONAsset = "version.json"
 
+// Update channels
+const (
+	ChannelStable  = "stable"
+	ChannelBeta    = "beta"
+	ChannelNightly = "nightly"
+)
+
+// channelTags maps each channel to the release tag its builds are published
+// under; stable builds come from the latest release
+var channelTags = map[string]string{
+	ChannelStable:  "",
+	ChannelBeta:    "beta",
+	ChannelNightly: "nightly",
+}
+
+// ValidateChannel checks an update channel name
+func ValidateChannel(channel string) error {
+	if _, ok := channelTags[channel]; !ok {
+		return fmt.Errorf("unknown update channel %q (use stable, beta or nightly)", channel)
+	}
+	return nil
+}
+
+// DefaultChannel returns the channel a build was published on, used until the
+// user picks one
+func DefaultChannel(current string) string {
+	if strings.HasPrefix(current, nightlyPrefix) {
+		return ChannelNightly
+	}
+	if v, err := ParseVersion(current); err == nil && len(v.Prerelease) > 0 {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
 // UpdateInfo represents the update information
 type UpdateInfo struct {
-	Version string `json:"version"`
-	Linux   struct {
+	Version     string `json:"version"`
+	Notes       string `json:"notes"`       // Release notes shown before installing (Markdown)
+	ReleaseDate string `json:"releaseDate"` // ISO 8601 format
+	// Rollback is set for launchers that confirm their start (see MarkHealthy),
+	// so the update helper can watch them and undo a failed update. Older
+	// releases never confirm and are installed without the watch.
+	Rollback bool `json:"rollback"`
+	// Assets are the launcher artifacts keyed by "os/arch/format", e.g.
+	// "linux/arm64/appimage" or "darwin/arm64/dmg"
+	Assets map[string]Asset `json:"assets"`
+
+	// Per-platform launchers of the old version.json layout, still published for
+	// launchers that predate Assets and used here when Assets is missing
+	Linux struct {
 		Amd64 struct {
 			Launcher Asset `json:"launcher"`
 		} `json:"amd64"`
@@ -35,57 +81,140 @@ type UpdateInfo struct {
 	} `json:"darwin"`
 }
 
+// assetFor returns the artifact matching an install, or nil if none is published
+func (info *UpdateInfo) assetFor(install *Install) *Asset {
+	if len(info.Assets) > 0 {
+		if asset, ok := info.Assets[install.Key()]; ok && asset.URL != "" {
+			return &asset
+		}
+		return nil
+	}
+
+	var legacy *Asset
+	switch install.Key() {
+	case "linux/amd64/" + FormatBinary:
+		legacy = &info.Linux.Amd64.Launcher
+	case "windows/amd64/" + FormatBinary:
+		legacy = &info.Windows.Amd64.Launcher
+	case "darwin/amd64/" + FormatDMG:
+		legacy = &info.Darwin.Amd64.Launcher
+	case "darwin/arm64/" + FormatDMG:
+		legacy = &info.Darwin.Arm64.Launcher
+	}
+	if legacy == nil || legacy.URL == "" {
+		return nil
+	}
+	return legacy
+}
+
 // Asset represents a downloadable asset
 type Asset struct {
 	URL    string `json:"url"`
 	Sha256 string `json:"sha256"`
 }
 
-// CheckUpdate checks for launcher updates
-func CheckUpdate(ctx context.Context, current string) (*Asset, string, error) {
-	// Determine if this is a nightly build
-	isNightly := strings.HasPrefix(current, "nightly-")
-	
-	info, err := fetchUpdateInfo(ctx, isNightly)
+// UpdateCheck describes a launcher update offered to the user
+type UpdateCheck struct {
+	Channel        string `json:"channel"`
+	CurrentVersion string `json:"currentVersion"`
+	Version        string `json:"version"`
+	Notes          string `json:"notes"`
+	ReleaseDate    string `json:"releaseDate,omitempty"`
+	// Downgrade is set when the offered version isn't newer than the current one
+	Downgrade bool `json:"downgrade"`
+	// Rollback is set when the update is undone automatically if it fails to start
+	Rollback bool `json:"rollback"`
+	// Format is the package format of the running launcher
+	Format string `json:"format"`
+	// Asset is the artifact to install; it is nil for installs updated by a
+	// package manager, which get Instructions instead
+	Asset        *Asset `json:"asset"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// CheckUpdate checks a channel for launcher updates. It returns nil if there
+// is nothing to install, and a check without an Asset if the update has to come
+// from a package manager. Versions that aren't newer than current are only
+// offered with allowDowngrade, e.g. after switching to a more stable channel.
+func CheckUpdate(ctx context.Context, current, channel string, allowDowngrade bool) (*UpdateCheck, error) {
+	if err := ValidateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	info, err := fetchUpdateInfo(ctx, channelTags[channel])
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	currentClean := strings.TrimPrefix(strings.TrimSpace(current), "v")
-	latestClean := strings.TrimPrefix(strings.TrimSpace(info.Version), "v")
+	fmt.Printf("Current version: %s, Latest version: %s (channel: %s)\n", current, info.Version, channel)
 
-	fmt.Printf("Current version: %s, Latest version: %s (nightly: %v)\n", current, info.Version, isNightly)
+	check := &UpdateCheck{
+		Channel:        channel,
+		CurrentVersion: current,
+		Version:        info.Version,
+		Notes:          info.Notes,
+		ReleaseDate:    info.ReleaseDate,
+		Rollback:       info.Rollback,
+	}
 
-	if currentClean == latestClean {
+	cmp, err := compareToCurrent(info.Version, current)
+	switch {
+	case err != nil:
+		if !allowDowngrade {
+			fmt.Printf("Not updating: can't compare versions: %v\n", err)
+			return nil, nil
+		}
+		check.Downgrade = true
+	case cmp == 0:
 		fmt.Println("Already on latest version")
-		return nil, "", nil
-	}
-
-	var asset *Asset
-	switch runtime.GOOS {
-	case "windows":
-		asset = &info.Windows.Amd64.Launcher
-		fmt.Printf("Update available for Windows: %s -> %s\n", current, info.Version)
-	case "darwin":
-		if runtime.GOARCH == "arm64" {
-			asset = &info.Darwin.Arm64.Launcher
-		} else {
-			asset = &info.Darwin.Amd64.Launcher
+		return nil, nil
+	case cmp < 0:
+		if !allowDowngrade {
+			fmt.Printf("Not downgrading from %s to %s\n", current, info.Version)
+			return nil, nil
 		}
-		fmt.Printf("Update available for macOS: %s -> %s\n", current, info.Version)
-	default:
-		asset = &info.Linux.Amd64.Launcher
-		fmt.Printf("Update available for Linux: %s -> %s\n", current, info.Version)
+		check.Downgrade = true
+	}
+
+	install, err := DetectInstall()
+	if err != nil {
+		return nil, err
+	}
+	check.Format = install.Format
+	if !install.SelfUpdating() {
+		fmt.Printf("Update %s -> %s is left to the package manager (%s)\n", current, info.Version, install.Format)
+		check.Instructions = install.ManagedInstructions()
+		return check, nil
 	}
 
-	if asset.URL == "" {
-		return nil, "", fmt.Errorf("no download URL found for %s/%s", runtime.GOOS, runtime.GOARCH)
+	asset := info.assetFor(install)
+	if asset == nil {
+		return nil, fmt.Errorf("no launcher update published for %s", install.Key())
 	}
+	fmt.Printf("Update available for %s: %s -> %s\n", install.Key(), current, info.Version)
+
+	check.Asset = asset
+	return check, nil
+}
 
-	return asset, info.Version, nil
+// compareToCurrent orders the remote version against the running one
+func compareToCurrent(latest, current string) (int, error) {
+	cmp, err := CompareVersions(latest, current)
+	if err == nil {
+		return cmp, nil
+	}
+	// Nightlies without a semantic version are ordered by the nightly tag
+	// itself, which always points at the newest build
+	if strings.HasPrefix(latest, nightlyPrefix) && strings.HasPrefix(current, nightlyPrefix) {
+		if strings.TrimSpace(latest) == strings.TrimSpace(current) {
+			return 0, nil
+		}
+		return 1, nil
+	}
+	return 0, err
 }
 
-func fetchUpdateInfo(ctx context.Context, isNightly bool) (*UpdateInfo, error) {
+func fetchUpdateInfo(ctx context.Context, tag string) (*UpdateInfo, error) {
 	tmpFile, err := os.CreateTemp("", "version-*.json")
 	if err != nil {
 		return nil, err
@@ -94,8 +223,8 @@ func fetchUpdateInfo(ctx context.Context, isNightly bool) (*UpdateInfo, error) {
 	tmpFile.Close()
 	defer os.Remove(tmpPath)
 
-	// Download version.json from the appropriate release channel
-	err = download.DownloadReleaseAsset(ctx, versionJSONAsset, tmpPath, isNightly, nil)
+	// Download version.json from the channel's release
+	err = download.DownloadReleaseAsset(ctx, versionJSONAsset, tmpPath, tag, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch update info: %w", err)
 	}
@@ -105,6 +234,15 @@ func fetchUpdateInfo(ctx context.Context, isNightly bool) (*UpdateInfo, error) {
 		return nil, err
 	}
 
+	// The hashes in version.json are only as trustworthy as the file itself
+	signature, err := fetchSignature(ctx, download.ReleaseAssetURL(versionJSONAsset, tag))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnverified, err)
+	}
+	if err := verifySignature(data, signature); err != nil {
+		return nil, fmt.Errorf("%w: update info: %v", ErrUnverified, err)
+	}
+
 	var info UpdateInfo
 	if err := json.Unmarshal(data, &info); err != nil {
 		return nil, fmt.Errorf("failed to parse update info: %w", err)
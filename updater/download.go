@@ -8,19 +8,35 @@ import (
 	"path/filepath"
 )
 
-// DownloadUpdate downloads a launcher update
-func DownloadUpdate(ctx context.Context, url string, progress func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
-	fmt.Printf("Starting download from: %s\n", url)
+// DownloadUpdate downloads a launcher update and verifies its checksum and
+// signature. The returned file is safe to pass to Apply.
+func DownloadUpdate(ctx context.Context, asset *Asset, progress func(stage string, progress float64, message string, currentFile string, speed string, downloaded, total int64)) (string, error) {
+	fmt.Printf("Starting download from: %s\n", asset.URL)
 
 	tmp := filepath.Join(os.TempDir(), "hyvanila-update.tmp")
 
 	_ = os.Remove(tmp)
 
-	if err := download.DownloadWithProgress(tmp, url, "update", 1.0, progress); err != nil {
+	err := download.Fetch(ctx, download.Request{
+		URL:      asset.URL,
+		Dest:     tmp,
+		SHA256:   asset.Sha256,
+		Stage:    "update",
+		Progress: progress,
+	})
+	if err != nil {
 		_ = os.Remove(tmp)
 		return "", fmt.Errorf("failed to download update: %w", err)
 	}
 
-	fmt.Printf("Download complete: %s\n", tmp)
+	if progress != nil {
+		progress("update", 100, "Verifying signature...", filepath.Base(tmp), "", 0, 0)
+	}
+	if err := verifyFile(ctx, tmp, asset.URL); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("%w: %v", ErrUnverified, err)
+	}
+
+	fmt.Printf("Download complete and verified: %s\n", tmp)
 	return tmp, nil
 }
@@ -0,0 +1,96 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"HyVanila/internal/env"
+)
+
+// Package formats the launcher is distributed in
+const (
+	FormatBinary   = "binary"   // Plain executable, replaced in place
+	FormatAppImage = "appimage" // Linux AppImage; the .AppImage file is replaced
+	FormatDMG      = "dmg"      // macOS app bundle, updated from a disk image
+	FormatFlatpak  = "flatpak"  // Updated by Flatpak, never replaced by the launcher
+)
+
+// defaultFlatpakID is used when the sandbox doesn't report the app ID
+const defaultFlatpakID = "dev.hyvanila.HyVanila"
+
+// Install describes how the running launcher was installed
+type Install struct {
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	Format string `json:"format"`
+	// Path is the file or app bundle an update replaces (empty for managed installs)
+	Path string `json:"path"`
+}
+
+// Key returns the "os/arch/format" key of the install's artifact in version.json
+func (i *Install) Key() string {
+	return i.OS + "/" + i.Arch + "/" + i.Format
+}
+
+// SelfUpdating reports whether the launcher replaces itself, rather than a
+// package manager updating it
+func (i *Install) SelfUpdating() bool {
+	return i.Format != FormatFlatpak
+}
+
+// ManagedInstructions tells the user how to update an install the launcher
+// doesn't replace itself
+func (i *Install) ManagedInstructions() string {
+	if i.Format == FormatFlatpak {
+		id := os.Getenv("FLATPAK_ID")
+		if id == "" {
+			id = defaultFlatpakID
+		}
+		return fmt.Sprintf("This launcher is installed with Flatpak. Update it from your software center or run: flatpak update %s", id)
+	}
+	return ""
+}
+
+// DetectInstall works out the platform and package format of the running launcher
+func DetectInstall() (*Install, error) {
+	install := &Install{OS: runtime.GOOS, Arch: runtime.GOARCH, Format: FormatBinary}
+
+	if env.IsFlatpak() {
+		install.Format = FormatFlatpak
+		return install, nil
+	}
+
+	// The AppImage runtime points APPIMAGE at the .AppImage file; the
+	// executable itself lives in a read-only mount
+	if appImage := os.Getenv("APPIMAGE"); runtime.GOOS == "linux" && appImage != "" {
+		install.Format = FormatAppImage
+		install.Path = appImage
+		return install, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	install.Path = exe
+
+	if runtime.GOOS == "darwin" {
+		// Navigate up from Contents/MacOS/executable to the .app bundle
+		appBundlePath := exe
+		for i := 0; i < 3; i++ {
+			appBundlePath = filepath.Dir(appBundlePath)
+		}
+		if !strings.HasSuffix(appBundlePath, ".app") {
+			return nil, fmt.Errorf("not running from an app bundle: %s", appBundlePath)
+		}
+		install.Format = FormatDMG
+		install.Path = appBundlePath
+	}
+	return install, nil
+}
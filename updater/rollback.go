@@ -0,0 +1,240 @@
+package updater
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"HyVanila/internal/env"
+)
+
+// healthTimeout is how many seconds a new launcher gets to finish starting
+// before the update helper restores the previous one. Startup confirms the
+// update only after the data migrations, which can take minutes on a first
+// start; a launcher that crashes is rolled back as soon as it exits.
+const healthTimeout = 600
+
+// PendingUpdate is written before a launcher update is applied. The new launcher
+// removes it once it has started; if that doesn't happen, the update helper
+// puts the previous launcher back.
+type PendingUpdate struct {
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	// Target is the installed binary, or the .app bundle on macOS
+	Target string `json:"target"`
+	// Backup is where the launcher being replaced is kept
+	Backup    string `json:"backup"`
+	StartedAt string `json:"startedAt"` // ISO 8601 format
+}
+
+// previousLauncher is the launcher kept after a confirmed update, for reverts
+type previousLauncher struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+// RollbackInfo tells the frontend what can be reverted and what was rolled back
+type RollbackInfo struct {
+	// PreviousVersion is the launcher a revert goes back to (empty if none is kept)
+	PreviousVersion string `json:"previousVersion"`
+	// RolledBackFrom is an update that failed to start and was undone
+	RolledBackFrom string `json:"rolledBackFrom,omitempty"`
+}
+
+// The launcher state lives in the app directory, outside the install, so it
+// survives the binary being swapped
+func getStateDir() string {
+	return filepath.Join(env.GetDefaultAppDir(), "launcher")
+}
+
+func getPendingPath() string {
+	return filepath.Join(getStateDir(), "pending.json")
+}
+
+func getRolledBackPath() string {
+	return filepath.Join(getStateDir(), "rolled-back.json")
+}
+
+func getPreviousPath() string {
+	return filepath.Join(getStateDir(), "previous.json")
+}
+
+func readState(path string, v any) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		fmt.Printf("Warning: Ignoring unreadable launcher state %s: %v\n", filepath.Base(path), err)
+		return false
+	}
+	return true
+}
+
+func writeState(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// prepareRollback records a launcher swap about to happen. Without watch the
+// swap isn't recorded as pending, so the update helper doesn't wait for the new
+// launcher to confirm its start; launchers that can't confirm would otherwise
+// always be rolled back.
+func prepareRollback(target, backup, fromVersion, toVersion string, watch bool) (*PendingUpdate, error) {
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create launcher backup folder: %w", err)
+	}
+	pending := &PendingUpdate{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Target:      target,
+		Backup:      backup,
+		StartedAt:   time.Now().Format(time.RFC3339),
+	}
+	if !watch {
+		os.Remove(getPendingPath())
+		return pending, nil
+	}
+	if err := writeState(getPendingPath(), pending); err != nil {
+		return nil, fmt.Errorf("failed to record pending update: %w", err)
+	}
+	return pending, nil
+}
+
+// startSwap starts the update helper and exits so it can replace this launcher
+func startSwap(script string, pending *PendingUpdate) error {
+	if err := runHelper(script, pending); err != nil {
+		os.Remove(getPendingPath())
+		return err
+	}
+	// The helper overwrites the backup slot, so the kept launcher is gone
+	os.Remove(getPreviousPath())
+	os.Exit(0)
+	return nil
+}
+
+// installTarget returns what a launcher swap replaces, refusing installs that
+// a package manager updates
+func installTarget() (string, error) {
+	install, err := DetectInstall()
+	if err != nil {
+		return "", err
+	}
+	if !install.SelfUpdating() {
+		return "", fmt.Errorf("%s", install.ManagedInstructions())
+	}
+	return install.Path, nil
+}
+
+// getBackupPath returns where the launcher at target is kept when replaced
+func getBackupPath(target string) string {
+	return filepath.Join(getStateDir(), "previous", filepath.Base(target))
+}
+
+// MarkHealthy confirms that the running launcher started successfully; call it
+// once startup has completed. After an update this stops the update helper from
+// rolling back and keeps the replaced launcher for manual reverts.
+func MarkHealthy(version string) {
+	var pending PendingUpdate
+	if !readState(getPendingPath(), &pending) {
+		return
+	}
+	if !sameVersion(pending.ToVersion, version) {
+		// The swap didn't happen and the old launcher was started again
+		fmt.Printf("Warning: Launcher update to %s was not installed\n", pending.ToVersion)
+		os.Rename(getPendingPath(), getRolledBackPath())
+		return
+	}
+	if err := os.Remove(getPendingPath()); err != nil {
+		fmt.Printf("Warning: Failed to confirm launcher update: %v\n", err)
+		return
+	}
+	if err := writeState(getPreviousPath(), previousLauncher{Version: pending.FromVersion, Path: pending.Backup}); err != nil {
+		fmt.Printf("Warning: Failed to record previous launcher: %v\n", err)
+	}
+	fmt.Printf("Launcher update to %s confirmed (previous version %s kept)\n", version, pending.FromVersion)
+}
+
+// sameVersion reports whether two version strings name the same release, e.g.
+// "v1.2.3" and "1.2.3". Versions that don't parse must match exactly.
+func sameVersion(a, b string) bool {
+	cmp, err := CompareVersions(a, b)
+	if err != nil {
+		return a == b
+	}
+	return cmp == 0
+}
+
+// TakeRolledBack returns the update that was undone because it failed to start,
+// and forgets it so it is only reported once
+func TakeRolledBack() *PendingUpdate {
+	var pending PendingUpdate
+	if !readState(getRolledBackPath(), &pending) {
+		return nil
+	}
+	os.Remove(getRolledBackPath())
+	return &pending
+}
+
+// getPrevious returns the launcher kept for reverts, or nil if there is none
+func getPrevious() *previousLauncher {
+	var prev previousLauncher
+	if !readState(getPreviousPath(), &prev) {
+		return nil
+	}
+	if _, err := os.Stat(prev.Path); err != nil {
+		return nil
+	}
+	return &prev
+}
+
+// PreviousVersion returns the version a revert goes back to (empty if none is kept)
+func PreviousVersion() string {
+	if prev := getPrevious(); prev != nil {
+		return prev.Version
+	}
+	return ""
+}
+
+// Apply installs a downloaded launcher update and restarts into it. The
+// replaced launcher is kept, and restored if the new one fails to start when
+// it supports rollback (UpdateCheck.Rollback).
+func Apply(tmp, fromVersion, toVersion string, rollback bool) error {
+	target, err := installTarget()
+	if err != nil {
+		return err
+	}
+	pending, err := prepareRollback(target, getBackupPath(target), fromVersion, toVersion, rollback)
+	if err != nil {
+		return err
+	}
+	return startSwap(installScript(tmp, pending), pending)
+}
+
+// Revert swaps the running launcher with the previously kept one and restarts
+// into it. The running launcher takes its place, so a revert can be undone.
+func Revert(current string) error {
+	prev := getPrevious()
+	if prev == nil {
+		return fmt.Errorf("no previous launcher version is kept")
+	}
+	target, err := installTarget()
+	if err != nil {
+		return err
+	}
+	// The kept launcher ran this updater before, so it confirms its start
+	pending, err := prepareRollback(target, prev.Path, current, prev.Version, true)
+	if err != nil {
+		return err
+	}
+	return startSwap(swapScript(pending), pending)
+}
@@ -0,0 +1,79 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"HyVanila/internal/env"
+)
+
+// useStateDir points the launcher state at a temporary app directory
+func useStateDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("LOCALAPPDATA", dir)
+	if err := os.MkdirAll(getStateDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnwatchedSwapIsNotPending(t *testing.T) {
+	useStateDir(t)
+	target := filepath.Join(env.GetDefaultAppDir(), "HyVanila")
+
+	if _, err := prepareRollback(target, getBackupPath(target), "1.2.0", "1.3.0", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(getPendingPath()); err != nil {
+		t.Fatalf("watched swap was not recorded: %v", err)
+	}
+
+	// A downgrade to a launcher that never confirms its start
+	if _, err := prepareRollback(target, getBackupPath(target), "1.3.0", "1.0.0", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(getPendingPath()); !os.IsNotExist(err) {
+		t.Errorf("unwatched swap left a pending marker: %v", err)
+	}
+}
+
+func TestMarkHealthy(t *testing.T) {
+	tests := []struct {
+		name, to, running string
+		confirmed         bool
+	}{
+		{name: "same version", to: "1.3.0", running: "1.3.0", confirmed: true},
+		{name: "v prefix in version.json", to: "v1.3.0", running: "1.3.0", confirmed: true},
+		{name: "build metadata", to: "1.3.0+linux", running: "1.3.0", confirmed: true},
+		{name: "nightly tag", to: "nightly-abc123", running: "nightly-abc123", confirmed: true},
+		{name: "old launcher started again", to: "1.3.0", running: "1.2.0"},
+		{name: "unparsable versions differ", to: "nightly-def456", running: "dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStateDir(t)
+			target := filepath.Join(env.GetDefaultAppDir(), "HyVanila")
+			if _, err := prepareRollback(target, getBackupPath(target), "1.2.0", tt.to, true); err != nil {
+				t.Fatal(err)
+			}
+			// The update helper moved the replaced launcher to the backup slot
+			if err := os.WriteFile(getBackupPath(target), nil, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			MarkHealthy(tt.running)
+			if _, err := os.Stat(getPendingPath()); !os.IsNotExist(err) {
+				t.Errorf("pending marker left behind: %v", err)
+			}
+			if got := PreviousVersion() == "1.2.0"; got != tt.confirmed {
+				t.Errorf("update confirmed = %v, want %v", got, tt.confirmed)
+			}
+			if rolledBack := TakeRolledBack(); (rolledBack != nil) == tt.confirmed {
+				t.Errorf("TakeRolledBack = %+v, want rolled back %v", rolledBack, !tt.confirmed)
+			}
+		})
+	}
+}
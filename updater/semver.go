@@ -0,0 +1,141 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// nightlyPrefix marks versions built for the nightly channel, e.g. "nightly-1.0.25-20250101"
+const nightlyPrefix = "nightly-"
+
+// Version is a parsed semantic version (https://semver.org)
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string // Dot-separated identifiers after "-", e.g. ["beta", "2"]
+}
+
+// ParseVersion parses "1.2.3", "v1.2.3", "1.2.3-beta.2" or "1.2.3+build".
+// A "nightly-" prefix is dropped; nightlies compare by the version that follows it.
+func ParseVersion(s string) (Version, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, nightlyPrefix)
+	s = strings.TrimPrefix(s, "v")
+
+	// Build metadata doesn't take part in comparisons
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v Version
+	core := s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		core = s[:i]
+		pre := s[i+1:]
+		if pre == "" {
+			return Version{}, fmt.Errorf("invalid version %q: empty prerelease", raw)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, id := range v.Prerelease {
+			if id == "" {
+				return Version{}, fmt.Errorf("invalid version %q: empty prerelease identifier", raw)
+			}
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", raw)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || part[0] == '+' {
+			return Version{}, fmt.Errorf("invalid version %q: %q is not a number", raw, part)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// String formats the version without a "v" prefix
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is older than, equal to or newer than other,
+// following semver precedence: a prerelease sorts before its release, and
+// prerelease identifiers compare numerically when both are numbers
+func (v Version) Compare(other Version) int {
+	if c := compareInt(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Prerelease), len(other.Prerelease))
+}
+
+// CompareVersions parses and compares two version strings
+func CompareVersions(a, b string) (int, error) {
+	va, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// compareIdentifier compares prerelease identifiers; numeric ones sort before
+// alphanumeric ones
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package updater
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{in: "1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v1.2.3", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: " 1.2.3\n", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "1.2.3+build.7", want: Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "1.2.3-beta.2", want: Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"beta", "2"}}},
+		{in: "1.2.3-rc.1+build", want: Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []string{"rc", "1"}}},
+		{in: "nightly-1.0.25-20250101", want: Version{Major: 1, Minor: 0, Patch: 25, Prerelease: []string{"20250101"}}},
+		{in: "nightly-v1.0.25", want: Version{Major: 1, Minor: 0, Patch: 25}},
+		{in: "", wantErr: true},
+		{in: "1.2", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+		{in: "1.x.3", wantErr: true},
+		{in: "1..3", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+		{in: "1.2.3-beta..1", wantErr: true},
+		{in: "vv1.2.3", wantErr: true},
+		{in: "nightly", wantErr: true},
+		{in: "nightly-20250101", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got.Major != tt.want.Major || got.Minor != tt.want.Minor || got.Patch != tt.want.Patch ||
+			!slices.Equal(got.Prerelease, tt.want.Prerelease) {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3+a", "1.2.3+b", 0},
+		{"1.2.4", "1.2.3", 1},
+		{"1.3.0", "1.2.9", 1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.10.0", "1.9.0", 1},
+		// A prerelease sorts before its release
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		// The semver.org precedence example
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		// Nightlies compare by their version and build date
+		{"nightly-1.0.25-20250102", "nightly-1.0.25-20250101", 1},
+		{"nightly-1.0.26-20250101", "1.0.25", 1},
+		{"nightly-1.0.25-20250101", "1.0.25", -1},
+	}
+	for _, tt := range tests {
+		got, err := CompareVersions(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("CompareVersions(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if reverse, _ := CompareVersions(tt.b, tt.a); reverse != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, reverse, -tt.want)
+		}
+	}
+}
+
+func TestCompareToCurrent(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            int
+		wantErr         bool
+	}{
+		{latest: "1.1.0", current: "1.0.0", want: 1},
+		{latest: "v1.0.0", current: "1.0.0", want: 0},
+		{latest: "1.0.0", current: "1.1.0-beta.1", want: -1},
+		{latest: "nightly-1.0.1-20250101", current: "nightly-1.0.0-20250301", want: 1},
+		// Nightly tags without a version are only told apart by the tag
+		{latest: "nightly-abc123", current: "nightly-abc123", want: 0},
+		{latest: "nightly-def456", current: "nightly-abc123", want: 1},
+		{latest: "nightly-def456", current: "1.0.0", wantErr: true},
+		{latest: "1.0.0", current: "dev", wantErr: true},
+		{latest: "garbage", current: "1.0.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := compareToCurrent(tt.latest, tt.current)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("compareToCurrent(%q, %q) error = %v, want error %v", tt.latest, tt.current, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("compareToCurrent(%q, %q) = %d, want %d", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"HyVanila/internal/util/download"
+)
+
+// ErrUnverified marks updates refused because their signature is missing or invalid
+var ErrUnverified = errors.New("update could not be verified")
+
+// PublicKey is the base64 ed25519 key release files are signed with - set at
+// build time via ldflags. Several keys may be given, separated by commas, while
+// the signing key is rotated.
+var PublicKey string
+
+// signatureSuffix is appended to a release file's URL to get its detached signature
+const signatureSuffix = ".sig"
+
+// maxSignatureSize bounds the signature download; a base64 ed25519 signature is 88 bytes
+const maxSignatureSize = 1024
+
+// publicKeys parses the embedded public keys
+func publicKeys() ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, encoded := range strings.Split(PublicKey, ",") {
+		encoded = strings.TrimSpace(encoded)
+		if encoded == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid embedded update signing key")
+		}
+		keys = append(keys, ed25519.PublicKey(raw))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("this build has no update signing key, so updates can't be verified")
+	}
+	return keys, nil
+}
+
+// verifySignature checks a base64 detached signature over data against the embedded keys
+func verifySignature(data, signature []byte) error {
+	keys, err := publicKeys()
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed signature")
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match any trusted key")
+}
+
+// fetchSignature downloads the detached signature published next to url
+func fetchSignature(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url+signatureSuffix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", download.UserAgent)
+	resp, err := download.GetSharedClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("update is not signed")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch signature: HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+}
+
+// verifyFile checks the file at path against the signature published next to url
+func verifyFile(ctx context.Context, path, url string) error {
+	signature, err := fetchSignature(ctx, url)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return verifySignature(data, signature)
+}
@@ -0,0 +1,59 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(public), private
+}
+
+func sign(private ed25519.PrivateKey, data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, data)) + "\n")
+}
+
+func TestVerifySignature(t *testing.T) {
+	defer func(key string) { PublicKey = key }(PublicKey)
+	current, currentPrivate := newKey(t)
+	previous, previousPrivate := newKey(t)
+	_, untrusted := newKey(t)
+	data := []byte(`{"version": "1.2.3"}`)
+
+	tests := []struct {
+		name      string
+		keys      string
+		data      []byte
+		signature []byte
+		wantErr   bool
+	}{
+		{name: "valid", keys: current, data: data, signature: sign(currentPrivate, data)},
+		{name: "rotated key", keys: current + ", " + previous, data: data, signature: sign(previousPrivate, data)},
+		{name: "untrusted key", keys: current, data: data, signature: sign(untrusted, data), wantErr: true},
+		{name: "tampered data", keys: current, data: []byte(`{"version": "9.9.9"}`), signature: sign(currentPrivate, data), wantErr: true},
+		{name: "not base64", keys: current, data: data, signature: []byte("not a signature!"), wantErr: true},
+		{name: "truncated", keys: current, data: data, signature: sign(currentPrivate, data)[:40], wantErr: true},
+		{name: "all zeros", keys: current, data: data, signature: []byte(strings.Repeat("A", 86) + "=="), wantErr: true},
+		{name: "empty", keys: current, data: data, signature: nil, wantErr: true},
+		{name: "no embedded key", keys: "", data: data, signature: sign(currentPrivate, data), wantErr: true},
+		{name: "invalid embedded key", keys: "c2hvcnQ=", data: data, signature: sign(currentPrivate, data), wantErr: true},
+		{name: "one invalid key among several", keys: current + ",c2hvcnQ=", data: data, signature: sign(currentPrivate, data), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PublicKey = tt.keys
+			err := verifySignature(tt.data, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifySignature = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}